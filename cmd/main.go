@@ -3,21 +3,61 @@ package main
 import (
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	envoyAuth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	"github.com/gomodule/redigo/redis"
 	"github.com/gorilla/mux"
+	accountRepo "github.com/lightlink/auth-service/internal/account/repository/redis"
+	"github.com/lightlink/auth-service/internal/federation/oidc"
+	federationFileRepo "github.com/lightlink/auth-service/internal/federation/repository/file"
+	federationRepo "github.com/lightlink/auth-service/internal/federation/repository/redis"
+	"github.com/lightlink/auth-service/internal/mailer"
+	mfaRepo "github.com/lightlink/auth-service/internal/mfa/repository/redis"
+	oauthFileRepo "github.com/lightlink/auth-service/internal/oauth/repository/file"
+	oauthRepo "github.com/lightlink/auth-service/internal/oauth/repository/redis"
+	passkeyRepo "github.com/lightlink/auth-service/internal/passkey/repository/redis"
+	roomRepo "github.com/lightlink/auth-service/internal/room/repository/redis"
+	"github.com/lightlink/auth-service/internal/session/authenticator"
+	"github.com/lightlink/auth-service/internal/session/authenticator/ldap"
 	sessionRepo "github.com/lightlink/auth-service/internal/session/repository/redis"
+	"github.com/lightlink/auth-service/internal/token/keyring"
+	"github.com/lightlink/auth-service/internal/token/signer"
+	turnRepo "github.com/lightlink/auth-service/internal/turn/repository/redis"
 	userRepo "github.com/lightlink/auth-service/internal/user/repository/grpc"
+	authProto "github.com/lightlink/auth-service/protogen/auth"
 	proto "github.com/lightlink/auth-service/protogen/user"
 
+	accountUsecase "github.com/lightlink/auth-service/internal/account/usecase"
+	federationUsecase "github.com/lightlink/auth-service/internal/federation/usecase"
+	forwardAuthUsecase "github.com/lightlink/auth-service/internal/forwardauth/usecase"
+	mfaUsecase "github.com/lightlink/auth-service/internal/mfa/usecase"
+	oauthUsecase "github.com/lightlink/auth-service/internal/oauth/usecase"
+	passkeyUsecase "github.com/lightlink/auth-service/internal/passkey/usecase"
+	roomUsecase "github.com/lightlink/auth-service/internal/room/usecase"
 	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	turnUsecase "github.com/lightlink/auth-service/internal/turn/usecase"
 
+	accountDelivery "github.com/lightlink/auth-service/internal/account/delivery/http"
+	federationDelivery "github.com/lightlink/auth-service/internal/federation/delivery/http"
+	forwardAuthGrpcDelivery "github.com/lightlink/auth-service/internal/forwardauth/delivery/grpc"
+	forwardAuthDelivery "github.com/lightlink/auth-service/internal/forwardauth/delivery/http"
+	mfaDelivery "github.com/lightlink/auth-service/internal/mfa/delivery/http"
+	oauthDelivery "github.com/lightlink/auth-service/internal/oauth/delivery/http"
+	passkeyDelivery "github.com/lightlink/auth-service/internal/passkey/delivery/http"
+	roomDelivery "github.com/lightlink/auth-service/internal/room/delivery/http"
+	sessionGrpcDelivery "github.com/lightlink/auth-service/internal/session/delivery/grpc"
 	sessionDelivery "github.com/lightlink/auth-service/internal/session/delivery/http"
+	tokenDelivery "github.com/lightlink/auth-service/internal/token/delivery/http"
+	turnDelivery "github.com/lightlink/auth-service/internal/turn/delivery/http"
 )
 
 func main() {
@@ -40,20 +80,363 @@ func main() {
 
 	sessionRepository := sessionRepo.NewSessionRedisRepository(redisConn)
 
+	revocationRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	revocationRepository := sessionRepo.NewRevocationRedisRepository(revocationRedisConn)
+
+	ticketRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	ticketRepository := sessionRepo.NewTicketRedisRepository(ticketRedisConn)
+
+	mfaRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	mfaRepository := mfaRepo.NewMFARedisRepository(mfaRedisConn)
+
+	credentialRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	credentialRepository := passkeyRepo.NewCredentialRedisRepository(credentialRedisConn)
+
+	challengeRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	challengeRepository := passkeyRepo.NewChallengeRedisRepository(challengeRedisConn)
+
+	authorizationCodeRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	authorizationCodeRepository := oauthRepo.NewAuthorizationCodeRedisRepository(authorizationCodeRedisConn)
+
+	deviceAuthorizationRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	deviceAuthorizationRepository := oauthRepo.NewDeviceAuthorizationRedisRepository(deviceAuthorizationRedisConn)
+
+	consentRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	consentRepository := oauthRepo.NewConsentRedisRepository(consentRedisConn)
+
+	clientRepository, err := oauthFileRepo.NewClientFileRepository(os.Getenv("OAUTH_CLIENTS_FILE"))
+	if err != nil {
+		panic(err)
+	}
+
+	roomRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	inviteRepository := roomRepo.NewInviteRedisRepository(roomRedisConn)
+
+	roleRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	roleRepository := roomRepo.NewRoleRedisRepository(roleRedisConn)
+
+	turnRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	turnRateLimitRepository := turnRepo.NewRateLimitRedisRepository(turnRedisConn)
+
+	accountRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	accountTokenRepository := accountRepo.NewAccountTokenRedisRepository(accountRedisConn)
+
+	accountRateLimitRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	accountRateLimitRepository := accountRepo.NewRateLimitRedisRepository(accountRateLimitRedisConn)
+
+	federationRedisConn, err := redis.DialURL(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	authRequestRepository := federationRepo.NewAuthRequestRedisRepository(federationRedisConn)
+
+	providerRepository, err := federationFileRepo.NewProviderFileRepository(os.Getenv("FEDERATION_PROVIDERS_FILE"))
+	if err != nil {
+		panic(err)
+	}
+
+	keyRetention := 24 * time.Hour
+	if retention := os.Getenv("TOKEN_KEY_RETENTION"); retention != "" {
+		keyRetention, err = time.ParseDuration(retention)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	keyRing, err := keyring.NewKeyRing(signer.LoadSignerFromEnv, keyRetention)
+	if err != nil {
+		panic(err)
+	}
+
+	previousSigners, err := signer.LoadPreviousSignersFromEnv()
+	if err != nil {
+		panic(err)
+	}
+	for _, previousSigner := range previousSigners {
+		keyRing.AddRetired(previousSigner)
+	}
+
+	go func() {
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		for range reload {
+			err := keyRing.Reload()
+			if err != nil {
+				log.Println("signing key reload err", err)
+			}
+		}
+	}()
+
+	mfaUsecase := mfaUsecase.NewMFAUsecase(mfaRepository)
+
+	passwordAuthenticator := authenticator.NewDomainAuthenticator(authenticator.NewLocalAuthenticator(userRepository))
+
+	ldapConfigs, err := ldap.LoadConfigsFromFile(os.Getenv("LDAP_CONFIG_FILE"))
+	if err != nil {
+		panic(err)
+	}
+	for _, ldapConfig := range ldapConfigs {
+		ldapAuthenticator, err := ldap.NewLDAPAuthenticator(ldapConfig, userRepository)
+		if err != nil {
+			panic(err)
+		}
+
+		for _, domain := range ldapConfig.Domains {
+			passwordAuthenticator.Register(domain, ldapAuthenticator)
+		}
+	}
+
+	var guestSessionTTL time.Duration
+	if ttl := os.Getenv("GUEST_SESSION_TTL"); ttl != "" {
+		guestSessionTTL, err = time.ParseDuration(ttl)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	sessionUsecase := sessionUsecase.NewSessionUsecase(
 		sessionRepository,
+		revocationRepository,
+		ticketRepository,
+		userRepository,
+		mfaUsecase,
+		keyRing,
+		passwordAuthenticator,
+		guestSessionTTL,
+	)
+
+	accountMailer, err := mailer.LoadMailerFromEnv()
+	if err != nil {
+		panic(err)
+	}
+
+	accountUsecase := accountUsecase.NewAccountUsecase(
 		userRepository,
+		accountTokenRepository,
+		accountRateLimitRepository,
+		sessionUsecase,
+		accountMailer,
+		keyRing,
+		os.Getenv("EMAIL_VERIFICATION_URL"),
+		os.Getenv("PASSWORD_RESET_URL"),
 	)
+	accountHandler := accountDelivery.NewAccountHandler(accountUsecase)
 
-	sessionHandler := sessionDelivery.NewSessionHandler(sessionUsecase)
+	roleUsecase := roomUsecase.NewRoleUsecase(roleRepository)
+	forwardAuthConfig := forwardAuthUsecase.LoadConfigFromEnv()
+
+	roleHandler := roomDelivery.NewRoleHandler(roleUsecase, forwardAuthConfig.RolesHeader)
+
+	sessionHandler := sessionDelivery.NewSessionHandler(sessionUsecase, roleUsecase, forwardAuthConfig)
+	mfaHandler := mfaDelivery.NewMFAHandler(mfaUsecase)
+	authGrpcServer := sessionGrpcDelivery.NewAuthGrpcServer(sessionUsecase)
+
+	forwardAuthUsecase := forwardAuthUsecase.NewForwardAuthUsecase(sessionUsecase, forwardAuthConfig)
+
+	forwardAuthHandler := forwardAuthDelivery.NewForwardAuthHandler(forwardAuthUsecase, "/api/forward-auth")
+	envoyAuthorizationServer := forwardAuthGrpcDelivery.NewEnvoyAuthorizationServer(forwardAuthUsecase)
+	tokenHandler := tokenDelivery.NewTokenHandler(keyRing)
+
+	oauthUsecase := oauthUsecase.NewOAuthUsecase(
+		clientRepository,
+		authorizationCodeRepository,
+		consentRepository,
+		deviceAuthorizationRepository,
+		userRepository,
+		sessionUsecase,
+		keyRing,
+		os.Getenv("OIDC_ISSUER"),
+		os.Getenv("OAUTH_DEVICE_VERIFICATION_URI"),
+	)
+	oauthHandler := oauthDelivery.NewOAuthHandler(oauthUsecase, os.Getenv("OAUTH_LOGIN_URL"), os.Getenv("OAUTH_CONSENT_URL"))
+
+	federationUsecase := federationUsecase.NewFederationUsecase(
+		providerRepository,
+		authRequestRepository,
+		userRepository,
+		sessionUsecase,
+		oidc.NewClient(&http.Client{Timeout: 10 * time.Second}),
+	)
+	federationHandler := federationDelivery.NewFederationHandler(federationUsecase, os.Getenv("FEDERATION_MFA_URL"))
 
 	router := mux.NewRouter()
 
-	router.HandleFunc("/api/signup", sessionHandler.Signup).Methods("POST")
+	router.HandleFunc("/api/signup", accountHandler.Signup).Methods("POST")
+	router.HandleFunc("/api/email/verify", accountHandler.VerifyEmail).Methods("POST")
+	router.HandleFunc("/api/email/verification/resend", accountHandler.ResendVerification).Methods("POST")
+	router.HandleFunc("/api/password/forgot", accountHandler.ForgotPassword).Methods("POST")
+	router.HandleFunc("/api/password/reset", accountHandler.ResetPassword).Methods("POST")
 	router.HandleFunc("/api/login", sessionHandler.Login).Methods("POST")
+	router.HandleFunc("/api/login/mfa", sessionHandler.LoginMFA).Methods("POST")
 	router.HandleFunc("/api/logout", sessionHandler.Logout).Methods("POST")
 	router.HandleFunc("/api/refresh", sessionHandler.Refresh).Methods("GET")
 	router.HandleFunc("/api/check", sessionHandler.Check).Methods("GET")
+	router.HandleFunc("/api/ws-tickets", sessionDelivery.RequireFirstParty(sessionHandler.IssueTicket)).Methods("POST")
+	router.HandleFunc("/api/ws-tickets/redeem", sessionHandler.RedeemTicket).Methods("POST")
+	router.HandleFunc("/api/mfa/totp/enroll", sessionDelivery.RequireFirstParty(mfaHandler.Enroll)).Methods("POST")
+	router.HandleFunc("/api/mfa/totp/activate", sessionDelivery.RequireFirstParty(mfaHandler.Activate)).Methods("POST")
+	router.HandleFunc("/api/federation/{provider}/login", federationHandler.Login).Methods("GET")
+	router.HandleFunc("/api/federation/{provider}/callback", federationHandler.Callback).Methods("GET")
+	router.PathPrefix("/api/forward-auth").HandlerFunc(forwardAuthHandler.Check)
+
+	if os.Getenv("WEBAUTHN_RP_ID") != "" {
+		webAuthn, err := passkeyUsecase.NewWebAuthnFromEnv()
+		if err != nil {
+			panic(err)
+		}
+
+		passkeyUsecase := passkeyUsecase.NewPasskeyUsecase(
+			webAuthn,
+			credentialRepository,
+			challengeRepository,
+			userRepository,
+			sessionUsecase,
+		)
+		passkeyHandler := passkeyDelivery.NewPasskeyHandler(passkeyUsecase)
+
+		router.HandleFunc("/api/passkeys/register/begin", sessionDelivery.RequireFirstParty(passkeyHandler.BeginRegistration)).Methods("POST")
+		router.HandleFunc("/api/passkeys/register/finish", sessionDelivery.RequireFirstParty(passkeyHandler.FinishRegistration)).Methods("POST")
+		router.HandleFunc("/api/passkeys/login/begin", passkeyHandler.BeginLogin).Methods("POST")
+		router.HandleFunc("/api/passkeys/login/finish", passkeyHandler.FinishLogin).Methods("POST")
+	}
+
+	router.HandleFunc("/oauth/authorize", oauthHandler.Authorize).Methods("GET")
+	router.HandleFunc("/oauth/token", oauthHandler.Token).Methods("POST")
+	router.HandleFunc("/oauth/introspect", oauthHandler.Introspect).Methods("POST")
+	router.HandleFunc("/oauth/revoke", oauthHandler.Revoke).Methods("POST")
+	router.HandleFunc("/oauth/consent", oauthHandler.Consent).Methods("GET")
+	router.HandleFunc("/oauth/consent", oauthHandler.DecideConsent).Methods("POST")
+	router.HandleFunc("/oauth/device_authorization", oauthHandler.DeviceAuthorization).Methods("POST")
+	router.HandleFunc("/oauth/device", oauthHandler.DeviceVerification).Methods("GET")
+	router.HandleFunc("/oauth/device", oauthHandler.ApproveDevice).Methods("POST")
+	router.HandleFunc("/userinfo", oauthHandler.UserInfo).Methods("GET", "POST")
+	router.HandleFunc("/.well-known/openid-configuration", oauthHandler.Discovery).Methods("GET")
+
+	router.HandleFunc("/api/rooms", sessionDelivery.RequireFirstParty(roleHandler.CreateRoom)).Methods("POST")
+	router.HandleFunc("/api/rooms/{roomID}/roles", roleHandler.ListRoles).Methods("GET")
+	router.HandleFunc("/api/rooms/{roomID}/roles/{identity}", sessionDelivery.RequireFirstParty(roleHandler.GrantRole)).Methods("PUT")
+	router.HandleFunc("/api/rooms/{roomID}/roles/{identity}", sessionDelivery.RequireFirstParty(roleHandler.RevokeRole)).Methods("DELETE")
+
+	if os.Getenv("MEDIA_API_SECRET") != "" {
+		mediaSigner, err := roomUsecase.LoadMediaSignerFromEnv()
+		if err != nil {
+			panic(err)
+		}
+
+		var joinTokenTTL time.Duration
+		if ttl := os.Getenv("MEDIA_TOKEN_TTL"); ttl != "" {
+			joinTokenTTL, err = time.ParseDuration(ttl)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		roomUsecase := roomUsecase.NewRoomUsecase(
+			mediaSigner,
+			joinTokenTTL,
+			roleUsecase,
+			inviteRepository,
+			keyRing,
+			keyRetention,
+			sessionUsecase,
+			os.Getenv("ROOM_INVITE_URL"),
+		)
+		roomHandler := roomDelivery.NewRoomHandler(roomUsecase, forwardAuthConfig.RolesHeader)
+
+		router.HandleFunc("/api/rooms/join-token", sessionDelivery.RequireFirstParty(roomHandler.JoinToken)).Methods("POST")
+		router.HandleFunc("/api/rooms/invites", sessionDelivery.RequireFirstParty(roomHandler.CreateInvite)).Methods("POST")
+		router.HandleFunc("/api/rooms/invites/redeem", roomHandler.RedeemInvite).Methods("POST")
+		router.HandleFunc("/api/rooms/invites/{inviteID}", sessionDelivery.RequireFirstParty(roomHandler.RevokeInvite)).Methods("DELETE")
+	}
+
+	if os.Getenv("TURN_SHARED_SECRET") != "" {
+		turnConfig, err := turnUsecase.LoadConfigFromEnv()
+		if err != nil {
+			panic(err)
+		}
+
+		turnUsecase := turnUsecase.NewTURNUsecase(turnRateLimitRepository, turnConfig)
+		turnHandler := turnDelivery.NewTURNHandler(turnUsecase)
+
+		router.HandleFunc("/api/turn/credentials", sessionDelivery.RequireFirstParty(turnHandler.Credentials)).Methods("GET")
+	}
+
+	router.HandleFunc("/.well-known/jwks.json", tokenHandler.JWKS).Methods("GET")
+	router.HandleFunc("/admin/keys/rotate", tokenHandler.RotateKeys).Methods("POST")
+
+	grpcPort := os.Getenv("AUTH_GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "8083"
+	}
+
+	grpcListener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		panic(err)
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(sessionGrpcDelivery.NewServiceAuthInterceptor(os.Getenv("AUTH_GRPC_SERVICE_TOKEN"))),
+	)
+	authProto.RegisterAuthServiceServer(grpcServer, authGrpcServer)
+	envoyAuth.RegisterAuthorizationServer(grpcServer, envoyAuthorizationServer)
+
+	go func() {
+		log.Printf("starting grpc server at 127.0.0.1:%s", grpcPort)
+		log.Fatal(grpcServer.Serve(grpcListener))
+	}()
 
 	log.Println("starting server at http://127.0.0.1:8082")
 	log.Fatal(http.ListenAndServe(":8082", router))
@@ -0,0 +1,232 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/lightlink/auth-service/internal/account/domain/dto"
+	"github.com/lightlink/auth-service/internal/account/domain/entity"
+	"github.com/lightlink/auth-service/internal/account/usecase"
+	sessionDelivery "github.com/lightlink/auth-service/internal/session/delivery/http"
+	sessionDTO "github.com/lightlink/auth-service/internal/session/domain/dto"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+)
+
+type AccountHandler struct {
+	accountUC usecase.AccountUsecaseI
+}
+
+func NewAccountHandler(accountUsecase usecase.AccountUsecaseI) *AccountHandler {
+	return &AccountHandler{
+		accountUC: accountUsecase,
+	}
+}
+
+func (h *AccountHandler) Signup(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	signupRequest := &sessionDTO.SignupRequest{}
+	err = json.Unmarshal(body, signupRequest)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	createdSessionEntity, err := h.accountUC.Signup(signupRequest)
+	if errors.Is(err, sessionEntity.ErrInvalidEmail) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("session create err", err)
+		return
+	}
+
+	if errors.Is(err, sessionEntity.ErrExternalAccount) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Println("session create err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("session create err", err)
+		return
+	}
+
+	sessionDelivery.SetSessionCookies(w, createdSessionEntity)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *AccountHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	verifyRequest := &dto.VerifyEmailRequest{}
+	err = json.Unmarshal(body, verifyRequest)
+	if err != nil || verifyRequest.Token == "" {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	err = h.accountUC.VerifyEmail(verifyRequest.Token)
+	if errors.Is(err, entity.ErrInvalidToken) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("verify email err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("verify email err", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *AccountHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userIDString := r.Header.Get("X-User-ID")
+	userID64, err := strconv.ParseUint(userIDString, 10, 32)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	err = h.accountUC.SendVerification(uint(userID64))
+	if errors.Is(err, entity.ErrNoEmail) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("resend verification err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrAlreadyVerified) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Println("resend verification err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("resend verification err", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ForgotPassword answers the same way whether or not the account exists. The
+// lookup and delivery run in the background so response time doesn't give
+// it away either.
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	forgotRequest := &dto.ForgotPasswordRequest{}
+	err = json.Unmarshal(body, forgotRequest)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	if forgotRequest.Username == "" {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// Throttled before anything runs in the background, so repeated requests
+	// can't pile up goroutines either.
+	err = h.accountUC.ThrottlePasswordReset(forgotRequest.Username)
+	rateLimited := &entity.RateLimitedError{}
+	if errors.As(err, &rateLimited) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimited.RetryAfter.Seconds()))))
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Println("forgot password err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("forgot password err", err)
+		return
+	}
+
+	go func() {
+		err := h.accountUC.ForgotPassword(forgotRequest.Username)
+		if err != nil {
+			fmt.Println("forgot password err", err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	resetRequest := &dto.ResetPasswordRequest{}
+	err = json.Unmarshal(body, resetRequest)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	err = h.accountUC.ResetPassword(resetRequest.Token, resetRequest.Password)
+	if errors.Is(err, entity.ErrInvalidToken) || errors.Is(err, entity.ErrInvalidPassword) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("reset password err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("reset password err", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNoToken         = errors.New("couldn't find account token")
+	ErrInvalidToken    = errors.New("account token is invalid or has already been used")
+	ErrNoEmail         = errors.New("account has no email address")
+	ErrAlreadyVerified = errors.New("email address is already verified")
+	ErrInvalidPassword = errors.New("password is invalid")
+)
+
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return "too many password reset requests"
+}
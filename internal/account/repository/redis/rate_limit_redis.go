@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+type RateLimitRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewRateLimitRedisRepository(conn redis.Conn) *RateLimitRedisRepository {
+	return &RateLimitRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+// The window starts with the first request, so the expiry is only set when
+// the counter is created.
+var hitScript = redis.NewScript(1, `
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return {count, redis.call('PTTL', KEYS[1])}
+`)
+
+func rateLimitKey(kind string, key string) string {
+	return "account_rate:" + kind + ":" + key
+}
+
+// Hit counts a request against the current window and reports the count
+// together with the time left until the window resets.
+func (repo *RateLimitRedisRepository) Hit(kind string, key string, window time.Duration) (int, time.Duration, error) {
+	repo.mu.Lock()
+	values, err := redis.Ints(hitScript.Do(repo.redisConn, rateLimitKey(kind, key), window.Milliseconds()))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(values) != 2 {
+		return 0, 0, redis.ErrNil
+	}
+
+	return values[0], time.Duration(values[1]) * time.Millisecond, nil
+}
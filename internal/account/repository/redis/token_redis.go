@@ -0,0 +1,92 @@
+package redis
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/account/domain/entity"
+)
+
+type AccountTokenRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewAccountTokenRedisRepository(conn redis.Conn) *AccountTokenRedisRepository {
+	return &AccountTokenRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+func accountTokenKey(kind string, tokenID string) string {
+	return "account_tokens:" + kind + ":" + tokenID
+}
+
+// userAccountTokensKey indexes the outstanding tokens of a kind per user. It
+// lives as long as the newest of them, so ids of expired tokens may linger in
+// it until then.
+func userAccountTokensKey(kind string, userID uint) string {
+	return "account_tokens_by_user:" + kind + ":" + strconv.Itoa(int(userID))
+}
+
+func (repo *AccountTokenRedisRepository) Set(kind string, tokenID string, userID uint, ttl time.Duration) error {
+	indexKey := userAccountTokensKey(kind, userID)
+
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("SET", accountTokenKey(kind, tokenID), userID, "EX", int(ttl.Seconds()))
+	repo.redisConn.Send("SADD", indexKey, tokenID)
+	repo.redisConn.Send("EXPIRE", indexKey, int(ttl.Seconds()))
+	_, err := repo.redisConn.Do("EXEC")
+	repo.mu.Unlock()
+
+	return err
+}
+
+func (repo *AccountTokenRedisRepository) DeleteAllForUser(kind string, userID uint) error {
+	indexKey := userAccountTokensKey(kind, userID)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	tokenIDs, err := redis.Strings(repo.redisConn.Do("SMEMBERS", indexKey))
+	if err != nil {
+		return err
+	}
+
+	keys := []interface{}{indexKey}
+	for _, tokenID := range tokenIDs {
+		keys = append(keys, accountTokenKey(kind, tokenID))
+	}
+
+	_, err = repo.redisConn.Do("DEL", keys...)
+
+	return err
+}
+
+func (repo *AccountTokenRedisRepository) Pop(kind string, tokenID string) (uint, error) {
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("GET", accountTokenKey(kind, tokenID))
+	repo.redisConn.Send("DEL", accountTokenKey(kind, tokenID))
+	result, err := redis.Values(repo.redisConn.Do("EXEC"))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return 0, err
+	}
+
+	userID, err := redis.Uint64(result[0], nil)
+	if err == redis.ErrNil {
+		return 0, entity.ErrNoToken
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(userID), nil
+}
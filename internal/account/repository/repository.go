@@ -0,0 +1,18 @@
+package repository
+
+import "time"
+
+// AccountTokenRepositoryI keeps the single-use tokens of account flows. The
+// token id is whatever the flow decides to key on, never necessarily the
+// token itself.
+type AccountTokenRepositoryI interface {
+	Set(kind string, tokenID string, userID uint, ttl time.Duration) error
+	Pop(kind string, tokenID string) (uint, error)
+	DeleteAllForUser(kind string, userID uint) error
+}
+
+// RateLimitRepositoryI counts requests of a kind per key, such as reset links
+// asked for one username.
+type RateLimitRepositoryI interface {
+	Hit(kind string, key string, window time.Duration) (int, time.Duration, error)
+}
@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/account/domain/entity"
+	accountRepo "github.com/lightlink/auth-service/internal/account/repository"
+	"github.com/lightlink/auth-service/internal/mailer"
+	sessionDTO "github.com/lightlink/auth-service/internal/session/domain/dto"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	"github.com/lightlink/auth-service/internal/token/signer"
+	userRepo "github.com/lightlink/auth-service/internal/user/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	verificationTokenTTL  = 24 * time.Hour
+	passwordResetTokenTTL = time.Hour
+
+	// Reset links one username can be mailed per window, so nobody can flood
+	// a mailbox by asking over and over.
+	passwordResetLimit  = 3
+	passwordResetWindow = time.Hour
+
+	// bcrypt ignores everything past 72 bytes.
+	maxPasswordLength = 72
+
+	tokenTypeEmailVerification = "email_verification"
+	tokenKindEmailVerification = "email_verification"
+	tokenKindPasswordReset     = "password_reset"
+)
+
+type AccountUsecaseI interface {
+	Signup(signupRequest *sessionDTO.SignupRequest) (*sessionEntity.Session, error)
+	SendVerification(userID uint) error
+	VerifyEmail(token string) error
+	ThrottlePasswordReset(username string) error
+	ForgotPassword(username string) error
+	ResetPassword(token string, password string) error
+}
+
+type AccountUsecase struct {
+	userRepo         userRepo.UserRepositoryI
+	tokenRepo        accountRepo.AccountTokenRepositoryI
+	rateLimitRepo    accountRepo.RateLimitRepositoryI
+	sessionUC        sessionUsecase.SessionUsecaseI
+	mailer           mailer.Mailer
+	tokenSigner      signer.Signer
+	verificationURL  string
+	passwordResetURL string
+}
+
+func NewAccountUsecase(userRepository userRepo.UserRepositoryI, tokenRepository accountRepo.AccountTokenRepositoryI, rateLimitRepository accountRepo.RateLimitRepositoryI, sessionUsecase sessionUsecase.SessionUsecaseI, accountMailer mailer.Mailer, tokenSigner signer.Signer, verificationURL string, passwordResetURL string) *AccountUsecase {
+	return &AccountUsecase{
+		userRepo:         userRepository,
+		tokenRepo:        tokenRepository,
+		rateLimitRepo:    rateLimitRepository,
+		sessionUC:        sessionUsecase,
+		mailer:           accountMailer,
+		tokenSigner:      tokenSigner,
+		verificationURL:  verificationURL,
+		passwordResetURL: passwordResetURL,
+	}
+}
+
+// Signup creates the account with a restricted session and mails the
+// verification link. A failed delivery doesn't undo the signup, the user can
+// ask for another link.
+func (uc *AccountUsecase) Signup(signupRequest *sessionDTO.SignupRequest) (*sessionEntity.Session, error) {
+	session, err := uc.sessionUC.Signup(signupRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.sendVerification(session.UserID, session.Username, signupRequest.Email)
+	if err != nil {
+		fmt.Println("verification email err", session.UserID, err)
+	}
+
+	return session, nil
+}
+
+func (uc *AccountUsecase) SendVerification(userID uint) error {
+	user, err := uc.userRepo.GetById(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Email == "" {
+		return entity.ErrNoEmail
+	}
+
+	if user.EmailVerified {
+		return entity.ErrAlreadyVerified
+	}
+
+	return uc.sendVerification(user.Id, user.Username, user.Email)
+}
+
+// VerifyEmail confirms the address the token was issued for. The token names
+// the address, so a link sent before the email changed can't confirm the new
+// one.
+func (uc *AccountUsecase) VerifyEmail(tokenString string) error {
+	token, err := jwt.Parse(tokenString, uc.tokenSigner.Keyfunc)
+	if err != nil || !token.Valid {
+		return entity.ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != tokenTypeEmailVerification {
+		return entity.ErrInvalidToken
+	}
+
+	subject, _ := claims["sub"].(string)
+	userID64, err := strconv.ParseUint(subject, 10, 32)
+	if err != nil || userID64 == 0 {
+		return entity.ErrInvalidToken
+	}
+
+	email, _ := claims["email"].(string)
+	jti, _ := claims["jti"].(string)
+	if email == "" || jti == "" {
+		return entity.ErrInvalidToken
+	}
+
+	tokenUserID, err := uc.tokenRepo.Pop(tokenKindEmailVerification, jti)
+	if err == entity.ErrNoToken {
+		return entity.ErrInvalidToken
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if tokenUserID != uint(userID64) {
+		return entity.ErrInvalidToken
+	}
+
+	_, err = uc.userRepo.MarkEmailVerified(uint(userID64), email)
+
+	return err
+}
+
+// ThrottlePasswordReset counts a reset request against the username. It runs
+// before the account is looked up, so an unknown username is limited just
+// the same and the answer doesn't tell whether it exists.
+func (uc *AccountUsecase) ThrottlePasswordReset(username string) error {
+	count, retryAfter, err := uc.rateLimitRepo.Hit(
+		tokenKindPasswordReset,
+		strings.ToLower(strings.TrimSpace(username)),
+		passwordResetWindow,
+	)
+	if err != nil {
+		return err
+	}
+
+	if count > passwordResetLimit {
+		return &entity.RateLimitedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// ForgotPassword mails a one-time reset link to the address on the account.
+// Only a hash of the token is stored, so a leaked Redis dump can't be used to
+// take over accounts.
+func (uc *AccountUsecase) ForgotPassword(username string) error {
+	user, err := uc.userRepo.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	if user.Email == "" {
+		return entity.ErrNoEmail
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	err = uc.tokenRepo.Set(tokenKindPasswordReset, hashToken(token), user.Id, passwordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	body := "Hi " + user.Username + ",\n\n" +
+		"Someone asked to reset the password for your account. To choose a new one, open the link below:\n\n" +
+		uc.link(uc.passwordResetURL, token) + "\n\n" +
+		"The link expires in 1 hour and can only be used once. If you didn't ask for this, you can ignore this email.\n"
+
+	return uc.mailer.Send(&mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    body,
+	})
+}
+
+// ResetPassword replaces the password and signs the user out everywhere, so
+// whoever knew the old password loses their sessions as well. Any other reset
+// link still sitting in the mailbox stops working too.
+func (uc *AccountUsecase) ResetPassword(token string, password string) error {
+	if password == "" || len(password) > maxPasswordLength {
+		return entity.ErrInvalidPassword
+	}
+
+	if token == "" {
+		return entity.ErrInvalidToken
+	}
+
+	userID, err := uc.tokenRepo.Pop(tokenKindPasswordReset, hashToken(token))
+	if err == entity.ErrNoToken {
+		return entity.ErrInvalidToken
+	}
+
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	err = uc.userRepo.SetPasswordHash(userID, string(passwordHash))
+	if err != nil {
+		return err
+	}
+
+	err = uc.tokenRepo.DeleteAllForUser(tokenKindPasswordReset, userID)
+	if err != nil {
+		return err
+	}
+
+	return uc.sessionUC.RevokeAllSessions(userID)
+}
+
+func (uc *AccountUsecase) sendVerification(userID uint, username string, email string) error {
+	jti, err := generateID()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	tokenString, err := uc.tokenSigner.Sign(jwt.MapClaims{
+		"typ":   tokenTypeEmailVerification,
+		"sub":   strconv.Itoa(int(userID)),
+		"email": email,
+		"jti":   jti,
+		"iat":   now.Unix(),
+		"exp":   now.Add(verificationTokenTTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = uc.tokenRepo.Set(tokenKindEmailVerification, jti, userID, verificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	body := "Hi " + username + ",\n\n" +
+		"Please confirm your email address by opening the link below:\n\n" +
+		uc.link(uc.verificationURL, tokenString) + "\n\n" +
+		"The link expires in 24 hours. If you didn't sign up, you can ignore this email.\n"
+
+	return uc.mailer.Send(&mailer.Message{
+		To:      email,
+		Subject: "Confirm your email address",
+		Body:    body,
+	})
+}
+
+// link appends the token to the page that completes the flow. Without a page
+// configured the bare token is mailed so it can be posted by hand.
+func (uc *AccountUsecase) link(pageURL string, token string) string {
+	if pageURL == "" {
+		return token
+	}
+
+	separator := "?"
+	if strings.Contains(pageURL, "?") {
+		separator = "&"
+	}
+
+	return pageURL + separator + "token=" + url.QueryEscape(token)
+}
+
+func generateID() (string, error) {
+	buf := make([]byte, 16)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/lightlink/auth-service/internal/account/domain/entity"
+	accountRepo "github.com/lightlink/auth-service/internal/account/repository/redis"
+	"github.com/lightlink/auth-service/internal/mailer"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	"github.com/lightlink/auth-service/internal/testutil"
+	userDTO "github.com/lightlink/auth-service/internal/user/domain/dto"
+)
+
+const testResetURL = "https://app.example.com/reset"
+
+var resetLink = regexp.MustCompile(regexp.QuoteMeta(testResetURL) + `\?\S+`)
+
+type fakeSessionUsecase struct {
+	sessionUsecase.SessionUsecaseI
+	revoked []uint
+}
+
+func (f *fakeSessionUsecase) RevokeAllSessions(userID uint) error {
+	f.revoked = append(f.revoked, userID)
+
+	return nil
+}
+
+type fakeMailer struct {
+	sent []*mailer.Message
+}
+
+func (f *fakeMailer) Send(message *mailer.Message) error {
+	f.sent = append(f.sent, message)
+
+	return nil
+}
+
+type testEnv struct {
+	uc       *AccountUsecase
+	users    *testutil.UserRepository
+	sessions *fakeSessionUsecase
+	mailer   *fakeMailer
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	base := testutil.NewEnv(t)
+	users := testutil.NewUserRepository(
+		&userDTO.UserTransfer{Id: 1, Username: "alice", Email: "alice@example.com", EmailVerified: true},
+		&userDTO.UserTransfer{Id: 2, Username: "bob", Email: "bob@example.com", EmailVerified: true},
+	)
+	sessions := &fakeSessionUsecase{}
+	accountMailer := &fakeMailer{}
+
+	uc := NewAccountUsecase(
+		users,
+		accountRepo.NewAccountTokenRedisRepository(base.Dial()),
+		accountRepo.NewRateLimitRedisRepository(base.Dial()),
+		sessions,
+		accountMailer,
+		base.Signer,
+		"https://app.example.com/verify",
+		testResetURL,
+	)
+
+	return &testEnv{
+		uc:       uc,
+		users:    users,
+		sessions: sessions,
+		mailer:   accountMailer,
+	}
+}
+
+// forgotPassword asks for a reset link and returns the token mailed in it.
+func (env *testEnv) forgotPassword(t *testing.T, username string) string {
+	t.Helper()
+
+	err := env.uc.ForgotPassword(username)
+	if err != nil {
+		t.Fatalf("ForgotPassword(%s): %v", username, err)
+	}
+
+	message := env.mailer.sent[len(env.mailer.sent)-1]
+	link, err := url.Parse(resetLink.FindString(message.Body))
+	if err != nil || link.Query().Get("token") == "" {
+		t.Fatalf("no reset link in %q", message.Body)
+	}
+
+	return link.Query().Get("token")
+}
+
+func TestResetPasswordIsSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+	token := env.forgotPassword(t, "alice")
+
+	err := env.uc.ResetPassword(token, "new password")
+	if err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+
+	if env.users.PasswordHashes[1] == "" || len(env.sessions.revoked) != 1 || env.sessions.revoked[0] != 1 {
+		t.Fatalf("reset didn't set the password and revoke sessions: hashes %v, revoked %v", env.users.PasswordHashes, env.sessions.revoked)
+	}
+
+	err = env.uc.ResetPassword(token, "another password")
+	if !errors.Is(err, entity.ErrInvalidToken) {
+		t.Fatalf("second ResetPassword err = %v, want %v", err, entity.ErrInvalidToken)
+	}
+}
+
+func TestResetPasswordInvalidatesOtherLinks(t *testing.T) {
+	env := newTestEnv(t)
+	first := env.forgotPassword(t, "alice")
+	second := env.forgotPassword(t, "alice")
+	other := env.forgotPassword(t, "bob")
+
+	err := env.uc.ResetPassword(second, "new password")
+	if err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+
+	err = env.uc.ResetPassword(first, "another password")
+	if !errors.Is(err, entity.ErrInvalidToken) {
+		t.Fatalf("older link err = %v, want %v", err, entity.ErrInvalidToken)
+	}
+
+	err = env.uc.ResetPassword(other, "bob's password")
+	if err != nil {
+		t.Fatalf("another user's link: %v", err)
+	}
+}
+
+func TestResetPasswordRejects(t *testing.T) {
+	env := newTestEnv(t)
+	token := env.forgotPassword(t, "alice")
+
+	tests := []struct {
+		name     string
+		token    string
+		password string
+		wantErr  error
+	}{
+		{"unknown token", "not a token", "new password", entity.ErrInvalidToken},
+		{"empty token", "", "new password", entity.ErrInvalidToken},
+		{"empty password", token, "", entity.ErrInvalidPassword},
+		{"password too long", token, string(make([]byte, maxPasswordLength+1)), entity.ErrInvalidPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := env.uc.ResetPassword(tt.token, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ResetPassword err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	err := env.uc.ResetPassword(token, "new password")
+	if err != nil {
+		t.Fatalf("rejected attempts used up the token: %v", err)
+	}
+}
+
+func TestThrottlePasswordReset(t *testing.T) {
+	tests := []struct {
+		name      string
+		username  string
+		overLimit string
+	}{
+		{"known user", "alice", "alice"},
+		{"unknown user", "nobody", "nobody"},
+		{"case and padding", "alice", " Alice "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			for i := 0; i < passwordResetLimit; i++ {
+				err := env.uc.ThrottlePasswordReset(tt.username)
+				if err != nil {
+					t.Fatalf("request %d: %v", i+1, err)
+				}
+			}
+
+			err := env.uc.ThrottlePasswordReset(tt.overLimit)
+			rateLimited := &entity.RateLimitedError{}
+			if !errors.As(err, &rateLimited) || rateLimited.RetryAfter <= 0 {
+				t.Fatalf("request over the limit err = %v, want a rate limit with a retry time", err)
+			}
+
+			err = env.uc.ThrottlePasswordReset("bob")
+			if err != nil {
+				t.Errorf("another username got limited: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lightlink/auth-service/internal/federation/domain/entity"
+	"github.com/lightlink/auth-service/internal/federation/usecase"
+	sessionDelivery "github.com/lightlink/auth-service/internal/session/delivery/http"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+)
+
+const stateCookie = "federation_state"
+
+type FederationHandler struct {
+	federationUC usecase.FederationUsecaseI
+	mfaURL       string
+}
+
+func NewFederationHandler(federationUsecase usecase.FederationUsecaseI, mfaURL string) *FederationHandler {
+	return &FederationHandler{
+		federationUC: federationUsecase,
+		mfaURL:       mfaURL,
+	}
+}
+
+func (h *FederationHandler) Login(w http.ResponseWriter, r *http.Request) {
+	accessToken := ""
+	if accessCookie, err := r.Cookie("access_token"); err == nil {
+		accessToken = accessCookie.Value
+	}
+
+	redirectURL, state, err := h.federationUC.BeginLogin(mux.Vars(r)["provider"], accessToken, r.URL.Query().Get("return_to"))
+	if errors.Is(err, entity.ErrUnknownProvider) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Println("federation login err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Println("federation login err", err)
+		return
+	}
+
+	// The state also lives in a cookie so a callback can only complete the
+	// login in the browser that started it.
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    state,
+		Path:     "/api/federation",
+		Expires:  time.Now().Add(10 * time.Minute),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   false,
+	})
+
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+func (h *FederationHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("federation provider err", providerErr)
+		return
+	}
+
+	browserState := ""
+	if cookie, err := r.Cookie(stateCookie); err == nil {
+		browserState = cookie.Value
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:    stateCookie,
+		Value:   "",
+		Path:    "/api/federation",
+		Expires: time.Unix(0, 0),
+		Secure:  false,
+	})
+
+	session, returnTo, err := h.federationUC.FinishLogin(mux.Vars(r)["provider"], query.Get("state"), browserState, query.Get("code"))
+	mfaRequired := &sessionEntity.MFARequiredError{}
+	if errors.As(err, &mfaRequired) {
+		if h.mfaURL == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Println("federation callback: second factor required but no mfa page is configured")
+			return
+		}
+
+		sessionDelivery.SetMFATokenCookie(w, mfaRequired.MFAToken)
+		http.Redirect(w, r, h.mfaURL+"?return_to="+url.QueryEscape(returnTo), http.StatusFound)
+		return
+	}
+
+	if errors.Is(err, entity.ErrStateMismatch) ||
+		errors.Is(err, entity.ErrNoAuthRequest) ||
+		errors.Is(err, entity.ErrInvalidIDToken) ||
+		errors.Is(err, entity.ErrTokenExchangeFailed) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("federation callback err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrIdentityNotLinked) || errors.Is(err, entity.ErrIdentityLinkedElsewhere) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Println("federation callback err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrUnknownProvider) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Println("federation callback err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("federation callback err", err)
+		return
+	}
+
+	sessionDelivery.SetSessionCookies(w, session)
+
+	http.Redirect(w, r, returnTo, http.StatusFound)
+}
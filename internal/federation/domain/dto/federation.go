@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"github.com/lightlink/auth-service/internal/federation/domain/entity"
+	"github.com/lightlink/auth-service/internal/federation/domain/model"
+)
+
+func ProviderModelToEntity(providerModel *model.Provider) *entity.Provider {
+	return &entity.Provider{
+		Name:         providerModel.Name,
+		Issuer:       providerModel.Issuer,
+		ClientID:     providerModel.ClientID,
+		ClientSecret: providerModel.ClientSecret,
+		RedirectURL:  providerModel.RedirectURL,
+		Scopes:       providerModel.Scopes,
+	}
+}
+
+func AuthRequestEntityToModel(authRequestEntity *entity.AuthRequest) *model.AuthRequest {
+	return &model.AuthRequest{
+		Provider:     authRequestEntity.Provider,
+		Nonce:        authRequestEntity.Nonce,
+		CodeVerifier: authRequestEntity.CodeVerifier,
+		LinkUserID:   authRequestEntity.LinkUserID,
+		ReturnTo:     authRequestEntity.ReturnTo,
+		ExpiresAt:    authRequestEntity.ExpiresAt,
+	}
+}
+
+func AuthRequestModelToEntity(state string, authRequestModel *model.AuthRequest) *entity.AuthRequest {
+	return &entity.AuthRequest{
+		State:        state,
+		Provider:     authRequestModel.Provider,
+		Nonce:        authRequestModel.Nonce,
+		CodeVerifier: authRequestModel.CodeVerifier,
+		LinkUserID:   authRequestModel.LinkUserID,
+		ReturnTo:     authRequestModel.ReturnTo,
+		ExpiresAt:    authRequestModel.ExpiresAt,
+	}
+}
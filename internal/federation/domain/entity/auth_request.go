@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+type AuthRequest struct {
+	State        string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	LinkUserID   uint
+	ReturnTo     string
+	ExpiresAt    time.Time
+}
+
+type ExternalIdentity struct {
+	Provider          string
+	Subject           string
+	Email             string
+	PreferredUsername string
+	AMR               []string
+}
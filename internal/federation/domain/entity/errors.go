@@ -0,0 +1,14 @@
+package entity
+
+import "errors"
+
+var (
+	ErrUnknownProvider         = errors.New("unknown identity provider")
+	ErrNoAuthRequest           = errors.New("couldn't find federated login request")
+	ErrStateMismatch           = errors.New("federated login state mismatch")
+	ErrDiscoveryFailed         = errors.New("couldn't fetch provider metadata")
+	ErrTokenExchangeFailed     = errors.New("provider rejected authorization code")
+	ErrInvalidIDToken          = errors.New("provider id token is invalid")
+	ErrIdentityNotLinked       = errors.New("external identity is not linked to any user")
+	ErrIdentityLinkedElsewhere = errors.New("external identity is linked to another user")
+)
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type AuthRequest struct {
+	Provider     string    `json:"provider"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	LinkUserID   uint      `json:"link_user_id,omitempty"`
+	ReturnTo     string    `json:"return_to"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
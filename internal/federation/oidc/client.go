@@ -0,0 +1,286 @@
+package oidc
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/federation/domain/entity"
+	tokenEntity "github.com/lightlink/auth-service/internal/token/domain/entity"
+	"github.com/lightlink/auth-service/internal/token/signer"
+)
+
+// Providers rotate keys without notice, so an unknown kid triggers a JWKS
+// refetch, but no more often than this.
+const jwksRefreshInterval = time.Minute
+
+type providerKeys struct {
+	keys      map[string]tokenEntity.JWK
+	fetchedAt time.Time
+}
+
+// Client talks to upstream OIDC providers and caches their discovery
+// documents and signing keys.
+type Client struct {
+	httpClient *http.Client
+	mu         *sync.Mutex
+	metadata   map[string]*entity.ProviderMetadata
+	keys       map[string]*providerKeys
+}
+
+func NewClient(httpClient *http.Client) *Client {
+	return &Client{
+		httpClient: httpClient,
+		mu:         &sync.Mutex{},
+		metadata:   map[string]*entity.ProviderMetadata{},
+		keys:       map[string]*providerKeys{},
+	}
+}
+
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+	Error   string `json:"error"`
+}
+
+func (c *Client) Discover(issuer string) (*entity.ProviderMetadata, error) {
+	c.mu.Lock()
+	metadata, ok := c.metadata[issuer]
+	c.mu.Unlock()
+	if ok {
+		return metadata, nil
+	}
+
+	metadata = &entity.ProviderMetadata{}
+	err := c.getJSON(strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata.Issuer != issuer || metadata.AuthorizationEndpoint == "" ||
+		metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, entity.ErrDiscoveryFailed
+	}
+
+	c.mu.Lock()
+	c.metadata[issuer] = metadata
+	c.mu.Unlock()
+
+	return metadata, nil
+}
+
+func (c *Client) ExchangeCode(provider *entity.Provider, metadata *entity.ProviderMetadata, code string, codeVerifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", provider.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+
+	request, err := http.NewRequest(http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(url.QueryEscape(provider.ClientID), url.QueryEscape(provider.ClientSecret))
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	tokens := &tokenResponse{}
+	err = json.Unmarshal(body, tokens)
+	if err != nil || response.StatusCode != http.StatusOK || tokens.IDToken == "" {
+		return "", entity.ErrTokenExchangeFailed
+	}
+
+	return tokens.IDToken, nil
+}
+
+func (c *Client) VerifyIDToken(provider *entity.Provider, metadata *entity.ProviderMetadata, rawIDToken string, nonce string) (*entity.ExternalIdentity, error) {
+	token, err := jwt.Parse(rawIDToken, func(token *jwt.Token) (interface{}, error) {
+		return c.keyfunc(metadata, token)
+	})
+	if err != nil || !token.Valid {
+		return nil, entity.ErrInvalidIDToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, entity.ErrInvalidIDToken
+	}
+
+	now := time.Now().Unix()
+	if !claims.VerifyIssuer(metadata.Issuer, true) ||
+		!claims.VerifyExpiresAt(now, true) ||
+		!audienceContains(claims["aud"], provider.ClientID) ||
+		claims["nonce"] != nonce {
+		return nil, entity.ErrInvalidIDToken
+	}
+
+	if azp, ok := claims["azp"].(string); ok && azp != provider.ClientID {
+		return nil, entity.ErrInvalidIDToken
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, entity.ErrInvalidIDToken
+	}
+
+	identity := &entity.ExternalIdentity{
+		Provider: provider.Name,
+		Subject:  subject,
+		AMR:      []string{},
+	}
+	identity.Email, _ = claims["email"].(string)
+	identity.PreferredUsername, _ = claims["preferred_username"].(string)
+
+	claimsAMR, _ := claims["amr"].([]interface{})
+	for _, claimsMethod := range claimsAMR {
+		if method, ok := claimsMethod.(string); ok {
+			identity.AMR = append(identity.AMR, method)
+		}
+	}
+
+	return identity, nil
+}
+
+func (c *Client) keyfunc(metadata *entity.ProviderMetadata, token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+
+	jwk, ok := c.cachedKey(metadata.Issuer, keyID)
+	if !ok {
+		err := c.refreshKeys(metadata)
+		if err != nil {
+			return nil, err
+		}
+
+		jwk, ok = c.cachedKey(metadata.Issuer, keyID)
+		if !ok {
+			return nil, tokenEntity.ErrUnknownKeyID
+		}
+	}
+
+	if !algorithmMatchesKey(token.Method.Alg(), &jwk) {
+		return nil, tokenEntity.ErrUnexpectedSigningMethod
+	}
+
+	return signer.ParsePublicJWK(&jwk)
+}
+
+func (c *Client) cachedKey(issuer string, keyID string) (tokenEntity.JWK, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.keys[issuer]
+	if !ok {
+		return tokenEntity.JWK{}, false
+	}
+
+	// Providers with a single key may omit kid from their tokens.
+	if keyID == "" && len(cached.keys) == 1 {
+		for _, jwk := range cached.keys {
+			return jwk, true
+		}
+	}
+
+	jwk, ok := cached.keys[keyID]
+
+	return jwk, ok
+}
+
+func (c *Client) refreshKeys(metadata *entity.ProviderMetadata) error {
+	c.mu.Lock()
+	cached, ok := c.keys[metadata.Issuer]
+	recentlyFetched := ok && time.Since(cached.fetchedAt) < jwksRefreshInterval
+	c.mu.Unlock()
+	if recentlyFetched {
+		return nil
+	}
+
+	jwkSet := &tokenEntity.JWKSet{}
+	err := c.getJSON(metadata.JWKSURI, jwkSet)
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]tokenEntity.JWK{}
+	for _, jwk := range jwkSet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		keys[jwk.Kid] = jwk
+	}
+
+	c.mu.Lock()
+	c.keys[metadata.Issuer] = &providerKeys{
+		keys:      keys,
+		fetchedAt: time.Now(),
+	}
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *Client) getJSON(location string, target interface{}) error {
+	response, err := c.httpClient.Get(location)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return entity.ErrDiscoveryFailed
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, target)
+}
+
+func algorithmMatchesKey(alg string, jwk *tokenEntity.JWK) bool {
+	if jwk.Alg != "" && jwk.Alg != alg {
+		return false
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case "EC":
+		return strings.HasPrefix(alg, "ES")
+	case "OKP":
+		return alg == "EdDSA"
+	}
+
+	return false
+}
+
+func audienceContains(audience interface{}, clientID string) bool {
+	switch aud := audience.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, candidate := range aud {
+			if candidate == clientID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
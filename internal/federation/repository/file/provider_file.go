@@ -0,0 +1,48 @@
+package file
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/lightlink/auth-service/internal/federation/domain/entity"
+	"github.com/lightlink/auth-service/internal/federation/domain/model"
+)
+
+type ProviderFileRepository struct {
+	providers map[string]*model.Provider
+}
+
+func NewProviderFileRepository(path string) (*ProviderFileRepository, error) {
+	repo := &ProviderFileRepository{
+		providers: map[string]*model.Provider{},
+	}
+	if path == "" {
+		return repo, nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := []*model.Provider{}
+	err = json.Unmarshal(contents, &providers)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, provider := range providers {
+		repo.providers[provider.Name] = provider
+	}
+
+	return repo, nil
+}
+
+func (repo *ProviderFileRepository) GetByName(name string) (*model.Provider, error) {
+	provider, ok := repo.providers[name]
+	if !ok {
+		return nil, entity.ErrUnknownProvider
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/federation/domain/dto"
+	"github.com/lightlink/auth-service/internal/federation/domain/entity"
+	"github.com/lightlink/auth-service/internal/federation/domain/model"
+)
+
+type AuthRequestRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewAuthRequestRedisRepository(conn redis.Conn) *AuthRequestRedisRepository {
+	return &AuthRequestRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+func authRequestKey(state string) string {
+	return "federation_states:" + state
+}
+
+func (repo *AuthRequestRedisRepository) Set(authRequestEntity *entity.AuthRequest, ttl time.Duration) error {
+	authRequestSerialized, err := json.Marshal(dto.AuthRequestEntityToModel(authRequestEntity))
+	if err != nil {
+		return err
+	}
+
+	repo.mu.Lock()
+	_, err = repo.redisConn.Do("SET", authRequestKey(authRequestEntity.State), authRequestSerialized, "EX", int(ttl.Seconds()))
+	repo.mu.Unlock()
+
+	return err
+}
+
+func (repo *AuthRequestRedisRepository) Pop(state string) (*model.AuthRequest, error) {
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("GET", authRequestKey(state))
+	repo.redisConn.Send("DEL", authRequestKey(state))
+	result, err := redis.Values(repo.redisConn.Do("EXEC"))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := redis.Bytes(result[0], nil)
+	if err == redis.ErrNil {
+		return nil, entity.ErrNoAuthRequest
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	authRequestModel := &model.AuthRequest{}
+	err = json.Unmarshal(bytes, authRequestModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return authRequestModel, nil
+}
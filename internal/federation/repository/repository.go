@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/lightlink/auth-service/internal/federation/domain/entity"
+	"github.com/lightlink/auth-service/internal/federation/domain/model"
+)
+
+type ProviderRepositoryI interface {
+	GetByName(name string) (*model.Provider, error)
+}
+
+type AuthRequestRepositoryI interface {
+	Set(authRequestEntity *entity.AuthRequest, ttl time.Duration) error
+	Pop(state string) (*model.AuthRequest, error)
+}
@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lightlink/auth-service/internal/federation/domain/dto"
+	"github.com/lightlink/auth-service/internal/federation/domain/entity"
+	"github.com/lightlink/auth-service/internal/federation/oidc"
+	federationRepo "github.com/lightlink/auth-service/internal/federation/repository"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	userDTO "github.com/lightlink/auth-service/internal/user/domain/dto"
+	userRepo "github.com/lightlink/auth-service/internal/user/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const authRequestTTL = 10 * time.Minute
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+type FederationUsecaseI interface {
+	BeginLogin(providerName string, accessToken string, returnTo string) (string, string, error)
+	FinishLogin(providerName string, state string, browserState string, code string) (*sessionEntity.Session, string, error)
+}
+
+type FederationUsecase struct {
+	providerRepo    federationRepo.ProviderRepositoryI
+	authRequestRepo federationRepo.AuthRequestRepositoryI
+	userRepo        userRepo.UserRepositoryI
+	sessionUC       sessionUsecase.SessionUsecaseI
+	oidcClient      *oidc.Client
+}
+
+func NewFederationUsecase(
+	providerRepository federationRepo.ProviderRepositoryI,
+	authRequestRepository federationRepo.AuthRequestRepositoryI,
+	userRepository userRepo.UserRepositoryI,
+	sessionUsecase sessionUsecase.SessionUsecaseI,
+	oidcClient *oidc.Client,
+) *FederationUsecase {
+	return &FederationUsecase{
+		providerRepo:    providerRepository,
+		authRequestRepo: authRequestRepository,
+		userRepo:        userRepository,
+		sessionUC:       sessionUsecase,
+		oidcClient:      oidcClient,
+	}
+}
+
+// BeginLogin returns the provider URL to send the browser to and the state
+// that must come back with it. A caller that is already logged in links the
+// external identity to their account instead of logging in.
+func (uc *FederationUsecase) BeginLogin(providerName string, accessToken string, returnTo string) (string, string, error) {
+	provider, err := uc.getProvider(providerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	metadata, err := uc.oidcClient.Discover(provider.Issuer)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := generateToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	nonce, err := generateToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	codeVerifier, err := generateToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	authRequest := &entity.AuthRequest{
+		State:        state,
+		Provider:     provider.Name,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ReturnTo:     sanitizeReturnTo(returnTo),
+		ExpiresAt:    time.Now().Add(authRequestTTL),
+	}
+
+	if accessToken != "" {
+		identity, err := uc.sessionUC.ValidateAccessToken(accessToken)
+		if err == nil && identity.ClientID == "" && !identity.Guest && !identity.Restricted {
+			authRequest.LinkUserID = identity.UserID
+		}
+	}
+
+	err = uc.authRequestRepo.Set(authRequest, authRequestTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	scopes := provider.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", provider.ClientID)
+	params.Set("redirect_uri", provider.RedirectURL)
+	params.Set("scope", strings.Join(scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return metadata.AuthorizationEndpoint + separator + params.Encode(), state, nil
+}
+
+// FinishLogin completes the login started by BeginLogin. Users with a local
+// second factor get a session only if the provider reports one of its own
+// in amr; otherwise the session usecase's MFARequiredError is returned
+// together with the return path, so the login can continue there.
+func (uc *FederationUsecase) FinishLogin(providerName string, state string, browserState string, code string) (*sessionEntity.Session, string, error) {
+	if state == "" || state != browserState {
+		return nil, "", entity.ErrStateMismatch
+	}
+
+	authRequestModel, err := uc.authRequestRepo.Pop(state)
+	if err != nil {
+		return nil, "", err
+	}
+
+	authRequest := dto.AuthRequestModelToEntity(state, authRequestModel)
+	if authRequest.Provider != providerName || time.Now().After(authRequest.ExpiresAt) {
+		return nil, "", entity.ErrStateMismatch
+	}
+
+	provider, err := uc.getProvider(providerName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	metadata, err := uc.oidcClient.Discover(provider.Issuer)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rawIDToken, err := uc.oidcClient.ExchangeCode(provider, metadata, code, authRequest.CodeVerifier)
+	if err != nil {
+		return nil, "", err
+	}
+
+	externalIdentity, err := uc.oidcClient.VerifyIDToken(provider, metadata, rawIDToken, authRequest.Nonce)
+	if err != nil {
+		return nil, "", err
+	}
+
+	user, err := uc.resolveUser(externalIdentity, authRequest.LinkUserID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	session, err := uc.sessionUC.CreateSession(user.Username, user.Id, externalIdentity.AMR)
+	if err != nil {
+		return nil, authRequest.ReturnTo, err
+	}
+
+	return session, authRequest.ReturnTo, nil
+}
+
+func (uc *FederationUsecase) resolveUser(externalIdentity *entity.ExternalIdentity, linkUserID uint) (*userDTO.UserTransfer, error) {
+	user, err := uc.userRepo.GetByExternalIdentity(externalIdentity.Provider, externalIdentity.Subject)
+	if err == nil {
+		if linkUserID != 0 && user.Id != linkUserID {
+			return nil, entity.ErrIdentityLinkedElsewhere
+		}
+		return user, nil
+	}
+
+	if st, ok := status.FromError(err); !ok || st.Code() != codes.NotFound {
+		return nil, err
+	}
+
+	if linkUserID == 0 {
+		return nil, entity.ErrIdentityNotLinked
+	}
+
+	err = uc.userRepo.LinkIdentity(linkUserID, externalIdentity.Provider, externalIdentity.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.userRepo.GetById(linkUserID)
+}
+
+func (uc *FederationUsecase) getProvider(providerName string) (*entity.Provider, error) {
+	providerModel, err := uc.providerRepo.GetByName(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ProviderModelToEntity(providerModel), nil
+}
+
+// sanitizeReturnTo only allows local paths so the callback can't be turned
+// into an open redirect.
+func sanitizeReturnTo(returnTo string) string {
+	if !strings.HasPrefix(returnTo, "/") ||
+		strings.HasPrefix(returnTo, "//") ||
+		strings.HasPrefix(returnTo, "/\\") {
+		return "/"
+	}
+
+	return returnTo
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/federation/domain/entity"
+	"github.com/lightlink/auth-service/internal/federation/domain/model"
+	"github.com/lightlink/auth-service/internal/federation/oidc"
+	federationRepo "github.com/lightlink/auth-service/internal/federation/repository/redis"
+	sessionDTO "github.com/lightlink/auth-service/internal/session/domain/dto"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	sessionRepo "github.com/lightlink/auth-service/internal/session/repository/redis"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	"github.com/lightlink/auth-service/internal/testutil"
+	tokenEntity "github.com/lightlink/auth-service/internal/token/domain/entity"
+	"github.com/lightlink/auth-service/internal/token/signer"
+	userDTO "github.com/lightlink/auth-service/internal/user/domain/dto"
+)
+
+const (
+	testClientID     = "auth-service"
+	testClientSecret = "provider secret"
+	testCode         = "provider-code"
+)
+
+// mockProvider is an OIDC provider serving discovery, JWKS and a token
+// endpoint that checks PKCE and returns idClaims as the signed ID token.
+type mockProvider struct {
+	server    *httptest.Server
+	signer    *signer.KeySigner
+	challenge string
+	idClaims  jwt.MapClaims
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	keySigner, err := signer.NewPrivateKeySigner("provider-key", privateKey)
+	if err != nil {
+		t.Fatalf("NewPrivateKeySigner: %v", err)
+	}
+
+	provider := &mockProvider{signer: keySigner}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&entity.ProviderMetadata{
+			Issuer:                provider.server.URL,
+			AuthorizationEndpoint: provider.server.URL + "/authorize",
+			TokenEndpoint:         provider.server.URL + "/token",
+			JWKSURI:               provider.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		jwk, _ := provider.signer.PublicJWK()
+		json.NewEncoder(w).Encode(&tokenEntity.JWKSet{Keys: []tokenEntity.JWK{*jwk}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ := r.BasicAuth()
+		verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if clientID != testClientID || clientSecret != url.QueryEscape(testClientSecret) ||
+			r.PostFormValue("code") != testCode ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != provider.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		idToken, err := provider.signer.Sign(provider.idClaims)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+	})
+
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+
+	return provider
+}
+
+type fakeProviderRepository struct {
+	provider *model.Provider
+}
+
+func (f *fakeProviderRepository) GetByName(name string) (*model.Provider, error) {
+	if name != f.provider.Name {
+		return nil, entity.ErrUnknownProvider
+	}
+
+	return f.provider, nil
+}
+
+type testEnv struct {
+	uc       *FederationUsecase
+	sessions *sessionUsecase.SessionUsecase
+	provider *mockProvider
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	base := testutil.NewEnv(t)
+	provider := newMockProvider(t)
+	users := testutil.NewUserRepository(
+		&userDTO.UserTransfer{Id: 1, Username: "alice", Email: "alice@example.com", EmailVerified: true},
+		&userDTO.UserTransfer{Id: 3, Username: "carol", Email: "carol@example.com", EmailVerified: true},
+	)
+	users.Identities["mock|alice-subject"] = 1
+	users.Identities["mock|carol-subject"] = 3
+
+	sessions := sessionUsecase.NewSessionUsecase(
+		sessionRepo.NewSessionRedisRepository(base.Dial()),
+		sessionRepo.NewRevocationRedisRepository(base.Dial()),
+		sessionRepo.NewTicketRedisRepository(base.Dial()),
+		users,
+		&testutil.MFAUsecase{Enabled: map[uint]bool{3: true}, Code: "123456"},
+		base.Signer,
+		nil,
+		0,
+	)
+
+	uc := NewFederationUsecase(
+		&fakeProviderRepository{provider: &model.Provider{
+			Name:         "mock",
+			Issuer:       provider.server.URL,
+			ClientID:     testClientID,
+			ClientSecret: testClientSecret,
+			RedirectURL:  "https://auth.example.com/api/federation/mock/callback",
+		}},
+		federationRepo.NewAuthRequestRedisRepository(base.Dial()),
+		users,
+		sessions,
+		oidc.NewClient(provider.server.Client()),
+	)
+
+	return &testEnv{uc: uc, sessions: sessions, provider: provider}
+}
+
+// begin starts a login and primes the provider with the PKCE challenge and a
+// valid ID token for subject, returning the state.
+func (env *testEnv) begin(t *testing.T, subject string) string {
+	t.Helper()
+
+	redirectURL, state, err := env.uc.BeginLogin("mock", "", "/rooms")
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+
+	parsed, err := url.Parse(redirectURL)
+	if err != nil {
+		t.Fatalf("parse redirect %q: %v", redirectURL, err)
+	}
+
+	query := parsed.Query()
+	if query.Get("state") != state || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("authorize URL %q lacks state or S256 challenge", redirectURL)
+	}
+
+	now := time.Now()
+	env.provider.challenge = query.Get("code_challenge")
+	env.provider.idClaims = jwt.MapClaims{
+		"iss":   env.provider.server.URL,
+		"aud":   testClientID,
+		"sub":   subject,
+		"nonce": query.Get("nonce"),
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"amr":   []string{"pwd"},
+	}
+
+	return state
+}
+
+func TestFinishLoginVerifiesProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(provider *mockProvider)
+		wantErr error
+	}{
+		{"valid", func(provider *mockProvider) {}, nil},
+		{"wrong issuer", func(provider *mockProvider) { provider.idClaims["iss"] = "https://evil.example.com" }, entity.ErrInvalidIDToken},
+		{"wrong audience", func(provider *mockProvider) { provider.idClaims["aud"] = "someone-else" }, entity.ErrInvalidIDToken},
+		{"wrong authorized party", func(provider *mockProvider) { provider.idClaims["azp"] = "someone-else" }, entity.ErrInvalidIDToken},
+		{"wrong nonce", func(provider *mockProvider) { provider.idClaims["nonce"] = "replayed" }, entity.ErrInvalidIDToken},
+		{"missing nonce", func(provider *mockProvider) { delete(provider.idClaims, "nonce") }, entity.ErrInvalidIDToken},
+		{"expired", func(provider *mockProvider) {
+			provider.idClaims["exp"] = time.Now().Add(-time.Minute).Unix()
+		}, entity.ErrInvalidIDToken},
+		{"pkce mismatch", func(provider *mockProvider) { provider.challenge = "challenge-of-another-login" }, entity.ErrTokenExchangeFailed},
+		{"unlinked subject", func(provider *mockProvider) { provider.idClaims["sub"] = "stranger" }, entity.ErrIdentityNotLinked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			state := env.begin(t, "alice-subject")
+			tt.tamper(env.provider)
+
+			session, returnTo, err := env.uc.FinishLogin("mock", state, state, testCode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FinishLogin err = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if session.UserID != 1 || returnTo != "/rooms" {
+				t.Errorf("FinishLogin = user %d, return to %q, want user 1, /rooms", session.UserID, returnTo)
+			}
+		})
+	}
+}
+
+func TestFinishLoginState(t *testing.T) {
+	env := newTestEnv(t)
+	state := env.begin(t, "alice-subject")
+
+	_, _, err := env.uc.FinishLogin("mock", state, "other-browser", testCode)
+	if !errors.Is(err, entity.ErrStateMismatch) {
+		t.Fatalf("FinishLogin from another browser err = %v, want %v", err, entity.ErrStateMismatch)
+	}
+
+	_, _, err = env.uc.FinishLogin("mock", state, state, testCode)
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+
+	_, _, err = env.uc.FinishLogin("mock", state, state, testCode)
+	if !errors.Is(err, entity.ErrNoAuthRequest) {
+		t.Fatalf("replayed FinishLogin err = %v, want %v", err, entity.ErrNoAuthRequest)
+	}
+}
+
+func TestFinishLoginLocalMFA(t *testing.T) {
+	tests := []struct {
+		name        string
+		subject     string
+		providerAMR []string
+		wantMFA     bool
+	}{
+		{"no local second factor", "alice-subject", []string{"pwd"}, false},
+		{"provider password only", "carol-subject", []string{"pwd"}, true},
+		{"provider without amr", "carol-subject", nil, true},
+		{"provider mfa", "carol-subject", []string{"pwd", "mfa"}, false},
+		{"provider otp", "carol-subject", []string{"pwd", "otp"}, false},
+		{"provider hardware key", "carol-subject", []string{"hwk"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			state := env.begin(t, tt.subject)
+			if tt.providerAMR == nil {
+				delete(env.provider.idClaims, "amr")
+			} else {
+				env.provider.idClaims["amr"] = tt.providerAMR
+			}
+
+			session, returnTo, err := env.uc.FinishLogin("mock", state, state, testCode)
+			mfaRequired := &sessionEntity.MFARequiredError{}
+			if errors.As(err, &mfaRequired) != tt.wantMFA {
+				t.Fatalf("FinishLogin err = %v, want mfa required %v", err, tt.wantMFA)
+			}
+
+			if !tt.wantMFA {
+				if err != nil || session == nil {
+					t.Fatalf("FinishLogin = %v, %v, want a session", session, err)
+				}
+				return
+			}
+
+			if session != nil || returnTo != "/rooms" {
+				t.Fatalf("FinishLogin = %v, %q, want no session and the return path", session, returnTo)
+			}
+
+			session, err = env.sessions.LoginMFA(&sessionDTO.LoginMFARequest{MFAToken: mfaRequired.MFAToken, Code: "123456"})
+			if err != nil {
+				t.Fatalf("LoginMFA: %v", err)
+			}
+
+			if session.UserID != 3 || !containsAll(session.AMR, sessionUsecase.AMROneTimeCode, sessionUsecase.AMRMultiFactor) {
+				t.Errorf("LoginMFA session = user %d amr %v, want user 3 with otp and mfa", session.UserID, session.AMR)
+			}
+		})
+	}
+}
+
+func containsAll(values []string, wanted ...string) bool {
+	for _, value := range wanted {
+		found := false
+		for _, candidate := range values {
+			if candidate == value {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
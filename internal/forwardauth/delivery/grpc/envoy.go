@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/forwardauth/usecase"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+)
+
+type EnvoyAuthorizationServer struct {
+	authv3.UnimplementedAuthorizationServer
+	forwardAuthUC usecase.ForwardAuthUsecaseI
+}
+
+func NewEnvoyAuthorizationServer(forwardAuthUsecase usecase.ForwardAuthUsecaseI) *EnvoyAuthorizationServer {
+	return &EnvoyAuthorizationServer{
+		forwardAuthUC: forwardAuthUsecase,
+	}
+}
+
+func (s *EnvoyAuthorizationServer) Check(ctx context.Context, request *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	httpAttributes := request.GetAttributes().GetRequest().GetHttp()
+	headers := httpAttributes.GetHeaders()
+
+	authorizeRequest := &dto.AuthorizeRequest{
+		Authorization: headers["authorization"],
+		Method:        httpAttributes.GetMethod(),
+		URI:           httpAttributes.GetPath(),
+	}
+
+	cookieRequest := &http.Request{Header: http.Header{"Cookie": {headers["cookie"]}}}
+	if accessCookie, err := cookieRequest.Cookie("access_token"); err == nil {
+		authorizeRequest.AccessToken = accessCookie.Value
+	}
+
+	decision, err := s.forwardAuthUC.Authorize(authorizeRequest)
+	if errors.Is(err, entity.ErrRestricted) {
+		return deniedResponse(codes.PermissionDenied, typev3.StatusCode_Forbidden, err), nil
+	}
+
+	if err != nil {
+		return deniedResponse(codes.Unauthenticated, typev3.StatusCode_Unauthorized, err), nil
+	}
+
+	upstreamHeaders := make([]*corev3.HeaderValueOption, 0, len(decision.Headers))
+	for name, value := range decision.Headers {
+		upstreamHeaders = append(upstreamHeaders, &corev3.HeaderValueOption{
+			Header: &corev3.HeaderValue{
+				Key:   name,
+				Value: value,
+			},
+			AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+		})
+	}
+
+	headersToRemove := []string{}
+	for _, name := range decision.HeadersToClear {
+		if _, ok := decision.Headers[name]; !ok {
+			headersToRemove = append(headersToRemove, name)
+		}
+	}
+
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{
+			Code: int32(codes.OK),
+		},
+		HttpResponse: &authv3.CheckResponse_OkResponse{
+			OkResponse: &authv3.OkHttpResponse{
+				Headers:         upstreamHeaders,
+				HeadersToRemove: headersToRemove,
+			},
+		},
+	}, nil
+}
+
+func deniedResponse(code codes.Code, httpCode typev3.StatusCode, err error) *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{
+			Code:    int32(code),
+			Message: err.Error(),
+		},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{
+			DeniedResponse: &authv3.DeniedHttpResponse{
+				Status: &typev3.HttpStatus{
+					Code: httpCode,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,55 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/entity"
+	"google.golang.org/grpc/codes"
+)
+
+type fakeForwardAuthUsecase struct {
+	err error
+}
+
+func (f *fakeForwardAuthUsecase) Authorize(authorizeRequest *dto.AuthorizeRequest) (*entity.Decision, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return &entity.Decision{Headers: map[string]string{"X-Subject-Type": "user"}}, nil
+}
+
+func TestCheckDenials(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+		wantHTTP typev3.StatusCode
+	}{
+		{"missing token", entity.ErrMissingToken, codes.Unauthenticated, typev3.StatusCode_Unauthorized},
+		{"restricted", entity.ErrRestricted, codes.PermissionDenied, typev3.StatusCode_Forbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewEnvoyAuthorizationServer(&fakeForwardAuthUsecase{err: tt.err})
+
+			response, err := server.Check(context.Background(), &authv3.CheckRequest{})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+
+			if codes.Code(response.GetStatus().GetCode()) != tt.wantCode {
+				t.Errorf("status = %v, want %v", codes.Code(response.GetStatus().GetCode()), tt.wantCode)
+			}
+
+			if got := response.GetDeniedResponse().GetStatus().GetCode(); got != tt.wantHTTP {
+				t.Errorf("http status = %v, want %v", got, tt.wantHTTP)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/forwardauth/usecase"
+)
+
+type ForwardAuthHandler struct {
+	forwardAuthUC usecase.ForwardAuthUsecaseI
+	pathPrefix    string
+}
+
+func NewForwardAuthHandler(forwardAuthUsecase usecase.ForwardAuthUsecaseI, pathPrefix string) *ForwardAuthHandler {
+	return &ForwardAuthHandler{
+		forwardAuthUC: forwardAuthUsecase,
+		pathPrefix:    pathPrefix,
+	}
+}
+
+func (h *ForwardAuthHandler) Check(w http.ResponseWriter, r *http.Request) {
+	authorizeRequest := &dto.AuthorizeRequest{
+		Authorization: r.Header.Get("Authorization"),
+		Method:        r.Header.Get("X-Forwarded-Method"),
+		URI:           r.Header.Get("X-Forwarded-Uri"),
+	}
+
+	if accessCookie, err := r.Cookie("access_token"); err == nil {
+		authorizeRequest.AccessToken = accessCookie.Value
+	}
+
+	if authorizeRequest.Method == "" {
+		authorizeRequest.Method = r.Method
+	}
+
+	// Envoy's HTTP ext_authz mode appends the original path to the check URL.
+	if authorizeRequest.URI == "" {
+		authorizeRequest.URI = strings.TrimPrefix(r.URL.RequestURI(), h.pathPrefix)
+	}
+
+	decision, err := h.forwardAuthUC.Authorize(authorizeRequest)
+	if errors.Is(err, entity.ErrRestricted) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Println("forward auth err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("forward auth err", err)
+		return
+	}
+
+	// Proxies copy these headers onto the upstream request, so an empty value
+	// overwrites whatever the client sent for an identity it doesn't have.
+	for _, name := range decision.HeadersToClear {
+		w.Header().Set(name, "")
+	}
+
+	for name, value := range decision.Headers {
+		w.Header().Set(name, value)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
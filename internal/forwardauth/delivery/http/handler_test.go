@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/entity"
+)
+
+type fakeForwardAuthUsecase struct {
+	decision *entity.Decision
+}
+
+func (f *fakeForwardAuthUsecase) Authorize(authorizeRequest *dto.AuthorizeRequest) (*entity.Decision, error) {
+	return f.decision, nil
+}
+
+func TestCheckClearsIdentityHeaders(t *testing.T) {
+	handler := NewForwardAuthHandler(&fakeForwardAuthUsecase{
+		decision: &entity.Decision{
+			Headers:        map[string]string{"X-Subject-Type": "user"},
+			HeadersToClear: []string{"X-User-ID", "X-User-Roles", "X-Subject-Type"},
+		},
+	}, "/api/forward-auth")
+
+	request := httptest.NewRequest(http.MethodGet, "/api/forward-auth/public", nil)
+	request.Header.Set("X-User-ID", "1")
+	recorder := httptest.NewRecorder()
+
+	handler.Check(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Check() status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	for _, name := range []string{"X-User-ID", "X-User-Roles"} {
+		values, ok := recorder.Header()[http.CanonicalHeaderKey(name)]
+		if !ok || len(values) != 1 || values[0] != "" {
+			t.Errorf("header %s = %v, want a single empty value", name, values)
+		}
+	}
+
+	if got := recorder.Header().Get("X-Subject-Type"); got != "user" {
+		t.Errorf("header X-Subject-Type = %q, want %q", got, "user")
+	}
+}
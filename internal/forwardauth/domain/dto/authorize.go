@@ -0,0 +1,8 @@
+package dto
+
+type AuthorizeRequest struct {
+	Authorization string
+	AccessToken   string
+	Method        string
+	URI           string
+}
@@ -0,0 +1,17 @@
+package entity
+
+type Config struct {
+	UserIDHeader      string
+	UsernameHeader    string
+	RolesHeader       string
+	SessionIDHeader   string
+	SubjectTypeHeader string
+	ClientIDHeader    string
+	ScopeHeader       string
+	GuestHeader       string
+	GuestIDHeader     string
+	RoomIDHeader      string
+	RestrictedHeader  string
+	PublicPaths       []string
+	RestrictedPaths   []string
+}
@@ -0,0 +1,6 @@
+package entity
+
+type Decision struct {
+	Headers        map[string]string
+	HeadersToClear []string
+}
@@ -0,0 +1,9 @@
+package entity
+
+import "errors"
+
+var (
+	ErrMissingToken = errors.New("missing access token")
+	ErrBadToken     = errors.New("malformed authorization header")
+	ErrRestricted   = errors.New("email address is not verified")
+)
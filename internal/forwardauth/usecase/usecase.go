@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/entity"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+)
+
+type ForwardAuthUsecaseI interface {
+	Authorize(authorizeRequest *dto.AuthorizeRequest) (*entity.Decision, error)
+}
+
+type ForwardAuthUsecase struct {
+	sessionUC sessionUsecase.SessionUsecaseI
+	config    *entity.Config
+}
+
+// DefaultRestrictedPaths lets an unverified account confirm its address, the
+// one thing its restricted session is for.
+var DefaultRestrictedPaths = []string{"/api/email/verify", "/api/email/verification/resend"}
+
+func NewForwardAuthUsecase(sessionUsecase sessionUsecase.SessionUsecaseI, config *entity.Config) *ForwardAuthUsecase {
+	return &ForwardAuthUsecase{
+		sessionUC: sessionUsecase,
+		config:    config,
+	}
+}
+
+func LoadConfigFromEnv() *entity.Config {
+	config := &entity.Config{
+		UserIDHeader:      envOrDefault("FORWARD_AUTH_USER_ID_HEADER", "X-User-ID"),
+		UsernameHeader:    envOrDefault("FORWARD_AUTH_USERNAME_HEADER", "X-Username"),
+		RolesHeader:       envOrDefault("FORWARD_AUTH_ROLES_HEADER", "X-User-Roles"),
+		SessionIDHeader:   envOrDefault("FORWARD_AUTH_SESSION_ID_HEADER", "X-Session-ID"),
+		SubjectTypeHeader: envOrDefault("FORWARD_AUTH_SUBJECT_TYPE_HEADER", "X-Subject-Type"),
+		ClientIDHeader:    envOrDefault("FORWARD_AUTH_CLIENT_ID_HEADER", "X-Client-ID"),
+		ScopeHeader:       envOrDefault("FORWARD_AUTH_SCOPE_HEADER", "X-Scope"),
+		GuestHeader:       envOrDefault("FORWARD_AUTH_GUEST_HEADER", "X-Guest"),
+		GuestIDHeader:     envOrDefault("FORWARD_AUTH_GUEST_ID_HEADER", "X-Guest-ID"),
+		RoomIDHeader:      envOrDefault("FORWARD_AUTH_ROOM_ID_HEADER", "X-Room-ID"),
+		RestrictedHeader:  envOrDefault("FORWARD_AUTH_RESTRICTED_HEADER", "X-Restricted"),
+		PublicPaths:       pathsFromEnv("FORWARD_AUTH_PUBLIC_PATHS"),
+		RestrictedPaths:   DefaultRestrictedPaths,
+	}
+
+	if os.Getenv("FORWARD_AUTH_RESTRICTED_PATHS") != "" {
+		config.RestrictedPaths = pathsFromEnv("FORWARD_AUTH_RESTRICTED_PATHS")
+	}
+
+	return config
+}
+
+func pathsFromEnv(name string) []string {
+	paths := []string{}
+	for _, path := range strings.Split(os.Getenv(name), ",") {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+func envOrDefault(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func (uc *ForwardAuthUsecase) Authorize(authorizeRequest *dto.AuthorizeRequest) (*entity.Decision, error) {
+	decision := &entity.Decision{
+		Headers:        map[string]string{},
+		HeadersToClear: uc.identityHeaders(),
+	}
+
+	if uc.isPublic(authorizeRequest.URI) {
+		return decision, nil
+	}
+
+	tokenString, err := extractToken(authorizeRequest)
+	if err != nil {
+		fmt.Println("forward auth denied", authorizeRequest.Method, authorizeRequest.URI, err)
+		return nil, err
+	}
+
+	identity, err := uc.sessionUC.ValidateAccessToken(tokenString)
+	if err != nil {
+		fmt.Println("forward auth denied", authorizeRequest.Method, authorizeRequest.URI, err)
+		return nil, err
+	}
+
+	if identity.ClientID != "" {
+		decision.Headers[uc.config.ClientIDHeader] = identity.ClientID
+		decision.Headers[uc.config.ScopeHeader] = identity.Scope
+	}
+
+	if identity.Service {
+		decision.Headers[uc.config.SubjectTypeHeader] = "service"
+		return decision, nil
+	}
+
+	if identity.Guest {
+		decision.Headers[uc.config.SubjectTypeHeader] = "guest"
+		decision.Headers[uc.config.GuestHeader] = "true"
+		decision.Headers[uc.config.GuestIDHeader] = identity.GuestID
+		decision.Headers[uc.config.RoomIDHeader] = identity.RoomID
+		decision.Headers[uc.config.UsernameHeader] = identity.Username
+		decision.Headers[uc.config.SessionIDHeader] = identity.SessionID
+		return decision, nil
+	}
+
+	// An unverified account only reaches the paths allowed for it.
+	if identity.Restricted {
+		if !HasPathPrefix(authorizeRequest.URI, uc.config.RestrictedPaths) {
+			fmt.Println("forward auth denied", authorizeRequest.Method, authorizeRequest.URI, entity.ErrRestricted)
+			return nil, entity.ErrRestricted
+		}
+
+		decision.Headers[uc.config.RestrictedHeader] = "true"
+	}
+
+	decision.Headers[uc.config.SubjectTypeHeader] = "user"
+	decision.Headers[uc.config.UserIDHeader] = strconv.Itoa(int(identity.UserID))
+	decision.Headers[uc.config.UsernameHeader] = identity.Username
+	decision.Headers[uc.config.RolesHeader] = strings.Join(identity.Roles, ",")
+	decision.Headers[uc.config.SessionIDHeader] = identity.SessionID
+
+	return decision, nil
+}
+
+func (uc *ForwardAuthUsecase) identityHeaders() []string {
+	return []string{
+		uc.config.UserIDHeader,
+		uc.config.UsernameHeader,
+		uc.config.RolesHeader,
+		uc.config.SessionIDHeader,
+		uc.config.SubjectTypeHeader,
+		uc.config.ClientIDHeader,
+		uc.config.ScopeHeader,
+		uc.config.GuestHeader,
+		uc.config.GuestIDHeader,
+		uc.config.RoomIDHeader,
+		uc.config.RestrictedHeader,
+	}
+}
+
+func (uc *ForwardAuthUsecase) isPublic(uri string) bool {
+	return HasPathPrefix(uri, uc.config.PublicPaths)
+}
+
+// HasPathPrefix matches whole path segments of the cleaned, decoded path, so
+// "/public" covers "/public/a" but not "/publicity", and dot segments or
+// encoded slashes can't walk out of a prefix.
+func HasPathPrefix(uri string, prefixes []string) bool {
+	requestPath, ok := normalizePath(uri)
+	if !ok {
+		return false
+	}
+
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if prefix == "" || requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizePath(uri string) (string, bool) {
+	rawPath := uri
+	if index := strings.IndexAny(rawPath, "?#"); index >= 0 {
+		rawPath = rawPath[:index]
+	}
+
+	decodedPath, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return "", false
+	}
+
+	return path.Clean("/" + decodedPath), true
+}
+
+func extractToken(authorizeRequest *dto.AuthorizeRequest) (string, error) {
+	if authorizeRequest.Authorization != "" {
+		fieldParts := strings.Split(authorizeRequest.Authorization, " ")
+		if len(fieldParts) != 2 || fieldParts[0] != "Bearer" {
+			return "", entity.ErrBadToken
+		}
+
+		return fieldParts[1], nil
+	}
+
+	if authorizeRequest.AccessToken != "" {
+		return authorizeRequest.AccessToken, nil
+	}
+
+	return "", entity.ErrMissingToken
+}
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/forwardauth/domain/entity"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+)
+
+type fakeSessionUsecase struct {
+	sessionUsecase.SessionUsecaseI
+	identities map[string]*sessionEntity.Identity
+}
+
+func (f *fakeSessionUsecase) ValidateAccessToken(tokenString string) (*sessionEntity.Identity, error) {
+	identity, ok := f.identities[tokenString]
+	if !ok {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	return identity, nil
+}
+
+func testConfig() *entity.Config {
+	return &entity.Config{
+		UserIDHeader:      "X-User-ID",
+		UsernameHeader:    "X-Username",
+		RolesHeader:       "X-User-Roles",
+		SessionIDHeader:   "X-Session-ID",
+		SubjectTypeHeader: "X-Subject-Type",
+		ClientIDHeader:    "X-Client-ID",
+		ScopeHeader:       "X-Scope",
+		GuestHeader:       "X-Guest",
+		GuestIDHeader:     "X-Guest-ID",
+		RoomIDHeader:      "X-Room-ID",
+		RestrictedHeader:  "X-Restricted",
+		PublicPaths:       []string{"/public", "/assets/"},
+		RestrictedPaths:   []string{"/api/email"},
+	}
+}
+
+func TestHasPathPrefix(t *testing.T) {
+	prefixes := []string{"/public", "/assets/"}
+
+	tests := []struct {
+		name string
+		uri  string
+		want bool
+	}{
+		{"exact", "/public", true},
+		{"child", "/public/index.html", true},
+		{"query", "/public?x=1", true},
+		{"fragment", "/public#top", true},
+		{"trailing slash prefix", "/assets/app.js", true},
+		{"trailing slash prefix exact", "/assets", true},
+		{"sibling", "/publicity", false},
+		{"dot segments", "/public/../admin", false},
+		{"encoded dot segments", "/public%2F..%2Fadmin", false},
+		{"encoded prefix", "/%70ublic/a", true},
+		{"double slash", "//public/a", true},
+		{"query does not match", "/admin?/public", false},
+		{"bad escape", "/public/%zz", false},
+		{"relative", "public/a", true},
+		{"unrelated", "/admin", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := HasPathPrefix(test.uri, prefixes)
+			if got != test.want {
+				t.Errorf("HasPathPrefix(%q) = %v, want %v", test.uri, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	sessionUC := &fakeSessionUsecase{
+		identities: map[string]*sessionEntity.Identity{
+			"user": {
+				UserID:    7,
+				Username:  "alice",
+				SessionID: "sid",
+				Roles:     []string{"admin"},
+			},
+			"restricted": {
+				UserID:     8,
+				Username:   "bob",
+				SessionID:  "sid",
+				Roles:      []string{},
+				Restricted: true,
+			},
+		},
+	}
+	uc := NewForwardAuthUsecase(sessionUC, testConfig())
+
+	tests := []struct {
+		name        string
+		request     *dto.AuthorizeRequest
+		wantErr     error
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "public path without token",
+			request:     &dto.AuthorizeRequest{URI: "/public/a"},
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:    "traversal out of public path",
+			request: &dto.AuthorizeRequest{URI: "/public/../admin"},
+			wantErr: entity.ErrMissingToken,
+		},
+		{
+			name:    "malformed authorization",
+			request: &dto.AuthorizeRequest{URI: "/admin", Authorization: "Basic abc"},
+			wantErr: entity.ErrBadToken,
+		},
+		{
+			name:    "invalid token",
+			request: &dto.AuthorizeRequest{URI: "/admin", Authorization: "Bearer nope"},
+			wantErr: sessionEntity.ErrInvalidToken,
+		},
+		{
+			name:    "user from cookie",
+			request: &dto.AuthorizeRequest{URI: "/admin", AccessToken: "user"},
+			wantHeaders: map[string]string{
+				"X-Subject-Type": "user",
+				"X-User-ID":      "7",
+				"X-Username":     "alice",
+				"X-User-Roles":   "admin",
+				"X-Session-ID":   "sid",
+			},
+		},
+		{
+			name:    "restricted outside allowlist",
+			request: &dto.AuthorizeRequest{URI: "/admin", Authorization: "Bearer restricted"},
+			wantErr: entity.ErrRestricted,
+		},
+		{
+			name:    "restricted inside allowlist",
+			request: &dto.AuthorizeRequest{URI: "/api/email/verify", Authorization: "Bearer restricted"},
+			wantHeaders: map[string]string{
+				"X-Subject-Type": "user",
+				"X-User-ID":      "8",
+				"X-Username":     "bob",
+				"X-User-Roles":   "",
+				"X-Session-ID":   "sid",
+				"X-Restricted":   "true",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decision, err := uc.Authorize(test.request)
+			if err != test.wantErr {
+				t.Fatalf("Authorize() err = %v, want %v", err, test.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if len(decision.Headers) != len(test.wantHeaders) {
+				t.Errorf("Authorize() headers = %v, want %v", decision.Headers, test.wantHeaders)
+			}
+
+			for name, value := range test.wantHeaders {
+				if decision.Headers[name] != value {
+					t.Errorf("header %s = %q, want %q", name, decision.Headers[name], value)
+				}
+			}
+
+			if len(decision.HeadersToClear) != 11 {
+				t.Errorf("Authorize() clears %d headers, want 11", len(decision.HeadersToClear))
+			}
+		})
+	}
+}
+
+func TestAuthorizeRestrictedWithoutAllowlist(t *testing.T) {
+	config := testConfig()
+	config.RestrictedPaths = []string{}
+	uc := NewForwardAuthUsecase(&fakeSessionUsecase{
+		identities: map[string]*sessionEntity.Identity{
+			"restricted": {UserID: 8, Username: "bob", SessionID: "sid", Restricted: true},
+		},
+	}, config)
+
+	for _, uri := range []string{"/api/email/verify", "/", "/admin"} {
+		_, err := uc.Authorize(&dto.AuthorizeRequest{URI: uri, Authorization: "Bearer restricted"})
+		if err != entity.ErrRestricted {
+			t.Errorf("Authorize(%s) err = %v, want %v", uri, err, entity.ErrRestricted)
+		}
+	}
+
+	_, err := uc.Authorize(&dto.AuthorizeRequest{URI: "/public/index.html", Authorization: "Bearer restricted"})
+	if err != nil {
+		t.Errorf("Authorize of a public path: %v", err)
+	}
+}
+
+func TestLoadConfigFromEnvAllowsEmailVerification(t *testing.T) {
+	t.Setenv("FORWARD_AUTH_RESTRICTED_PATHS", "")
+
+	uc := NewForwardAuthUsecase(&fakeSessionUsecase{
+		identities: map[string]*sessionEntity.Identity{
+			"restricted": {UserID: 8, Username: "bob", SessionID: "sid", Restricted: true},
+		},
+	}, LoadConfigFromEnv())
+
+	for _, uri := range []string{"/api/email/verify", "/api/email/verification/resend"} {
+		decision, err := uc.Authorize(&dto.AuthorizeRequest{URI: uri, Authorization: "Bearer restricted"})
+		if err != nil {
+			t.Errorf("Authorize(%s): %v", uri, err)
+			continue
+		}
+
+		if decision.Headers["X-Restricted"] != "true" {
+			t.Errorf("Authorize(%s) X-Restricted = %q, want true", uri, decision.Headers["X-Restricted"])
+		}
+	}
+
+	_, err := uc.Authorize(&dto.AuthorizeRequest{URI: "/api/rooms", Authorization: "Bearer restricted"})
+	if err != entity.ErrRestricted {
+		t.Errorf("Authorize(/api/rooms) err = %v, want %v", err, entity.ErrRestricted)
+	}
+}
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// FileMailer appends every message to a file instead of delivering it. An
+// empty path or "-" writes to stdout.
+type FileMailer struct {
+	output io.Writer
+	from   string
+	mu     *sync.Mutex
+}
+
+func NewFileMailer(path string, from string) (*FileMailer, error) {
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+
+	var output io.Writer = os.Stdout
+	if path != "" && path != "-" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+
+		output = file
+	}
+
+	return &FileMailer{
+		output: output,
+		from:   from,
+		mu:     &sync.Mutex{},
+	}, nil
+}
+
+func (m *FileMailer) Send(message *Message) error {
+	rendered, err := formatMessage(m.from, message)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err = m.output.Write(append(rendered, []byte("\r\n")...))
+
+	return err
+}
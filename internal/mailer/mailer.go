@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownTransport = errors.New("unknown mail transport")
+	ErrNoSender         = errors.New("mail sender address is not configured")
+	ErrInvalidRecipient = errors.New("mail recipient is invalid")
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(message *Message) error
+}
+
+// LoadMailerFromEnv picks the transport named by MAIL_TRANSPORT. Without one
+// mail is written to MAIL_FILE, or to stdout, which is enough for local runs.
+func LoadMailerFromEnv() (Mailer, error) {
+	switch os.Getenv("MAIL_TRANSPORT") {
+	case "", "file":
+		return NewFileMailer(os.Getenv("MAIL_FILE"), os.Getenv("MAIL_FROM"))
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+
+		return NewSMTPMailer(&SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+			Timeout:  10 * time.Second,
+		})
+	}
+
+	return nil, ErrUnknownTransport
+}
+
+// formatMessage renders a plain text RFC 5322 message.
+func formatMessage(from string, message *Message) ([]byte, error) {
+	if strings.ContainsAny(message.To, "\r\n") || message.To == "" {
+		return nil, ErrInvalidRecipient
+	}
+
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(message.Subject)
+
+	builder := &strings.Builder{}
+	builder.WriteString("From: " + from + "\r\n")
+	builder.WriteString("To: " + message.To + "\r\n")
+	builder.WriteString("Subject: " + subject + "\r\n")
+	builder.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	builder.WriteString("\r\n")
+
+	return []byte(builder.String()), nil
+}
@@ -0,0 +1,96 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+type SMTPMailer struct {
+	config *SMTPConfig
+}
+
+func NewSMTPMailer(config *SMTPConfig) (*SMTPMailer, error) {
+	if config.From == "" {
+		return nil, ErrNoSender
+	}
+
+	return &SMTPMailer{
+		config: config,
+	}, nil
+}
+
+// Send upgrades to TLS whenever the server offers STARTTLS; credentials are
+// only sent once it has, since smtp.PlainAuth refuses plaintext connections
+// to anything but localhost.
+func (m *SMTPMailer) Send(message *Message) error {
+	rendered, err := formatMessage(m.config.From, message)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(m.config.Host, m.config.Port), m.config.Timeout)
+	if err != nil {
+		return err
+	}
+
+	conn.SetDeadline(time.Now().Add(m.config.Timeout))
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: m.config.Host})
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.config.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(m.config.From)
+	if err != nil {
+		return err
+	}
+
+	err = client.Rcpt(message.To)
+	if err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(rendered)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
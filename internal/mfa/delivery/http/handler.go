@@ -0,0 +1,115 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/lightlink/auth-service/internal/mfa/domain/dto"
+	"github.com/lightlink/auth-service/internal/mfa/domain/entity"
+	"github.com/lightlink/auth-service/internal/mfa/usecase"
+)
+
+type MFAHandler struct {
+	mfaUC usecase.MFAUsecaseI
+}
+
+func NewMFAHandler(mfaUsecase usecase.MFAUsecaseI) *MFAHandler {
+	return &MFAHandler{
+		mfaUC: mfaUsecase,
+	}
+}
+
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	userIDString := r.Header.Get("X-User-ID")
+	userID64, err := strconv.ParseUint(userIDString, 10, 32)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	enrollResponse, err := h.mfaUC.Enroll(uint(userID64), r.Header.Get("X-Username"))
+	if errors.Is(err, entity.ErrAlreadyEnabled) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Println("mfa enroll err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("mfa enroll err", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, enrollResponse)
+}
+
+func (h *MFAHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	userIDString := r.Header.Get("X-User-ID")
+	userID64, err := strconv.ParseUint(userIDString, 10, 32)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	activateRequest := &dto.ActivateRequest{}
+	err = json.Unmarshal(body, activateRequest)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	activateResponse, err := h.mfaUC.Activate(uint(userID64), activateRequest.Code)
+	if errors.Is(err, entity.ErrInvalidCode) || errors.Is(err, entity.ErrNotEnrolled) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("mfa activate err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrAlreadyEnabled) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Println("mfa activate err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("mfa activate err", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, activateResponse)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("marshal err", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"github.com/lightlink/auth-service/internal/mfa/domain/entity"
+	"github.com/lightlink/auth-service/internal/mfa/domain/model"
+)
+
+type EnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type ActivateRequest struct {
+	Code string `json:"code"`
+}
+
+type ActivateResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func TOTPEntityToModel(totpEntity *entity.TOTP) *model.TOTP {
+	return &model.TOTP{
+		UserID:  totpEntity.UserID,
+		Secret:  totpEntity.Secret,
+		Enabled: totpEntity.Enabled,
+	}
+}
+
+func TOTPModelToEntity(totpModel *model.TOTP) *entity.TOTP {
+	return &entity.TOTP{
+		UserID:  totpModel.UserID,
+		Secret:  totpModel.Secret,
+		Enabled: totpModel.Enabled,
+	}
+}
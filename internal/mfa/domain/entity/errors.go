@@ -0,0 +1,10 @@
+package entity
+
+import "errors"
+
+var (
+	ErrNotEnrolled     = errors.New("totp is not enrolled")
+	ErrAlreadyEnabled  = errors.New("totp is already enabled")
+	ErrInvalidCode     = errors.New("invalid verification code")
+	ErrTooManyAttempts = errors.New("too many failed verification attempts")
+)
@@ -0,0 +1,7 @@
+package entity
+
+type TOTP struct {
+	UserID  uint
+	Secret  string
+	Enabled bool
+}
@@ -0,0 +1,7 @@
+package model
+
+type TOTP struct {
+	UserID  uint   `json:"user_id"`
+	Secret  string `json:"secret"`
+	Enabled bool   `json:"enabled"`
+}
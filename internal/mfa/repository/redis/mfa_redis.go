@@ -0,0 +1,172 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/mfa/domain/dto"
+	"github.com/lightlink/auth-service/internal/mfa/domain/entity"
+	"github.com/lightlink/auth-service/internal/mfa/domain/model"
+)
+
+// A code stays valid for at most three 30 second steps, so the used step
+// marker only has to outlive that window.
+const usedStepTTL = 90
+
+type MFARedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewMFARedisRepository(conn redis.Conn) *MFARedisRepository {
+	return &MFARedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+func totpKey(userID uint) string {
+	return "mfa_totp:" + strconv.Itoa(int(userID))
+}
+
+func usedStepKey(userID uint, step int64) string {
+	return "mfa_totp_used:" + strconv.Itoa(int(userID)) + ":" + strconv.FormatInt(step, 10)
+}
+
+func failedAttemptsKey(userID uint) string {
+	return "mfa_failed_attempts:" + strconv.Itoa(int(userID))
+}
+
+// The lockout window starts with the first failure and isn't extended by
+// later ones.
+var recordFailureScript = redis.NewScript(1, `
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+func recoveryCodesKey(userID uint) string {
+	return "mfa_recovery_codes:" + strconv.Itoa(int(userID))
+}
+
+func (repo *MFARedisRepository) SetTOTP(totpEntity *entity.TOTP) (*model.TOTP, error) {
+	totpModel := dto.TOTPEntityToModel(totpEntity)
+	totpSerialized, err := json.Marshal(totpModel)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.mu.Lock()
+	result, err := redis.String(repo.redisConn.Do("SET", totpKey(totpModel.UserID), totpSerialized))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if result != "OK" {
+		return nil, fmt.Errorf("unexpected Redis response: %v", result)
+	}
+
+	return totpModel, nil
+}
+
+func (repo *MFARedisRepository) GetTOTP(userID uint) (*model.TOTP, error) {
+	repo.mu.Lock()
+	bytes, err := redis.Bytes(repo.redisConn.Do("GET", totpKey(userID)))
+	repo.mu.Unlock()
+
+	if err == redis.ErrNil {
+		return nil, entity.ErrNotEnrolled
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	totpModel := &model.TOTP{}
+	err = json.Unmarshal(bytes, totpModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return totpModel, nil
+}
+
+func (repo *MFARedisRepository) MarkStepUsed(userID uint, step int64) (bool, error) {
+	repo.mu.Lock()
+	_, err := redis.String(repo.redisConn.Do("SET", usedStepKey(userID, step), 1, "NX", "EX", usedStepTTL))
+	repo.mu.Unlock()
+
+	if err == redis.ErrNil {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repo *MFARedisRepository) SetRecoveryCodes(userID uint, codeHashes []string) error {
+	args := []interface{}{recoveryCodesKey(userID)}
+	for _, codeHash := range codeHashes {
+		args = append(args, codeHash)
+	}
+
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("DEL", recoveryCodesKey(userID))
+	repo.redisConn.Send("SADD", args...)
+	_, err := repo.redisConn.Do("EXEC")
+	repo.mu.Unlock()
+
+	return err
+}
+
+func (repo *MFARedisRepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	repo.mu.Lock()
+	removed, err := redis.Int(repo.redisConn.Do("SREM", recoveryCodesKey(userID), codeHash))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return false, err
+	}
+
+	return removed == 1, nil
+}
+
+func (repo *MFARedisRepository) RecordFailure(userID uint, window time.Duration) (int, error) {
+	repo.mu.Lock()
+	count, err := redis.Int(recordFailureScript.Do(repo.redisConn, failedAttemptsKey(userID), window.Milliseconds()))
+	repo.mu.Unlock()
+
+	return count, err
+}
+
+func (repo *MFARedisRepository) CountFailures(userID uint) (int, error) {
+	repo.mu.Lock()
+	count, err := redis.Int(repo.redisConn.Do("GET", failedAttemptsKey(userID)))
+	repo.mu.Unlock()
+
+	if err == redis.ErrNil {
+		return 0, nil
+	}
+
+	return count, err
+}
+
+func (repo *MFARedisRepository) ClearFailures(userID uint) error {
+	repo.mu.Lock()
+	_, err := repo.redisConn.Do("DEL", failedAttemptsKey(userID))
+	repo.mu.Unlock()
+
+	return err
+}
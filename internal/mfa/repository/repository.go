@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/lightlink/auth-service/internal/mfa/domain/entity"
+	"github.com/lightlink/auth-service/internal/mfa/domain/model"
+)
+
+type MFARepositoryI interface {
+	SetTOTP(totpEntity *entity.TOTP) (*model.TOTP, error)
+	GetTOTP(userID uint) (*model.TOTP, error)
+	MarkStepUsed(userID uint, step int64) (bool, error)
+	SetRecoveryCodes(userID uint, codeHashes []string) error
+	UseRecoveryCode(userID uint, codeHash string) (bool, error)
+	RecordFailure(userID uint, window time.Duration) (int, error)
+	CountFailures(userID uint) (int, error)
+	ClearFailures(userID uint) error
+}
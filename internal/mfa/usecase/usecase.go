@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lightlink/auth-service/internal/mfa/domain/dto"
+	"github.com/lightlink/auth-service/internal/mfa/domain/entity"
+	mfaRepo "github.com/lightlink/auth-service/internal/mfa/repository"
+	"github.com/lightlink/auth-service/internal/mfa/totp"
+)
+
+const recoveryCodeCount = 10
+
+const (
+	maxFailedAttempts    = 5
+	failedAttemptsWindow = 15 * time.Minute
+)
+
+type MFAUsecaseI interface {
+	Enroll(userID uint, username string) (*dto.EnrollResponse, error)
+	Activate(userID uint, code string) (*dto.ActivateResponse, error)
+	IsEnabled(userID uint) (bool, error)
+	Verify(userID uint, code string) error
+}
+
+type MFAUsecase struct {
+	mfaRepo mfaRepo.MFARepositoryI
+}
+
+func NewMFAUsecase(mfaRepository mfaRepo.MFARepositoryI) *MFAUsecase {
+	return &MFAUsecase{
+		mfaRepo: mfaRepository,
+	}
+}
+
+func (uc *MFAUsecase) Enroll(userID uint, username string) (*dto.EnrollResponse, error) {
+	existing, err := uc.mfaRepo.GetTOTP(userID)
+	if err == nil && existing.Enabled {
+		return nil, entity.ErrAlreadyEnabled
+	}
+
+	if err != nil && err != entity.ErrNotEnrolled {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.mfaRepo.SetTOTP(&entity.TOTP{
+		UserID:  userID,
+		Secret:  secret,
+		Enabled: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := os.Getenv("MFA_ISSUER")
+	if issuer == "" {
+		issuer = "LightLink"
+	}
+
+	return &dto.EnrollResponse{
+		Secret: secret,
+		URI:    totp.URI(issuer, username, secret),
+	}, nil
+}
+
+func (uc *MFAUsecase) Activate(userID uint, code string) (*dto.ActivateResponse, error) {
+	totpModel, err := uc.mfaRepo.GetTOTP(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if totpModel.Enabled {
+		return nil, entity.ErrAlreadyEnabled
+	}
+
+	err = uc.verifyTOTP(userID, totpModel.Secret, code)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes := make([]string, 0, recoveryCodeCount)
+	recoveryCodeHashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		recoveryCode, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		recoveryCodes = append(recoveryCodes, recoveryCode)
+		recoveryCodeHashes = append(recoveryCodeHashes, hashRecoveryCode(recoveryCode))
+	}
+
+	err = uc.mfaRepo.SetRecoveryCodes(userID, recoveryCodeHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.mfaRepo.SetTOTP(&entity.TOTP{
+		UserID:  totpModel.UserID,
+		Secret:  totpModel.Secret,
+		Enabled: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ActivateResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (uc *MFAUsecase) IsEnabled(userID uint) (bool, error) {
+	totpModel, err := uc.mfaRepo.GetTOTP(userID)
+	if err == entity.ErrNotEnrolled {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return totpModel.Enabled, nil
+}
+
+// Verify gives up after maxFailedAttempts wrong codes in a row. Until the
+// window runs out even a correct code is refused, otherwise a six digit code
+// could still be guessed by spreading the attempts over fresh logins.
+func (uc *MFAUsecase) Verify(userID uint, code string) error {
+	totpModel, err := uc.mfaRepo.GetTOTP(userID)
+	if err != nil {
+		return err
+	}
+
+	if !totpModel.Enabled {
+		return entity.ErrNotEnrolled
+	}
+
+	failures, err := uc.mfaRepo.CountFailures(userID)
+	if err != nil {
+		return err
+	}
+
+	if failures >= maxFailedAttempts {
+		return entity.ErrTooManyAttempts
+	}
+
+	err = uc.verifyCode(userID, totpModel.Secret, code)
+	if err == entity.ErrInvalidCode {
+		failures, err = uc.mfaRepo.RecordFailure(userID, failedAttemptsWindow)
+		if err != nil {
+			return err
+		}
+
+		if failures >= maxFailedAttempts {
+			return entity.ErrTooManyAttempts
+		}
+
+		return entity.ErrInvalidCode
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return uc.mfaRepo.ClearFailures(userID)
+}
+
+func (uc *MFAUsecase) verifyCode(userID uint, secret string, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return uc.verifyTOTP(userID, secret, code)
+	}
+
+	used, err := uc.mfaRepo.UseRecoveryCode(userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+
+	if !used {
+		return entity.ErrInvalidCode
+	}
+
+	return nil
+}
+
+func (uc *MFAUsecase) verifyTOTP(userID uint, secret string, code string) error {
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return entity.ErrInvalidCode
+	}
+
+	fresh, err := uc.mfaRepo.MarkStepUsed(userID, step)
+	if err != nil {
+		return err
+	}
+
+	if !fresh {
+		return entity.ErrInvalidCode
+	}
+
+	return nil
+}
+
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 5)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+
+	return code[:4] + "-" + code[4:], nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/mfa/domain/entity"
+	mfaRepo "github.com/lightlink/auth-service/internal/mfa/repository/redis"
+	"github.com/lightlink/auth-service/internal/mfa/totp"
+)
+
+func newTestUsecase(t *testing.T) (*MFAUsecase, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	conn, err := redis.Dial("tcp", server.Addr())
+	if err != nil {
+		t.Fatalf("dial miniredis: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return NewMFAUsecase(mfaRepo.NewMFARedisRepository(conn)), server
+}
+
+// enroll turns TOTP on for the user and returns the secret with the recovery
+// codes handed out on activation.
+func enroll(t *testing.T, uc *MFAUsecase, userID uint) (string, []string) {
+	t.Helper()
+
+	enrollment, err := uc.Enroll(userID, "alice")
+	if err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+
+	activation, err := uc.Activate(userID, code)
+	if err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+
+	return enrollment.Secret, activation.RecoveryCodes
+}
+
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+
+	return code
+}
+
+func TestVerifyLocksOutAfterFailedAttempts(t *testing.T) {
+	uc, server := newTestUsecase(t)
+	secret, recoveryCodes := enroll(t, uc, 1)
+
+	for attempt := 1; attempt < maxFailedAttempts; attempt++ {
+		err := uc.Verify(1, "000000")
+		if err != entity.ErrInvalidCode {
+			t.Fatalf("attempt %d: Verify() = %v, want %v", attempt, err, entity.ErrInvalidCode)
+		}
+	}
+
+	err := uc.Verify(1, "not-a-code")
+	if err != entity.ErrTooManyAttempts {
+		t.Fatalf("last attempt: Verify() = %v, want %v", err, entity.ErrTooManyAttempts)
+	}
+
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"totp", currentCode(t, secret)},
+		{"recovery code", recoveryCodes[0]},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := uc.Verify(1, test.code)
+			if err != entity.ErrTooManyAttempts {
+				t.Errorf("Verify() while locked = %v, want %v", err, entity.ErrTooManyAttempts)
+			}
+		})
+	}
+
+	server.FastForward(failedAttemptsWindow)
+
+	err = uc.Verify(1, recoveryCodes[0])
+	if err != nil {
+		t.Fatalf("Verify() after window = %v, want nil", err)
+	}
+}
+
+func TestVerifyClearsFailuresOnSuccess(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	_, recoveryCodes := enroll(t, uc, 1)
+
+	for round := 0; round < 2; round++ {
+		for attempt := 1; attempt < maxFailedAttempts; attempt++ {
+			err := uc.Verify(1, "000000")
+			if err != entity.ErrInvalidCode {
+				t.Fatalf("round %d attempt %d: Verify() = %v, want %v", round, attempt, err, entity.ErrInvalidCode)
+			}
+		}
+
+		err := uc.Verify(1, recoveryCodes[round])
+		if err != nil {
+			t.Fatalf("round %d: Verify() = %v, want nil", round, err)
+		}
+	}
+}
+
+func TestVerifyCountsPerUser(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	enroll(t, uc, 1)
+	_, recoveryCodes := enroll(t, uc, 2)
+
+	for attempt := 0; attempt < maxFailedAttempts; attempt++ {
+		uc.Verify(1, "000000")
+	}
+
+	err := uc.Verify(2, recoveryCodes[0])
+	if err != nil {
+		t.Fatalf("Verify() for another user = %v, want nil", err)
+	}
+}
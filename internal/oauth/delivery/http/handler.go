@@ -0,0 +1,439 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lightlink/auth-service/internal/oauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/oauth/usecase"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+)
+
+type OAuthHandler struct {
+	oauthUC    usecase.OAuthUsecaseI
+	loginURL   string
+	consentURL string
+}
+
+func NewOAuthHandler(oauthUsecase usecase.OAuthUsecaseI, loginURL string, consentURL string) *OAuthHandler {
+	return &OAuthHandler{
+		oauthUC:    oauthUsecase,
+		loginURL:   loginURL,
+		consentURL: consentURL,
+	}
+}
+
+func (h *OAuthHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	authorizeRequest := &dto.AuthorizeRequest{
+		ResponseType:        query.Get("response_type"),
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+		Nonce:               query.Get("nonce"),
+		Prompt:              query.Get("prompt"),
+	}
+
+	redirectURL, err := h.oauthUC.Authorize(accessTokenFromRequest(r), authorizeRequest)
+	// A URL is returned whenever the error belongs to the client, e.g.
+	// login_required for prompt=none, so it takes precedence.
+	if redirectURL != "" {
+		if err != nil {
+			fmt.Println("oauth authorize err", err)
+		}
+
+		http.Redirect(w, r, redirectURL, http.StatusFound)
+		return
+	}
+
+	if errors.Is(err, entity.ErrLoginRequired) {
+		if h.loginURL == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Println("oauth authorize: login required")
+			return
+		}
+
+		http.Redirect(w, r, h.loginURL+"?return_to="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+		return
+	}
+
+	if errors.Is(err, entity.ErrConsentRequired) {
+		if h.consentURL == "" {
+			writeJSON(w, http.StatusForbidden, &dto.ErrorResponse{Error: err.Error()})
+			fmt.Println("oauth authorize: consent required")
+			return
+		}
+
+		params := url.Values{}
+		params.Set("return_to", r.URL.RequestURI())
+		params.Set("client_id", authorizeRequest.ClientID)
+		params.Set("redirect_uri", authorizeRequest.RedirectURI)
+		params.Set("scope", authorizeRequest.Scope)
+		params.Set("state", authorizeRequest.State)
+		http.Redirect(w, r, h.consentURL+"?"+params.Encode(), http.StatusFound)
+		return
+	}
+
+	if errors.Is(err, entity.ErrInvalidClient) || errors.Is(err, entity.ErrInvalidRedirectURI) {
+		writeJSON(w, http.StatusBadRequest, &dto.ErrorResponse{
+			Error:            entity.ErrInvalidRequest.Error(),
+			ErrorDescription: err.Error(),
+		})
+		return
+	}
+
+	/*Handle*/
+	w.WriteHeader(http.StatusInternalServerError)
+	fmt.Println("oauth authorize err", err)
+}
+
+func (h *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		writeError(w, entity.ErrInvalidRequest)
+		return
+	}
+
+	tokenRequest := &dto.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		DeviceCode:   r.PostForm.Get("device_code"),
+		Scope:        r.PostForm.Get("scope"),
+	}
+
+	if clientID, clientSecret, ok := r.BasicAuth(); ok {
+		tokenRequest.ClientID, _ = url.QueryUnescape(clientID)
+		tokenRequest.ClientSecret, _ = url.QueryUnescape(clientSecret)
+	}
+
+	tokenResponse, err := h.oauthUC.Exchange(tokenRequest)
+	if err != nil {
+		fmt.Println("oauth token err", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	writeJSON(w, http.StatusOK, tokenResponse)
+}
+
+func (h *OAuthHandler) DeviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		writeError(w, entity.ErrInvalidRequest)
+		return
+	}
+
+	deviceRequest := &dto.DeviceAuthorizationRequest{
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		Scope:        r.PostForm.Get("scope"),
+	}
+
+	if clientID, clientSecret, ok := r.BasicAuth(); ok {
+		deviceRequest.ClientID, _ = url.QueryUnescape(clientID)
+		deviceRequest.ClientSecret, _ = url.QueryUnescape(clientSecret)
+	}
+
+	deviceResponse, err := h.oauthUC.AuthorizeDevice(deviceRequest)
+	if err != nil {
+		fmt.Println("oauth device authorization err", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, deviceResponse)
+}
+
+func (h *OAuthHandler) DeviceVerification(w http.ResponseWriter, r *http.Request) {
+	verification, err := h.oauthUC.GetDeviceAuthorization(accessTokenFromRequest(r), r.URL.Query().Get("user_code"))
+	if err != nil {
+		writeDeviceError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, verification)
+}
+
+func (h *OAuthHandler) ApproveDevice(w http.ResponseWriter, r *http.Request) {
+	if !isJSONRequest(r) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		fmt.Println("device approval: not a json request")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	approvalRequest := &dto.DeviceApprovalRequest{}
+	err = json.Unmarshal(body, approvalRequest)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	err = h.oauthUC.ApproveDevice(accessTokenFromRequest(r), r.Header.Get("X-CSRF-Token"), approvalRequest)
+	if err != nil {
+		writeDeviceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *OAuthHandler) Consent(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	consent, err := h.oauthUC.GetConsent(accessTokenFromRequest(r), query.Get("client_id"), query.Get("scope"))
+	if err != nil {
+		writeConsentError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, consent)
+}
+
+func (h *OAuthHandler) DecideConsent(w http.ResponseWriter, r *http.Request) {
+	if !isJSONRequest(r) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		fmt.Println("consent: not a json request")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	consentRequest := &dto.ConsentRequest{}
+	err = json.Unmarshal(body, consentRequest)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	decision, err := h.oauthUC.DecideConsent(accessTokenFromRequest(r), r.Header.Get("X-CSRF-Token"), consentRequest)
+	if err != nil {
+		writeConsentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, decision)
+}
+
+func (h *OAuthHandler) Introspect(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		writeError(w, entity.ErrInvalidRequest)
+		return
+	}
+
+	introspectionRequest := &dto.IntrospectionRequest{
+		ClientID:      r.PostForm.Get("client_id"),
+		ClientSecret:  r.PostForm.Get("client_secret"),
+		Token:         r.PostForm.Get("token"),
+		TokenTypeHint: r.PostForm.Get("token_type_hint"),
+	}
+
+	if clientID, clientSecret, ok := r.BasicAuth(); ok {
+		introspectionRequest.ClientID, _ = url.QueryUnescape(clientID)
+		introspectionRequest.ClientSecret, _ = url.QueryUnescape(clientSecret)
+	}
+
+	introspection, err := h.oauthUC.Introspect(introspectionRequest)
+	if err != nil {
+		fmt.Println("oauth introspect err", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, introspection)
+}
+
+func (h *OAuthHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		writeError(w, entity.ErrInvalidRequest)
+		return
+	}
+
+	revocationRequest := &dto.RevocationRequest{
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		Token:        r.PostForm.Get("token"),
+	}
+
+	if clientID, clientSecret, ok := r.BasicAuth(); ok {
+		revocationRequest.ClientID, _ = url.QueryUnescape(clientID)
+		revocationRequest.ClientSecret, _ = url.QueryUnescape(clientSecret)
+	}
+
+	err = h.oauthUC.Revoke(revocationRequest)
+	if err != nil {
+		fmt.Println("oauth revoke err", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *OAuthHandler) UserInfo(w http.ResponseWriter, r *http.Request) {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		w.Header().Set("WWW-Authenticate", `Bearer`)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("Missing token")
+		return
+	}
+
+	userInfo, err := h.oauthUC.UserInfo(strings.TrimPrefix(authorization, "Bearer "))
+	if errors.Is(err, entity.ErrInsufficientScope) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Println("userinfo err", err)
+		return
+	}
+
+	if errors.Is(err, sessionEntity.ErrInvalidToken) || errors.Is(err, sessionEntity.ErrTokenRevoked) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("userinfo err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("userinfo err", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, userInfo)
+}
+
+func (h *OAuthHandler) Discovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	writeJSON(w, http.StatusOK, h.oauthUC.ProviderMetadata())
+}
+
+func accessTokenFromRequest(r *http.Request) string {
+	if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, "Bearer ") {
+		return strings.TrimPrefix(authorization, "Bearer ")
+	}
+
+	if accessCookie, err := r.Cookie("access_token"); err == nil {
+		return accessCookie.Value
+	}
+
+	return ""
+}
+
+// isJSONRequest rejects the content types a cross-site form can submit
+// without a CORS preflight.
+func isJSONRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+func writeConsentError(w http.ResponseWriter, err error) {
+	fmt.Println("oauth consent err", err)
+
+	switch {
+	case errors.Is(err, entity.ErrLoginRequired):
+		w.WriteHeader(http.StatusUnauthorized)
+	case errors.Is(err, entity.ErrInvalidCSRFToken):
+		w.WriteHeader(http.StatusForbidden)
+	case errors.Is(err, entity.ErrInvalidClient),
+		errors.Is(err, entity.ErrInvalidRedirectURI),
+		errors.Is(err, entity.ErrInvalidScope):
+		writeJSON(w, http.StatusBadRequest, &dto.ErrorResponse{
+			Error:            entity.ErrInvalidRequest.Error(),
+			ErrorDescription: err.Error(),
+		})
+	default:
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func writeDeviceError(w http.ResponseWriter, err error) {
+	fmt.Println("oauth device verification err", err)
+
+	switch {
+	case errors.Is(err, entity.ErrLoginRequired):
+		w.WriteHeader(http.StatusUnauthorized)
+	case errors.Is(err, entity.ErrInvalidCSRFToken):
+		w.WriteHeader(http.StatusForbidden)
+	case errors.Is(err, entity.ErrNoDeviceCode):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidClient):
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		writeJSON(w, http.StatusUnauthorized, &dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, entity.ErrInvalidRequest),
+		errors.Is(err, entity.ErrInvalidGrant),
+		errors.Is(err, entity.ErrUnauthorizedClient),
+		errors.Is(err, entity.ErrUnsupportedGrantType),
+		errors.Is(err, entity.ErrInvalidScope),
+		errors.Is(err, entity.ErrAuthorizationPending),
+		errors.Is(err, entity.ErrSlowDown),
+		errors.Is(err, entity.ErrExpiredToken),
+		errors.Is(err, entity.ErrAccessDenied):
+		writeJSON(w, http.StatusBadRequest, &dto.ErrorResponse{Error: err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, &dto.ErrorResponse{Error: "server_error"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("marshal err", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
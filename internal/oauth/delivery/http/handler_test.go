@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lightlink/auth-service/internal/oauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/oauth/usecase"
+)
+
+type fakeOAuthUsecase struct {
+	usecase.OAuthUsecaseI
+	redirectURL string
+	err         error
+}
+
+func (f *fakeOAuthUsecase) Authorize(accessToken string, authorizeRequest *dto.AuthorizeRequest) (string, error) {
+	return f.redirectURL, f.err
+}
+
+func TestAuthorizeRedirects(t *testing.T) {
+	tests := []struct {
+		name         string
+		redirectURL  string
+		err          error
+		wantStatus   int
+		wantLocation string
+	}{
+		{"code", "https://app.example.com/cb?code=abc", nil, http.StatusFound, "https://app.example.com/cb?code=abc"},
+		{"silent login required", "https://app.example.com/cb?error=login_required", entity.ErrLoginRequired, http.StatusFound, "https://app.example.com/cb?error=login_required"},
+		{"interactive login required", "", entity.ErrLoginRequired, http.StatusFound, "https://auth.example.com/login?return_to="},
+		{"silent consent required", "https://app.example.com/cb?error=consent_required", entity.ErrConsentRequired, http.StatusFound, "https://app.example.com/cb?error=consent_required"},
+		{"interactive consent required", "", entity.ErrConsentRequired, http.StatusFound, "https://auth.example.com/consent?"},
+		{"unknown client", "", entity.ErrInvalidClient, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewOAuthHandler(
+				&fakeOAuthUsecase{redirectURL: tt.redirectURL, err: tt.err},
+				"https://auth.example.com/login",
+				"https://auth.example.com/consent",
+			)
+
+			request := httptest.NewRequest(http.MethodGet, "/oauth/authorize?client_id=app&prompt=none", nil)
+			recorder := httptest.NewRecorder()
+
+			handler.Authorize(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("Authorize() status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if location := recorder.Header().Get("Location"); !strings.HasPrefix(location, tt.wantLocation) {
+				t.Errorf("Authorize() Location = %q, want prefix %q", location, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestApproveDeviceRequiresJSON(t *testing.T) {
+	handler := NewOAuthHandler(&fakeOAuthUsecase{}, "", "")
+
+	request := httptest.NewRequest(http.MethodPost, "/oauth/device", strings.NewReader("user_code=BCDF-GHJK&approve=true"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+
+	handler.ApproveDevice(recorder, request)
+
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("ApproveDevice() status = %d, want %d", recorder.Code, http.StatusUnsupportedMediaType)
+	}
+}
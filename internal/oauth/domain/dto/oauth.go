@@ -0,0 +1,222 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/oauth/domain/model"
+)
+
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
+	Prompt              string
+}
+
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	DeviceCode   string
+	Scope        string
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+type UserInfoResponse struct {
+	Subject           string `json:"sub"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+}
+
+type ProviderMetadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+type DeviceAuthorizationRequest struct {
+	ClientID     string
+	ClientSecret string
+	Scope        string
+}
+
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type DeviceVerificationResponse struct {
+	UserCode   string `json:"user_code"`
+	ClientID   string `json:"client_id"`
+	ClientName string `json:"client_name"`
+	Scope      string `json:"scope"`
+	CSRFToken  string `json:"csrf_token"`
+}
+
+type ConsentResponse struct {
+	ClientID   string `json:"client_id"`
+	ClientName string `json:"client_name"`
+	Scope      string `json:"scope"`
+	CSRFToken  string `json:"csrf_token"`
+}
+
+type ConsentRequest struct {
+	ClientID    string `json:"client_id"`
+	RedirectURI string `json:"redirect_uri"`
+	Scope       string `json:"scope"`
+	State       string `json:"state"`
+	Approve     bool   `json:"approve"`
+}
+
+type ConsentDecisionResponse struct {
+	RedirectTo string `json:"redirect_to,omitempty"`
+}
+
+type DeviceApprovalRequest struct {
+	UserCode string `json:"user_code"`
+	Approve  bool   `json:"approve"`
+}
+
+type IntrospectionRequest struct {
+	ClientID      string
+	ClientSecret  string
+	Token         string
+	TokenTypeHint string
+}
+
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Subject   string `json:"sub,omitempty"`
+	Username  string `json:"username,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+type RevocationRequest struct {
+	ClientID     string
+	ClientSecret string
+	Token        string
+}
+
+type ErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func ClientModelToEntity(clientModel *model.Client) *entity.Client {
+	return &entity.Client{
+		ID:           clientModel.ID,
+		Name:         clientModel.Name,
+		SecretHash:   clientModel.SecretHash,
+		RedirectURIs: clientModel.RedirectURIs,
+		Scopes:       clientModel.Scopes,
+		GrantTypes:   clientModel.GrantTypes,
+		TokenTTL:     time.Duration(clientModel.TokenTTL) * time.Second,
+		FirstParty:   clientModel.FirstParty,
+	}
+}
+
+func CodeEntityToModel(codeEntity *entity.AuthorizationCode) *model.AuthorizationCode {
+	return &model.AuthorizationCode{
+		ClientID:            codeEntity.ClientID,
+		UserID:              codeEntity.UserID,
+		Username:            codeEntity.Username,
+		RedirectURI:         codeEntity.RedirectURI,
+		RedirectURIProvided: codeEntity.RedirectURIProvided,
+		Scope:               codeEntity.Scope,
+		CodeChallenge:       codeEntity.CodeChallenge,
+		CodeChallengeMethod: codeEntity.CodeChallengeMethod,
+		Nonce:               codeEntity.Nonce,
+		AuthTime:            codeEntity.AuthTime,
+		AMR:                 codeEntity.AMR,
+		ExpiresAt:           codeEntity.ExpiresAt,
+	}
+}
+
+func CodeModelToEntity(code string, codeModel *model.AuthorizationCode) *entity.AuthorizationCode {
+	return &entity.AuthorizationCode{
+		Code:                code,
+		ClientID:            codeModel.ClientID,
+		UserID:              codeModel.UserID,
+		Username:            codeModel.Username,
+		RedirectURI:         codeModel.RedirectURI,
+		RedirectURIProvided: codeModel.RedirectURIProvided,
+		Scope:               codeModel.Scope,
+		CodeChallenge:       codeModel.CodeChallenge,
+		CodeChallengeMethod: codeModel.CodeChallengeMethod,
+		Nonce:               codeModel.Nonce,
+		AuthTime:            codeModel.AuthTime,
+		AMR:                 codeModel.AMR,
+		ExpiresAt:           codeModel.ExpiresAt,
+	}
+}
+
+func DeviceAuthorizationEntityToModel(deviceEntity *entity.DeviceAuthorization) *model.DeviceAuthorization {
+	return &model.DeviceAuthorization{
+		DeviceCode: deviceEntity.DeviceCode,
+		UserCode:   deviceEntity.UserCode,
+		ClientID:   deviceEntity.ClientID,
+		Scope:      deviceEntity.Scope,
+		Status:     deviceEntity.Status,
+		UserID:     deviceEntity.UserID,
+		Username:   deviceEntity.Username,
+		AuthTime:   deviceEntity.AuthTime,
+		AMR:        deviceEntity.AMR,
+		Interval:   int(deviceEntity.Interval.Seconds()),
+		ExpiresAt:  deviceEntity.ExpiresAt,
+	}
+}
+
+func DeviceAuthorizationModelToEntity(deviceModel *model.DeviceAuthorization) *entity.DeviceAuthorization {
+	return &entity.DeviceAuthorization{
+		DeviceCode: deviceModel.DeviceCode,
+		UserCode:   deviceModel.UserCode,
+		ClientID:   deviceModel.ClientID,
+		Scope:      deviceModel.Scope,
+		Status:     deviceModel.Status,
+		UserID:     deviceModel.UserID,
+		Username:   deviceModel.Username,
+		AuthTime:   deviceModel.AuthTime,
+		AMR:        deviceModel.AMR,
+		Interval:   time.Duration(deviceModel.Interval) * time.Second,
+		ExpiresAt:  deviceModel.ExpiresAt,
+	}
+}
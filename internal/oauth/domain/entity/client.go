@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+type Client struct {
+	ID           string
+	Name         string
+	SecretHash   string
+	RedirectURIs []string
+	Scopes       []string
+	GrantTypes   []string
+	TokenTTL     time.Duration
+	FirstParty   bool
+}
+
+func (c *Client) IsConfidential() bool {
+	return c.SecretHash != ""
+}
+
+func (c *Client) AllowsGrant(grantType string) bool {
+	for _, allowed := range c.GrantTypes {
+		if allowed == grantType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type AuthorizationCode struct {
+	Code                string
+	ClientID            string
+	UserID              uint
+	Username            string
+	RedirectURI         string
+	RedirectURIProvided bool
+	Scope               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
+	AuthTime            time.Time
+	AMR                 []string
+	ExpiresAt           time.Time
+}
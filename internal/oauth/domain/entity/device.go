@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+const (
+	DeviceStatusPending  = "pending"
+	DeviceStatusApproved = "approved"
+	DeviceStatusDenied   = "denied"
+)
+
+type DeviceAuthorization struct {
+	DeviceCode string
+	UserCode   string
+	ClientID   string
+	Scope      string
+	Status     string
+	UserID     uint
+	Username   string
+	AuthTime   time.Time
+	AMR        []string
+	Interval   time.Duration
+	ExpiresAt  time.Time
+}
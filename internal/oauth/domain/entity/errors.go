@@ -0,0 +1,27 @@
+package entity
+
+import "errors"
+
+// Error messages double as the RFC 6749 error codes returned to clients.
+var (
+	ErrInvalidRequest          = errors.New("invalid_request")
+	ErrInvalidClient           = errors.New("invalid_client")
+	ErrInvalidGrant            = errors.New("invalid_grant")
+	ErrUnauthorizedClient      = errors.New("unauthorized_client")
+	ErrUnsupportedGrantType    = errors.New("unsupported_grant_type")
+	ErrUnsupportedResponseType = errors.New("unsupported_response_type")
+	ErrInvalidScope            = errors.New("invalid_scope")
+	ErrLoginRequired           = errors.New("login_required")
+	ErrConsentRequired         = errors.New("consent_required")
+	ErrInsufficientScope       = errors.New("insufficient_scope")
+	ErrAuthorizationPending    = errors.New("authorization_pending")
+	ErrSlowDown                = errors.New("slow_down")
+	ErrExpiredToken            = errors.New("expired_token")
+	ErrAccessDenied            = errors.New("access_denied")
+
+	ErrInvalidRedirectURI = errors.New("redirect_uri is not registered for client")
+	ErrNoClient           = errors.New("couldn't find oauth client")
+	ErrNoCode             = errors.New("couldn't find authorization code")
+	ErrNoDeviceCode       = errors.New("couldn't find device authorization")
+	ErrInvalidCSRFToken   = errors.New("invalid csrf token")
+)
@@ -0,0 +1,12 @@
+package model
+
+type Client struct {
+	ID           string   `json:"client_id"`
+	Name         string   `json:"client_name"`
+	SecretHash   string   `json:"client_secret_hash"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes"`
+	GrantTypes   []string `json:"grant_types"`
+	TokenTTL     int      `json:"access_token_ttl"`
+	FirstParty   bool     `json:"first_party,omitempty"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type AuthorizationCode struct {
+	ClientID            string    `json:"client_id"`
+	UserID              uint      `json:"user_id"`
+	Username            string    `json:"username"`
+	RedirectURI         string    `json:"redirect_uri"`
+	RedirectURIProvided bool      `json:"redirect_uri_provided,omitempty"`
+	Scope               string    `json:"scope"`
+	CodeChallenge       string    `json:"code_challenge"`
+	CodeChallengeMethod string    `json:"code_challenge_method"`
+	Nonce               string    `json:"nonce,omitempty"`
+	AuthTime            time.Time `json:"auth_time"`
+	AMR                 []string  `json:"amr,omitempty"`
+	ExpiresAt           time.Time `json:"expires_at"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type DeviceAuthorization struct {
+	DeviceCode string    `json:"device_code"`
+	UserCode   string    `json:"user_code"`
+	ClientID   string    `json:"client_id"`
+	Scope      string    `json:"scope"`
+	Status     string    `json:"status"`
+	UserID     uint      `json:"user_id,omitempty"`
+	Username   string    `json:"username,omitempty"`
+	AuthTime   time.Time `json:"auth_time,omitempty"`
+	AMR        []string  `json:"amr,omitempty"`
+	Interval   int       `json:"interval"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
@@ -0,0 +1,50 @@
+package file
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/oauth/domain/model"
+)
+
+// ClientFileRepository serves the client registry from a JSON file loaded at
+// startup: a list of clients with their redirect URIs, scopes and grants.
+type ClientFileRepository struct {
+	clients map[string]*model.Client
+}
+
+func NewClientFileRepository(path string) (*ClientFileRepository, error) {
+	repo := &ClientFileRepository{
+		clients: map[string]*model.Client{},
+	}
+	if path == "" {
+		return repo, nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := []*model.Client{}
+	err = json.Unmarshal(contents, &clients)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, client := range clients {
+		repo.clients[client.ID] = client
+	}
+
+	return repo, nil
+}
+
+func (repo *ClientFileRepository) GetByID(clientID string) (*model.Client, error) {
+	client, ok := repo.clients[clientID]
+	if !ok {
+		return nil, entity.ErrNoClient
+	}
+
+	return client, nil
+}
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/oauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/oauth/domain/model"
+)
+
+type AuthorizationCodeRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewAuthorizationCodeRedisRepository(conn redis.Conn) *AuthorizationCodeRedisRepository {
+	return &AuthorizationCodeRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+func codeKey(code string) string {
+	return "oauth_codes:" + code
+}
+
+func (repo *AuthorizationCodeRedisRepository) Set(codeEntity *entity.AuthorizationCode, ttl time.Duration) error {
+	codeSerialized, err := json.Marshal(dto.CodeEntityToModel(codeEntity))
+	if err != nil {
+		return err
+	}
+
+	repo.mu.Lock()
+	_, err = repo.redisConn.Do("SET", codeKey(codeEntity.Code), codeSerialized, "EX", int(ttl.Seconds()))
+	repo.mu.Unlock()
+
+	return err
+}
+
+func (repo *AuthorizationCodeRedisRepository) Pop(code string) (*model.AuthorizationCode, error) {
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("GET", codeKey(code))
+	repo.redisConn.Send("DEL", codeKey(code))
+	result, err := redis.Values(repo.redisConn.Do("EXEC"))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := redis.Bytes(result[0], nil)
+	if err == redis.ErrNil {
+		return nil, entity.ErrNoCode
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	codeModel := &model.AuthorizationCode{}
+	err = json.Unmarshal(bytes, codeModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return codeModel, nil
+}
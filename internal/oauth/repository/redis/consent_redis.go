@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+type ConsentRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewConsentRedisRepository(conn redis.Conn) *ConsentRedisRepository {
+	return &ConsentRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+func consentKey(userID uint, clientID string) string {
+	return fmt.Sprintf("oauth_consents:%d:%s", userID, clientID)
+}
+
+// GetScopes returns the scopes the user has granted to the client so far.
+func (repo *ConsentRedisRepository) GetScopes(userID uint, clientID string) ([]string, error) {
+	repo.mu.Lock()
+	scopes, err := redis.Strings(repo.redisConn.Do("SMEMBERS", consentKey(userID, clientID)))
+	repo.mu.Unlock()
+
+	return scopes, err
+}
+
+// Grant adds scopes to the user's consent for the client. The whole grant
+// expires ttl after the last change, after which the user is asked again.
+func (repo *ConsentRedisRepository) Grant(userID uint, clientID string, scopes []string, ttl time.Duration) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	key := consentKey(userID, clientID)
+	args := redis.Args{}.Add(key).AddFlat(scopes)
+
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("SADD", args...)
+	repo.redisConn.Send("EXPIRE", key, int(ttl.Seconds()))
+	_, err := repo.redisConn.Do("EXEC")
+	repo.mu.Unlock()
+
+	return err
+}
@@ -0,0 +1,187 @@
+package redis
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/oauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/oauth/domain/model"
+)
+
+type DeviceAuthorizationRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewDeviceAuthorizationRedisRepository(conn redis.Conn) *DeviceAuthorizationRedisRepository {
+	return &DeviceAuthorizationRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+func deviceCodeKey(deviceCode string) string {
+	return "oauth_device_codes:" + deviceCode
+}
+
+func userCodeKey(userCode string) string {
+	return "oauth_user_codes:" + userCode
+}
+
+// Poll state lives under its own key so that a pending poll never rewrites
+// the authorization and can't race with the user's decision.
+func devicePollKey(deviceCode string) string {
+	return "oauth_device_polls:" + deviceCode
+}
+
+// The decision is only stored while the authorization is still pending, so
+// that the first of several concurrent decisions wins.
+var decideScript = redis.NewScript(1, `
+local current = redis.call('GET', KEYS[1])
+if not current or cjson.decode(current).status ~= ARGV[3] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'EX', ARGV[2])
+return 1
+`)
+
+// Polling faster than the current interval adds the penalty to it and
+// reports slow_down (RFC 8628, section 3.5).
+var pollScript = redis.NewScript(1, `
+local state = redis.call('HMGET', KEYS[1], 'last_polled_at', 'interval')
+local now = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+if state[2] then
+	interval = tonumber(state[2])
+end
+local slowDown = 0
+if state[1] and now - tonumber(state[1]) < interval then
+	interval = interval + tonumber(ARGV[3])
+	slowDown = 1
+end
+redis.call('HSET', KEYS[1], 'last_polled_at', now, 'interval', interval)
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return slowDown
+`)
+
+func (repo *DeviceAuthorizationRedisRepository) Set(deviceEntity *entity.DeviceAuthorization) error {
+	deviceSerialized, err := json.Marshal(dto.DeviceAuthorizationEntityToModel(deviceEntity))
+	if err != nil {
+		return err
+	}
+
+	ttl := int(time.Until(deviceEntity.ExpiresAt).Seconds())
+	if ttl <= 0 {
+		return entity.ErrExpiredToken
+	}
+
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("SET", deviceCodeKey(deviceEntity.DeviceCode), deviceSerialized, "EX", ttl)
+	repo.redisConn.Send("SET", userCodeKey(deviceEntity.UserCode), deviceEntity.DeviceCode, "EX", ttl)
+	_, err = repo.redisConn.Do("EXEC")
+	repo.mu.Unlock()
+
+	return err
+}
+
+// Decide stores the user's decision and reports whether the authorization was
+// still pending.
+func (repo *DeviceAuthorizationRedisRepository) Decide(deviceEntity *entity.DeviceAuthorization) (bool, error) {
+	deviceSerialized, err := json.Marshal(dto.DeviceAuthorizationEntityToModel(deviceEntity))
+	if err != nil {
+		return false, err
+	}
+
+	ttl := int(time.Until(deviceEntity.ExpiresAt).Seconds())
+	if ttl <= 0 {
+		return false, entity.ErrExpiredToken
+	}
+
+	repo.mu.Lock()
+	decided, err := redis.Int(decideScript.Do(repo.redisConn, deviceCodeKey(deviceEntity.DeviceCode), deviceSerialized, ttl, entity.DeviceStatusPending))
+	repo.mu.Unlock()
+
+	return decided == 1, err
+}
+
+// Poll records a poll of a pending authorization and reports whether the
+// client has to slow down.
+func (repo *DeviceAuthorizationRedisRepository) Poll(deviceEntity *entity.DeviceAuthorization, now time.Time, slowDownPenalty time.Duration) (bool, error) {
+	ttl := time.Until(deviceEntity.ExpiresAt)
+	if ttl <= 0 {
+		return false, entity.ErrExpiredToken
+	}
+
+	repo.mu.Lock()
+	slowDown, err := redis.Int(pollScript.Do(
+		repo.redisConn,
+		devicePollKey(deviceEntity.DeviceCode),
+		now.UnixMilli(),
+		deviceEntity.Interval.Milliseconds(),
+		slowDownPenalty.Milliseconds(),
+		ttl.Milliseconds(),
+	))
+	repo.mu.Unlock()
+
+	return slowDown == 1, err
+}
+
+func (repo *DeviceAuthorizationRedisRepository) GetByDeviceCode(deviceCode string) (*model.DeviceAuthorization, error) {
+	repo.mu.Lock()
+	bytes, err := redis.Bytes(repo.redisConn.Do("GET", deviceCodeKey(deviceCode)))
+	repo.mu.Unlock()
+
+	if err == redis.ErrNil {
+		return nil, entity.ErrNoDeviceCode
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	deviceModel := &model.DeviceAuthorization{}
+	err = json.Unmarshal(bytes, deviceModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return deviceModel, nil
+}
+
+func (repo *DeviceAuthorizationRedisRepository) GetByUserCode(userCode string) (*model.DeviceAuthorization, error) {
+	repo.mu.Lock()
+	deviceCode, err := redis.String(repo.redisConn.Do("GET", userCodeKey(userCode)))
+	repo.mu.Unlock()
+
+	if err == redis.ErrNil {
+		return nil, entity.ErrNoDeviceCode
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByDeviceCode(deviceCode)
+}
+
+// Delete reports whether this call removed the authorization, so that only one
+// of several concurrent polls can redeem an approved device code.
+func (repo *DeviceAuthorizationRedisRepository) Delete(deviceEntity *entity.DeviceAuthorization) (bool, error) {
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("DEL", deviceCodeKey(deviceEntity.DeviceCode))
+	repo.redisConn.Send("DEL", userCodeKey(deviceEntity.UserCode))
+	repo.redisConn.Send("DEL", devicePollKey(deviceEntity.DeviceCode))
+	result, err := redis.Ints(repo.redisConn.Do("EXEC"))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return false, err
+	}
+
+	return result[0] == 1, nil
+}
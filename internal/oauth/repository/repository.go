@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/oauth/domain/model"
+)
+
+type ClientRepositoryI interface {
+	GetByID(clientID string) (*model.Client, error)
+}
+
+type AuthorizationCodeRepositoryI interface {
+	Set(codeEntity *entity.AuthorizationCode, ttl time.Duration) error
+	Pop(code string) (*model.AuthorizationCode, error)
+}
+
+type ConsentRepositoryI interface {
+	GetScopes(userID uint, clientID string) ([]string, error)
+	Grant(userID uint, clientID string, scopes []string, ttl time.Duration) error
+}
+
+type DeviceAuthorizationRepositoryI interface {
+	Set(deviceEntity *entity.DeviceAuthorization) error
+	Decide(deviceEntity *entity.DeviceAuthorization) (bool, error)
+	Poll(deviceEntity *entity.DeviceAuthorization, now time.Time, slowDownPenalty time.Duration) (bool, error)
+	GetByDeviceCode(deviceCode string) (*model.DeviceAuthorization, error)
+	GetByUserCode(userCode string) (*model.DeviceAuthorization, error)
+	Delete(deviceEntity *entity.DeviceAuthorization) (bool, error)
+}
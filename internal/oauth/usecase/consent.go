@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lightlink/auth-service/internal/oauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+)
+
+const consentTTL = 180 * 24 * time.Hour
+
+func (uc *OAuthUsecase) GetConsent(accessToken string, clientID string, scope string) (*dto.ConsentResponse, error) {
+	identity, err := uc.loggedInIdentity(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := uc.getClient(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err = resolveScope(client, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	csrfToken, err := uc.issueCSRFToken(identity.SessionID, csrfPurposeConsent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ConsentResponse{
+		ClientID:   client.ID,
+		ClientName: client.Name,
+		Scope:      scope,
+		CSRFToken:  csrfToken,
+	}, nil
+}
+
+// DecideConsent records an approval so that the authorize request can be
+// retried, or returns the access_denied redirect for the client on refusal.
+func (uc *OAuthUsecase) DecideConsent(accessToken string, csrfToken string, consentRequest *dto.ConsentRequest) (*dto.ConsentDecisionResponse, error) {
+	identity, err := uc.loggedInIdentity(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.verifyCSRFToken(csrfToken, identity.SessionID, csrfPurposeConsent)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := uc.getClient(consentRequest.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURI, err := resolveRedirectURI(client, consentRequest.RedirectURI)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := resolveScope(client, consentRequest.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if !consentRequest.Approve {
+		return &dto.ConsentDecisionResponse{
+			RedirectTo: errorRedirect(redirectURI, entity.ErrAccessDenied, consentRequest.State),
+		}, nil
+	}
+
+	err = uc.consentRepo.Grant(identity.UserID, client.ID, strings.Fields(scope), consentTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ConsentDecisionResponse{}, nil
+}
+
+// hasConsent reports whether the user has already granted every scope of the
+// request to the client. First-party clients never ask.
+func (uc *OAuthUsecase) hasConsent(userID uint, client *entity.Client, scope string) (bool, error) {
+	if client.FirstParty {
+		return true, nil
+	}
+
+	granted, err := uc.consentRepo.GetScopes(userID, client.ID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, wanted := range strings.Fields(scope) {
+		if !contains(granted, wanted) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+)
+
+const (
+	tokenTypeCSRF = "csrf"
+	csrfTokenTTL  = 10 * time.Minute
+
+	csrfPurposeConsent = "consent"
+	csrfPurposeDevice  = "device"
+)
+
+// issueCSRFToken binds a form to the session that loaded it. The session
+// cookies carry no SameSite attribute, so state-changing endpoints require
+// this token back alongside them.
+func (uc *OAuthUsecase) issueCSRFToken(sessionID string, purpose string) (string, error) {
+	return uc.tokenSigner.Sign(jwt.MapClaims{
+		"typ":     tokenTypeCSRF,
+		"sid":     sessionID,
+		"purpose": purpose,
+		"exp":     time.Now().Add(csrfTokenTTL).Unix(),
+	})
+}
+
+func (uc *OAuthUsecase) verifyCSRFToken(tokenString string, sessionID string, purpose string) error {
+	if tokenString == "" || sessionID == "" {
+		return entity.ErrInvalidCSRFToken
+	}
+
+	token, err := jwt.Parse(tokenString, uc.tokenSigner.Keyfunc)
+	if err != nil || !token.Valid {
+		return entity.ErrInvalidCSRFToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok ||
+		claims["typ"] != tokenTypeCSRF ||
+		claims["sid"] != sessionID ||
+		claims["purpose"] != purpose {
+		return entity.ErrInvalidCSRFToken
+	}
+
+	return nil
+}
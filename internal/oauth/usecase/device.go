@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lightlink/auth-service/internal/oauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+)
+
+const (
+	deviceCodeTTL         = 10 * time.Minute
+	devicePollInterval    = 5 * time.Second
+	deviceSlowDownPenalty = 5 * time.Second
+
+	// Consonants only, so user codes can't spell words and survive being
+	// read aloud or typed on a remote control (RFC 8628, section 6.1).
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+)
+
+func (uc *OAuthUsecase) AuthorizeDevice(deviceRequest *dto.DeviceAuthorizationRequest) (*dto.DeviceAuthorizationResponse, error) {
+	client, err := uc.authenticateClient(deviceRequest.ClientID, deviceRequest.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.AllowsGrant(grantTypeDeviceCode) {
+		return nil, entity.ErrUnauthorizedClient
+	}
+
+	scope, err := resolveScope(client, deviceRequest.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceCode, err := generateCode()
+	if err != nil {
+		return nil, err
+	}
+
+	userCode, err := generateUserCode()
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.deviceRepo.Set(&entity.DeviceAuthorization{
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		ClientID:   client.ID,
+		Scope:      scope,
+		Status:     entity.DeviceStatusPending,
+		Interval:   devicePollInterval,
+		ExpiresAt:  time.Now().Add(deviceCodeTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("user_code", userCode)
+
+	return &dto.DeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                formatUserCode(userCode),
+		VerificationURI:         uc.verificationURI,
+		VerificationURIComplete: appendQuery(uc.verificationURI, params),
+		ExpiresIn:               int(deviceCodeTTL.Seconds()),
+		Interval:                int(devicePollInterval.Seconds()),
+	}, nil
+}
+
+func (uc *OAuthUsecase) GetDeviceAuthorization(accessToken string, userCode string) (*dto.DeviceVerificationResponse, error) {
+	identity, err := uc.loggedInIdentity(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceEntity, err := uc.pendingDeviceAuthorization(userCode)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := uc.getClient(deviceEntity.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	csrfToken, err := uc.issueCSRFToken(identity.SessionID, csrfPurposeDevice)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DeviceVerificationResponse{
+		UserCode:   formatUserCode(deviceEntity.UserCode),
+		ClientID:   client.ID,
+		ClientName: client.Name,
+		Scope:      deviceEntity.Scope,
+		CSRFToken:  csrfToken,
+	}, nil
+}
+
+func (uc *OAuthUsecase) ApproveDevice(accessToken string, csrfToken string, approvalRequest *dto.DeviceApprovalRequest) error {
+	identity, err := uc.loggedInIdentity(accessToken)
+	if err != nil {
+		return err
+	}
+
+	err = uc.verifyCSRFToken(csrfToken, identity.SessionID, csrfPurposeDevice)
+	if err != nil {
+		return err
+	}
+
+	deviceEntity, err := uc.pendingDeviceAuthorization(approvalRequest.UserCode)
+	if err != nil {
+		return err
+	}
+
+	if !approvalRequest.Approve {
+		deviceEntity.Status = entity.DeviceStatusDenied
+		return uc.decideDevice(deviceEntity)
+	}
+
+	authTime := identity.AuthTime
+	if authTime.IsZero() {
+		authTime = time.Now()
+	}
+
+	deviceEntity.Status = entity.DeviceStatusApproved
+	deviceEntity.UserID = identity.UserID
+	deviceEntity.Username = identity.Username
+	deviceEntity.AuthTime = authTime
+	deviceEntity.AMR = identity.AMR
+
+	return uc.decideDevice(deviceEntity)
+}
+
+func (uc *OAuthUsecase) decideDevice(deviceEntity *entity.DeviceAuthorization) error {
+	decided, err := uc.deviceRepo.Decide(deviceEntity)
+	if errors.Is(err, entity.ErrExpiredToken) {
+		return entity.ErrNoDeviceCode
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !decided {
+		return entity.ErrNoDeviceCode
+	}
+
+	return nil
+}
+
+func (uc *OAuthUsecase) exchangeDeviceCode(client *entity.Client, tokenRequest *dto.TokenRequest) (*sessionEntity.Session, error) {
+	if tokenRequest.DeviceCode == "" {
+		return nil, entity.ErrInvalidRequest
+	}
+
+	deviceModel, err := uc.deviceRepo.GetByDeviceCode(tokenRequest.DeviceCode)
+	if errors.Is(err, entity.ErrNoDeviceCode) {
+		// Device codes are dropped from Redis once they expire.
+		return nil, entity.ErrExpiredToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	deviceEntity := dto.DeviceAuthorizationModelToEntity(deviceModel)
+	if deviceEntity.ClientID != client.ID {
+		return nil, entity.ErrInvalidGrant
+	}
+
+	now := time.Now()
+	if now.After(deviceEntity.ExpiresAt) {
+		return nil, entity.ErrExpiredToken
+	}
+
+	switch deviceEntity.Status {
+	case entity.DeviceStatusPending:
+		slowDown, err := uc.deviceRepo.Poll(deviceEntity, now, deviceSlowDownPenalty)
+		if err != nil {
+			return nil, err
+		}
+
+		if slowDown {
+			return nil, entity.ErrSlowDown
+		}
+
+		return nil, entity.ErrAuthorizationPending
+	case entity.DeviceStatusDenied:
+		_, err = uc.deviceRepo.Delete(deviceEntity)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, entity.ErrAccessDenied
+	}
+
+	deleted, err := uc.deviceRepo.Delete(deviceEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	if !deleted {
+		return nil, entity.ErrInvalidGrant
+	}
+
+	return uc.sessionUC.CreateDelegatedSession(deviceEntity.Username, deviceEntity.UserID, &sessionEntity.AuthContext{
+		ClientID: client.ID,
+		Scope:    deviceEntity.Scope,
+		AuthTime: deviceEntity.AuthTime,
+		AMR:      deviceEntity.AMR,
+	})
+}
+
+func (uc *OAuthUsecase) loggedInIdentity(accessToken string) (*sessionEntity.Identity, error) {
+	identity, err := uc.sessionUC.ValidateAccessToken(accessToken)
+	if err != nil || identity.ClientID != "" || identity.Guest || identity.Restricted {
+		return nil, entity.ErrLoginRequired
+	}
+
+	return identity, nil
+}
+
+func (uc *OAuthUsecase) pendingDeviceAuthorization(userCode string) (*entity.DeviceAuthorization, error) {
+	deviceModel, err := uc.deviceRepo.GetByUserCode(normalizeUserCode(userCode))
+	if err != nil {
+		return nil, err
+	}
+
+	deviceEntity := dto.DeviceAuthorizationModelToEntity(deviceModel)
+	if deviceEntity.Status != entity.DeviceStatusPending {
+		return nil, entity.ErrNoDeviceCode
+	}
+
+	return deviceEntity, nil
+}
+
+func generateUserCode() (string, error) {
+	code := make([]byte, 0, userCodeLength)
+	buf := make([]byte, 1)
+	for len(code) < userCodeLength {
+		_, err := rand.Read(buf)
+		if err != nil {
+			return "", err
+		}
+
+		// Reject the tail of the byte range to keep the distribution uniform.
+		if int(buf[0]) >= 256-256%len(userCodeAlphabet) {
+			continue
+		}
+		code = append(code, userCodeAlphabet[int(buf[0])%len(userCodeAlphabet)])
+	}
+
+	return string(code), nil
+}
+
+func formatUserCode(userCode string) string {
+	if len(userCode) != userCodeLength {
+		return userCode
+	}
+
+	return userCode[:userCodeLength/2] + "-" + userCode[userCodeLength/2:]
+}
+
+func normalizeUserCode(userCode string) string {
+	normalized := strings.Builder{}
+	for _, char := range strings.ToUpper(userCode) {
+		if strings.ContainsRune(userCodeAlphabet, char) {
+			normalized.WriteRune(char)
+		}
+	}
+
+	return normalized.String()
+}
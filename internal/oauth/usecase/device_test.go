@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lightlink/auth-service/internal/oauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+)
+
+// startDevice begins a device authorization and returns it as stored.
+func (env *testEnv) startDevice(t *testing.T) *entity.DeviceAuthorization {
+	t.Helper()
+
+	deviceResponse, err := env.uc.AuthorizeDevice(&dto.DeviceAuthorizationRequest{ClientID: "first-party", Scope: scopeOpenID})
+	if err != nil {
+		t.Fatalf("AuthorizeDevice: %v", err)
+	}
+
+	deviceModel, err := env.uc.deviceRepo.GetByDeviceCode(deviceResponse.DeviceCode)
+	if err != nil {
+		t.Fatalf("GetByDeviceCode: %v", err)
+	}
+
+	return dto.DeviceAuthorizationModelToEntity(deviceModel)
+}
+
+func (env *testEnv) pollDevice(deviceCode string) (*dto.TokenResponse, error) {
+	return env.uc.Exchange(&dto.TokenRequest{
+		GrantType:  grantTypeDeviceCode,
+		ClientID:   "first-party",
+		DeviceCode: deviceCode,
+	})
+}
+
+func (env *testEnv) decideDevice(t *testing.T, userCode string, approve bool) error {
+	t.Helper()
+
+	verification, err := env.uc.GetDeviceAuthorization("alice-access", userCode)
+	if err != nil {
+		return err
+	}
+
+	return env.uc.ApproveDevice("alice-access", verification.CSRFToken, &dto.DeviceApprovalRequest{
+		UserCode: userCode,
+		Approve:  approve,
+	})
+}
+
+func TestDevicePollInterval(t *testing.T) {
+	env := newTestEnv(t)
+	deviceEntity := env.startDevice(t)
+	start := time.Now()
+
+	polls := []struct {
+		name         string
+		after        time.Duration
+		wantSlowDown bool
+	}{
+		{"first poll", 0, false},
+		{"too fast", time.Second, true},
+		{"still inside the grown interval", 8 * time.Second, true},
+		{"after the grown interval", 30 * time.Second, false},
+		{"right after that", 31 * time.Second, true},
+	}
+
+	for _, poll := range polls {
+		slowDown, err := env.uc.deviceRepo.Poll(deviceEntity, start.Add(poll.after), deviceSlowDownPenalty)
+		if err != nil {
+			t.Fatalf("%s: Poll: %v", poll.name, err)
+		}
+
+		if slowDown != poll.wantSlowDown {
+			t.Errorf("%s: slow down = %v, want %v", poll.name, slowDown, poll.wantSlowDown)
+		}
+	}
+}
+
+func TestDevicePolling(t *testing.T) {
+	env := newTestEnv(t)
+	deviceEntity := env.startDevice(t)
+
+	_, err := env.pollDevice(deviceEntity.DeviceCode)
+	if !errors.Is(err, entity.ErrAuthorizationPending) {
+		t.Fatalf("first poll err = %v, want %v", err, entity.ErrAuthorizationPending)
+	}
+
+	_, err = env.pollDevice(deviceEntity.DeviceCode)
+	if !errors.Is(err, entity.ErrSlowDown) {
+		t.Fatalf("second poll err = %v, want %v", err, entity.ErrSlowDown)
+	}
+
+	err = env.decideDevice(t, formatUserCode(deviceEntity.UserCode), true)
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+
+	tokenResponse, err := env.pollDevice(deviceEntity.DeviceCode)
+	if err != nil {
+		t.Fatalf("poll after approval: %v", err)
+	}
+
+	if tokenResponse.AccessToken == "" {
+		t.Errorf("poll after approval returned no access token")
+	}
+
+	_, err = env.pollDevice(deviceEntity.DeviceCode)
+	if !errors.Is(err, entity.ErrExpiredToken) {
+		t.Fatalf("poll after redemption err = %v, want %v", err, entity.ErrExpiredToken)
+	}
+}
+
+// A poll that read the authorization before the user approved it must not
+// overwrite the approval.
+func TestDevicePollKeepsApproval(t *testing.T) {
+	env := newTestEnv(t)
+	stale := env.startDevice(t)
+
+	err := env.decideDevice(t, stale.UserCode, true)
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+
+	_, err = env.uc.deviceRepo.Poll(stale, time.Now(), deviceSlowDownPenalty)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	deviceModel, err := env.uc.deviceRepo.GetByDeviceCode(stale.DeviceCode)
+	if err != nil {
+		t.Fatalf("GetByDeviceCode: %v", err)
+	}
+
+	if deviceModel.Status != entity.DeviceStatusApproved || deviceModel.UserID != 1 {
+		t.Fatalf("status = %q, user = %d after poll, want approved for user 1", deviceModel.Status, deviceModel.UserID)
+	}
+}
+
+func TestDeviceDecisionIsFinal(t *testing.T) {
+	env := newTestEnv(t)
+	stale := env.startDevice(t)
+
+	err := env.decideDevice(t, stale.UserCode, false)
+	if err != nil {
+		t.Fatalf("deny: %v", err)
+	}
+
+	stale.Status = entity.DeviceStatusApproved
+	stale.UserID = 1
+	decided, err := env.uc.deviceRepo.Decide(stale)
+	if err != nil {
+		t.Fatalf("Decide: %v", err)
+	}
+
+	if decided {
+		t.Fatalf("Decide overwrote a denial")
+	}
+
+	_, err = env.pollDevice(stale.DeviceCode)
+	if !errors.Is(err, entity.ErrAccessDenied) {
+		t.Fatalf("poll after denial err = %v, want %v", err, entity.ErrAccessDenied)
+	}
+}
+
+func TestApproveDeviceRequiresCSRFToken(t *testing.T) {
+	env := newTestEnv(t)
+	deviceEntity := env.startDevice(t)
+
+	consentCSRF, err := env.uc.issueCSRFToken("alice-session", csrfPurposeConsent)
+	if err != nil {
+		t.Fatalf("issueCSRFToken: %v", err)
+	}
+
+	for _, csrfToken := range []string{"", "garbage", consentCSRF} {
+		err = env.uc.ApproveDevice("alice-access", csrfToken, &dto.DeviceApprovalRequest{
+			UserCode: deviceEntity.UserCode,
+			Approve:  true,
+		})
+		if !errors.Is(err, entity.ErrInvalidCSRFToken) {
+			t.Fatalf("ApproveDevice(%q) err = %v, want %v", csrfToken, err, entity.ErrInvalidCSRFToken)
+		}
+	}
+}
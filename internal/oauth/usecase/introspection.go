@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"strconv"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/oauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+)
+
+const (
+	tokenTypeHintAccessToken  = "access_token"
+	tokenTypeHintRefreshToken = "refresh_token"
+)
+
+func (uc *OAuthUsecase) Introspect(introspectionRequest *dto.IntrospectionRequest) (*dto.IntrospectionResponse, error) {
+	client, err := uc.authenticateClient(introspectionRequest.ClientID, introspectionRequest.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.IsConfidential() {
+		return nil, entity.ErrInvalidClient
+	}
+
+	if introspectionRequest.Token == "" {
+		return nil, entity.ErrInvalidRequest
+	}
+
+	validators := []func(string) (*sessionEntity.Identity, string, error){
+		uc.introspectAccessToken,
+		uc.introspectRefreshToken,
+	}
+	if introspectionRequest.TokenTypeHint == tokenTypeHintRefreshToken {
+		validators[0], validators[1] = validators[1], validators[0]
+	}
+
+	for _, validate := range validators {
+		identity, tokenType, err := validate(introspectionRequest.Token)
+		if err != nil {
+			continue
+		}
+
+		return identityToIntrospection(identity, tokenType), nil
+	}
+
+	return &dto.IntrospectionResponse{
+		Active: false,
+	}, nil
+}
+
+func (uc *OAuthUsecase) Revoke(revocationRequest *dto.RevocationRequest) error {
+	client, err := uc.authenticateClient(revocationRequest.ClientID, revocationRequest.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	if revocationRequest.Token == "" {
+		return entity.ErrInvalidRequest
+	}
+
+	// Invalid and expired tokens need no revocation and must not be reported
+	// as errors (RFC 7009, section 2.2).
+	token, err := uc.sessionUC.ParseToken(revocationRequest.Token)
+	if err != nil || !token.Valid {
+		return nil
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil
+	}
+
+	if claims["client_id"] != client.ID {
+		return entity.ErrUnauthorizedClient
+	}
+
+	return uc.sessionUC.RevokeToken(token)
+}
+
+// introspectAccessToken combines JWT validation with a session store lookup,
+// so tokens of a session that was logged out report as inactive right away.
+func (uc *OAuthUsecase) introspectAccessToken(tokenString string) (*sessionEntity.Identity, string, error) {
+	identity, err := uc.sessionUC.ValidateAccessToken(tokenString)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !identity.Service && !identity.Guest {
+		_, err = uc.sessionUC.GetSession(identity.UserID, identity.SessionID)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	return identity, tokenTypeHintAccessToken, nil
+}
+
+func (uc *OAuthUsecase) introspectRefreshToken(tokenString string) (*sessionEntity.Identity, string, error) {
+	identity, err := uc.sessionUC.ValidateRefreshToken(tokenString)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return identity, tokenTypeHintRefreshToken, nil
+}
+
+func identityToIntrospection(identity *sessionEntity.Identity, tokenType string) *dto.IntrospectionResponse {
+	introspection := &dto.IntrospectionResponse{
+		Active:    true,
+		Subject:   strconv.Itoa(int(identity.UserID)),
+		Username:  identity.Username,
+		ClientID:  identity.ClientID,
+		Scope:     identity.Scope,
+		SessionID: identity.SessionID,
+		TokenType: tokenType,
+		ExpiresAt: identity.ExpiresAt.Unix(),
+	}
+	if identity.Service {
+		introspection.Subject = identity.ClientID
+	}
+	if identity.Guest {
+		introspection.Subject = "guest:" + identity.GuestID
+	}
+
+	return introspection
+}
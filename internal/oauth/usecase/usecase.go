@@ -0,0 +1,532 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/oauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+	oauthRepo "github.com/lightlink/auth-service/internal/oauth/repository"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	"github.com/lightlink/auth-service/internal/token/signer"
+	userRepo "github.com/lightlink/auth-service/internal/user/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	codeTTL = 60 * time.Second
+
+	defaultServiceTokenTTL = 15 * time.Minute
+
+	grantTypeAuthorizationCode = "authorization_code"
+	grantTypeRefreshToken      = "refresh_token"
+	grantTypeClientCredentials = "client_credentials"
+	grantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+
+	codeChallengeMethodS256 = "S256"
+
+	scopeOpenID  = "openid"
+	scopeProfile = "profile"
+
+	promptNone = "none"
+)
+
+type OAuthUsecaseI interface {
+	Authorize(accessToken string, authorizeRequest *dto.AuthorizeRequest) (string, error)
+	Exchange(tokenRequest *dto.TokenRequest) (*dto.TokenResponse, error)
+	UserInfo(accessToken string) (*dto.UserInfoResponse, error)
+	ProviderMetadata() *dto.ProviderMetadata
+	AuthorizeDevice(deviceRequest *dto.DeviceAuthorizationRequest) (*dto.DeviceAuthorizationResponse, error)
+	GetDeviceAuthorization(accessToken string, userCode string) (*dto.DeviceVerificationResponse, error)
+	ApproveDevice(accessToken string, csrfToken string, approvalRequest *dto.DeviceApprovalRequest) error
+	GetConsent(accessToken string, clientID string, scope string) (*dto.ConsentResponse, error)
+	DecideConsent(accessToken string, csrfToken string, consentRequest *dto.ConsentRequest) (*dto.ConsentDecisionResponse, error)
+	Introspect(introspectionRequest *dto.IntrospectionRequest) (*dto.IntrospectionResponse, error)
+	Revoke(revocationRequest *dto.RevocationRequest) error
+}
+
+type OAuthUsecase struct {
+	clientRepo      oauthRepo.ClientRepositoryI
+	codeRepo        oauthRepo.AuthorizationCodeRepositoryI
+	consentRepo     oauthRepo.ConsentRepositoryI
+	deviceRepo      oauthRepo.DeviceAuthorizationRepositoryI
+	userRepo        userRepo.UserRepositoryI
+	sessionUC       sessionUsecase.SessionUsecaseI
+	tokenSigner     signer.Signer
+	issuer          string
+	verificationURI string
+}
+
+func NewOAuthUsecase(
+	clientRepository oauthRepo.ClientRepositoryI,
+	codeRepository oauthRepo.AuthorizationCodeRepositoryI,
+	consentRepository oauthRepo.ConsentRepositoryI,
+	deviceRepository oauthRepo.DeviceAuthorizationRepositoryI,
+	userRepository userRepo.UserRepositoryI,
+	sessionUsecase sessionUsecase.SessionUsecaseI,
+	tokenSigner signer.Signer,
+	issuer string,
+	verificationURI string,
+) *OAuthUsecase {
+	issuer = strings.TrimSuffix(issuer, "/")
+	if verificationURI == "" {
+		verificationURI = issuer + "/oauth/device"
+	}
+
+	return &OAuthUsecase{
+		clientRepo:      clientRepository,
+		codeRepo:        codeRepository,
+		consentRepo:     consentRepository,
+		deviceRepo:      deviceRepository,
+		userRepo:        userRepository,
+		sessionUC:       sessionUsecase,
+		tokenSigner:     tokenSigner,
+		issuer:          issuer,
+		verificationURI: verificationURI,
+	}
+}
+
+// Authorize returns the URL the user agent should be sent back to. Errors that
+// happen before the redirect URI is trusted are returned with an empty URL and
+// must be shown to the user instead.
+func (uc *OAuthUsecase) Authorize(accessToken string, authorizeRequest *dto.AuthorizeRequest) (string, error) {
+	client, err := uc.getClient(authorizeRequest.ClientID)
+	if err != nil {
+		return "", err
+	}
+
+	redirectURI, err := resolveRedirectURI(client, authorizeRequest.RedirectURI)
+	if err != nil {
+		return "", err
+	}
+
+	if authorizeRequest.ResponseType != "code" {
+		return errorRedirect(redirectURI, entity.ErrUnsupportedResponseType, authorizeRequest.State), entity.ErrUnsupportedResponseType
+	}
+
+	if !client.AllowsGrant(grantTypeAuthorizationCode) {
+		return errorRedirect(redirectURI, entity.ErrUnauthorizedClient, authorizeRequest.State), entity.ErrUnauthorizedClient
+	}
+
+	if authorizeRequest.CodeChallenge == "" || authorizeRequest.CodeChallengeMethod != codeChallengeMethodS256 {
+		return errorRedirect(redirectURI, entity.ErrInvalidRequest, authorizeRequest.State), entity.ErrInvalidRequest
+	}
+
+	scope, err := resolveScope(client, authorizeRequest.Scope)
+	if err != nil {
+		return errorRedirect(redirectURI, err, authorizeRequest.State), err
+	}
+
+	identity, err := uc.sessionUC.ValidateAccessToken(accessToken)
+	if err != nil || identity.ClientID != "" || identity.Guest || identity.Restricted {
+		if authorizeRequest.Prompt == promptNone {
+			return errorRedirect(redirectURI, entity.ErrLoginRequired, authorizeRequest.State), entity.ErrLoginRequired
+		}
+		return "", entity.ErrLoginRequired
+	}
+
+	consented, err := uc.hasConsent(identity.UserID, client, scope)
+	if err != nil {
+		return "", err
+	}
+
+	if !consented {
+		if authorizeRequest.Prompt == promptNone {
+			return errorRedirect(redirectURI, entity.ErrConsentRequired, authorizeRequest.State), entity.ErrConsentRequired
+		}
+		return "", entity.ErrConsentRequired
+	}
+
+	authTime := identity.AuthTime
+	if authTime.IsZero() {
+		authTime = time.Now()
+	}
+
+	code, err := generateCode()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = uc.codeRepo.Set(&entity.AuthorizationCode{
+		Code:                code,
+		ClientID:            client.ID,
+		UserID:              identity.UserID,
+		Username:            identity.Username,
+		RedirectURI:         redirectURI,
+		RedirectURIProvided: authorizeRequest.RedirectURI != "",
+		Scope:               scope,
+		CodeChallenge:       authorizeRequest.CodeChallenge,
+		CodeChallengeMethod: authorizeRequest.CodeChallengeMethod,
+		Nonce:               authorizeRequest.Nonce,
+		AuthTime:            authTime,
+		AMR:                 identity.AMR,
+		ExpiresAt:           now.Add(codeTTL),
+	}, codeTTL)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	if authorizeRequest.State != "" {
+		params.Set("state", authorizeRequest.State)
+	}
+
+	return appendQuery(redirectURI, params), nil
+}
+
+func (uc *OAuthUsecase) Exchange(tokenRequest *dto.TokenRequest) (*dto.TokenResponse, error) {
+	client, err := uc.authenticateClient(tokenRequest.ClientID, tokenRequest.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !contains(supportedGrantTypes(), tokenRequest.GrantType) {
+		return nil, entity.ErrUnsupportedGrantType
+	}
+
+	if !client.AllowsGrant(tokenRequest.GrantType) {
+		return nil, entity.ErrUnauthorizedClient
+	}
+
+	var session *sessionEntity.Session
+	nonce := ""
+	switch tokenRequest.GrantType {
+	case grantTypeAuthorizationCode:
+		session, nonce, err = uc.exchangeCode(client, tokenRequest)
+	case grantTypeRefreshToken:
+		session, err = uc.exchangeRefreshToken(client, tokenRequest)
+	case grantTypeClientCredentials:
+		session, err = uc.exchangeClientCredentials(client, tokenRequest)
+	case grantTypeDeviceCode:
+		session, err = uc.exchangeDeviceCode(client, tokenRequest)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokenResponse := &dto.TokenResponse{
+		AccessToken:  session.JWTAccess,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(time.Until(session.AccessExpiresAt).Seconds()),
+		RefreshToken: session.JWTRefresh,
+		Scope:        session.Scope,
+	}
+
+	if tokenRequest.GrantType != grantTypeClientCredentials && hasScope(session.Scope, scopeOpenID) {
+		tokenResponse.IDToken, err = uc.createIDToken(session, nonce)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tokenResponse, nil
+}
+
+func (uc *OAuthUsecase) UserInfo(accessToken string) (*dto.UserInfoResponse, error) {
+	identity, err := uc.sessionUC.ValidateAccessToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity.Service || !hasScope(identity.Scope, scopeOpenID) {
+		return nil, entity.ErrInsufficientScope
+	}
+
+	user, err := uc.userRepo.GetById(identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo := &dto.UserInfoResponse{
+		Subject: strconv.Itoa(int(user.Id)),
+	}
+	if hasScope(identity.Scope, scopeProfile) {
+		userInfo.Name = user.Username
+		userInfo.PreferredUsername = user.Username
+	}
+
+	return userInfo, nil
+}
+
+func (uc *OAuthUsecase) ProviderMetadata() *dto.ProviderMetadata {
+	return &dto.ProviderMetadata{
+		Issuer:                            uc.issuer,
+		AuthorizationEndpoint:             uc.issuer + "/oauth/authorize",
+		TokenEndpoint:                     uc.issuer + "/oauth/token",
+		DeviceAuthorizationEndpoint:       uc.issuer + "/oauth/device_authorization",
+		IntrospectionEndpoint:             uc.issuer + "/oauth/introspect",
+		RevocationEndpoint:                uc.issuer + "/oauth/revoke",
+		UserInfoEndpoint:                  uc.issuer + "/userinfo",
+		JWKSURI:                           uc.issuer + "/.well-known/jwks.json",
+		ScopesSupported:                   []string{scopeOpenID, scopeProfile},
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               supportedGrantTypes(),
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{uc.tokenSigner.Method().Alg()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{codeChallengeMethodS256},
+		ClaimsSupported:                   []string{"sub", "aud", "iss", "exp", "iat", "auth_time", "nonce", "amr", "sid", "name", "preferred_username"},
+	}
+}
+
+func (uc *OAuthUsecase) createIDToken(session *sessionEntity.Session, nonce string) (string, error) {
+	extraClaims := jwt.MapClaims{
+		"iss": uc.issuer,
+	}
+	if nonce != "" {
+		extraClaims["nonce"] = nonce
+	}
+	if hasScope(session.Scope, scopeProfile) {
+		extraClaims["preferred_username"] = session.Username
+	}
+
+	return uc.sessionUC.CreateIDToken(session, extraClaims)
+}
+
+func (uc *OAuthUsecase) exchangeCode(client *entity.Client, tokenRequest *dto.TokenRequest) (*sessionEntity.Session, string, error) {
+	if tokenRequest.Code == "" || tokenRequest.CodeVerifier == "" {
+		return nil, "", entity.ErrInvalidRequest
+	}
+
+	codeModel, err := uc.codeRepo.Pop(tokenRequest.Code)
+	if errors.Is(err, entity.ErrNoCode) {
+		return nil, "", entity.ErrInvalidGrant
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if codeModel.ClientID != client.ID || time.Now().After(codeModel.ExpiresAt) {
+		return nil, "", entity.ErrInvalidGrant
+	}
+
+	// redirect_uri is only required when the authorize request carried one
+	// (RFC 6749, section 4.1.3); if sent anyway it still has to match.
+	if (codeModel.RedirectURIProvided || tokenRequest.RedirectURI != "") &&
+		codeModel.RedirectURI != tokenRequest.RedirectURI {
+		return nil, "", entity.ErrInvalidGrant
+	}
+
+	if !verifyCodeChallenge(codeModel.CodeChallenge, tokenRequest.CodeVerifier) {
+		return nil, "", entity.ErrInvalidGrant
+	}
+
+	session, err := uc.sessionUC.CreateDelegatedSession(codeModel.Username, codeModel.UserID, &sessionEntity.AuthContext{
+		ClientID: client.ID,
+		Scope:    codeModel.Scope,
+		AuthTime: codeModel.AuthTime,
+		AMR:      codeModel.AMR,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return session, codeModel.Nonce, nil
+}
+
+func (uc *OAuthUsecase) exchangeRefreshToken(client *entity.Client, tokenRequest *dto.TokenRequest) (*sessionEntity.Session, error) {
+	if tokenRequest.RefreshToken == "" {
+		return nil, entity.ErrInvalidRequest
+	}
+
+	token, err := uc.sessionUC.ParseToken(tokenRequest.RefreshToken)
+	if err != nil || !token.Valid {
+		return nil, entity.ErrInvalidGrant
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["client_id"] != client.ID {
+		return nil, entity.ErrInvalidGrant
+	}
+
+	session, err := uc.sessionUC.RefreshSession(token)
+	if errors.Is(err, sessionEntity.ErrNoSession) ||
+		errors.Is(err, sessionEntity.ErrInvalidRefreshToken) ||
+		errors.Is(err, sessionEntity.ErrRefreshTokenReused) {
+		return nil, entity.ErrInvalidGrant
+	}
+
+	return session, err
+}
+
+func (uc *OAuthUsecase) exchangeClientCredentials(client *entity.Client, tokenRequest *dto.TokenRequest) (*sessionEntity.Session, error) {
+	if !client.IsConfidential() {
+		return nil, entity.ErrUnauthorizedClient
+	}
+
+	scope, err := resolveScope(client, tokenRequest.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := client.TokenTTL
+	if ttl <= 0 {
+		ttl = defaultServiceTokenTTL
+	}
+
+	return uc.sessionUC.CreateServiceToken(client.ID, scope, ttl)
+}
+
+func (uc *OAuthUsecase) getClient(clientID string) (*entity.Client, error) {
+	if clientID == "" {
+		return nil, entity.ErrInvalidClient
+	}
+
+	clientModel, err := uc.clientRepo.GetByID(clientID)
+	if errors.Is(err, entity.ErrNoClient) {
+		return nil, entity.ErrInvalidClient
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ClientModelToEntity(clientModel), nil
+}
+
+func (uc *OAuthUsecase) authenticateClient(clientID string, clientSecret string) (*entity.Client, error) {
+	client, err := uc.getClient(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.IsConfidential() {
+		return client, nil
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(client.SecretHash), []byte(clientSecret))
+	if err != nil {
+		return nil, entity.ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+func supportedGrantTypes() []string {
+	return []string{grantTypeAuthorizationCode, grantTypeRefreshToken, grantTypeClientCredentials, grantTypeDeviceCode}
+}
+
+func resolveRedirectURI(client *entity.Client, requested string) (string, error) {
+	if requested == "" {
+		if len(client.RedirectURIs) == 1 {
+			return client.RedirectURIs[0], nil
+		}
+		return "", entity.ErrInvalidRedirectURI
+	}
+
+	for _, registered := range client.RedirectURIs {
+		if redirectURIMatches(registered, requested) {
+			return requested, nil
+		}
+	}
+
+	return "", entity.ErrInvalidRedirectURI
+}
+
+// redirectURIMatches requires an exact match, except that loopback redirects
+// of native apps may use any port (RFC 8252, section 7.3).
+func redirectURIMatches(registered string, requested string) bool {
+	if registered == requested {
+		return true
+	}
+
+	registeredURL, err := url.Parse(registered)
+	if err != nil {
+		return false
+	}
+
+	requestedURL, err := url.Parse(requested)
+	if err != nil {
+		return false
+	}
+
+	if registeredURL.Scheme != "http" || !isLoopback(registeredURL.Hostname()) {
+		return false
+	}
+
+	return requestedURL.Scheme == registeredURL.Scheme &&
+		requestedURL.Hostname() == registeredURL.Hostname() &&
+		requestedURL.Path == registeredURL.Path &&
+		requestedURL.RawQuery == registeredURL.RawQuery &&
+		requestedURL.Fragment == ""
+}
+
+func isLoopback(host string) bool {
+	return host == "127.0.0.1" || host == "::1"
+}
+
+func resolveScope(client *entity.Client, requested string) (string, error) {
+	if requested == "" {
+		return strings.Join(client.Scopes, " "), nil
+	}
+
+	for _, scope := range strings.Fields(requested) {
+		if !contains(client.Scopes, scope) {
+			return "", entity.ErrInvalidScope
+		}
+	}
+
+	return strings.Join(strings.Fields(requested), " "), nil
+}
+
+func hasScope(scope string, wanted string) bool {
+	return contains(strings.Fields(scope), wanted)
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func verifyCodeChallenge(codeChallenge string, codeVerifier string) bool {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(codeChallenge)) == 1
+}
+
+func errorRedirect(redirectURI string, err error, state string) string {
+	params := url.Values{}
+	params.Set("error", err.Error())
+	if state != "" {
+		params.Set("state", state)
+	}
+
+	return appendQuery(redirectURI, params)
+}
+
+func appendQuery(redirectURI string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(redirectURI, "?") {
+		separator = "&"
+	}
+
+	return redirectURI + separator + params.Encode()
+}
+
+func generateCode() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
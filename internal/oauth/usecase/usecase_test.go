@@ -0,0 +1,377 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/oauth/domain/dto"
+	"github.com/lightlink/auth-service/internal/oauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/oauth/domain/model"
+	oauthRepo "github.com/lightlink/auth-service/internal/oauth/repository/redis"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	"github.com/lightlink/auth-service/internal/testutil"
+)
+
+const (
+	testRedirectURI  = "https://app.example.com/callback"
+	testCodeVerifier = "dBjftJeZ4CVP-mJ92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+type fakeClientRepository struct {
+	clients map[string]*model.Client
+}
+
+func (f *fakeClientRepository) GetByID(clientID string) (*model.Client, error) {
+	client, ok := f.clients[clientID]
+	if !ok {
+		return nil, entity.ErrNoClient
+	}
+
+	return client, nil
+}
+
+type fakeSessionUsecase struct {
+	sessionUsecase.SessionUsecaseI
+	identities map[string]*sessionEntity.Identity
+}
+
+func (f *fakeSessionUsecase) ValidateAccessToken(tokenString string) (*sessionEntity.Identity, error) {
+	identity, ok := f.identities[tokenString]
+	if !ok {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	return identity, nil
+}
+
+func (f *fakeSessionUsecase) CreateDelegatedSession(username string, userID uint, authContext *sessionEntity.AuthContext) (*sessionEntity.Session, error) {
+	return &sessionEntity.Session{
+		ID:              "delegated",
+		JWTAccess:       "delegated-access",
+		UserID:          userID,
+		Username:        username,
+		ClientID:        authContext.ClientID,
+		Scope:           authContext.Scope,
+		AuthTime:        authContext.AuthTime,
+		AMR:             authContext.AMR,
+		AccessExpiresAt: time.Now().Add(15 * time.Minute),
+	}, nil
+}
+
+func (f *fakeSessionUsecase) CreateIDToken(session *sessionEntity.Session, extraClaims jwt.MapClaims) (string, error) {
+	return "id-token", nil
+}
+
+type testEnv struct {
+	uc    *OAuthUsecase
+	redis *miniredis.Miniredis
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	base := testutil.NewEnv(t)
+	clients := &fakeClientRepository{
+		clients: map[string]*model.Client{
+			"first-party": {
+				ID:           "first-party",
+				Name:         "Web",
+				RedirectURIs: []string{testRedirectURI},
+				Scopes:       []string{scopeOpenID, scopeProfile},
+				GrantTypes:   []string{grantTypeAuthorizationCode, grantTypeDeviceCode},
+				FirstParty:   true,
+			},
+			"third-party": {
+				ID:           "third-party",
+				Name:         "Partner",
+				RedirectURIs: []string{testRedirectURI},
+				Scopes:       []string{scopeOpenID, scopeProfile},
+				GrantTypes:   []string{grantTypeAuthorizationCode},
+			},
+			"multi-redirect": {
+				ID:           "multi-redirect",
+				RedirectURIs: []string{testRedirectURI, "https://app.example.com/other"},
+				Scopes:       []string{scopeOpenID},
+				GrantTypes:   []string{grantTypeAuthorizationCode},
+				FirstParty:   true,
+			},
+		},
+	}
+	sessions := &fakeSessionUsecase{
+		identities: map[string]*sessionEntity.Identity{
+			"alice-access": {UserID: 1, Username: "alice", SessionID: "alice-session", AMR: []string{"pwd"}},
+			"bob-access":   {UserID: 2, Username: "bob", SessionID: "bob-session", Restricted: true},
+		},
+	}
+
+	uc := NewOAuthUsecase(
+		clients,
+		oauthRepo.NewAuthorizationCodeRedisRepository(base.Dial()),
+		oauthRepo.NewConsentRedisRepository(base.Dial()),
+		oauthRepo.NewDeviceAuthorizationRedisRepository(base.Dial()),
+		nil,
+		sessions,
+		base.Signer,
+		"https://auth.example.com",
+		"",
+	)
+
+	return &testEnv{uc: uc, redis: base.Redis}
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func authorizeRequest(clientID string, redirectURI string) *dto.AuthorizeRequest {
+	return &dto.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            clientID,
+		RedirectURI:         redirectURI,
+		Scope:               scopeOpenID,
+		State:               "xyz",
+		CodeChallenge:       codeChallenge(testCodeVerifier),
+		CodeChallengeMethod: codeChallengeMethodS256,
+	}
+}
+
+// authorize runs the authorize step for alice and returns the issued code.
+func (env *testEnv) authorize(t *testing.T, authorizeRequest *dto.AuthorizeRequest) string {
+	t.Helper()
+
+	redirectURL, err := env.uc.Authorize("alice-access", authorizeRequest)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	parsed, err := url.Parse(redirectURL)
+	if err != nil {
+		t.Fatalf("parse redirect %q: %v", redirectURL, err)
+	}
+
+	if parsed.Query().Get("state") != authorizeRequest.State {
+		t.Fatalf("state = %q, want %q", parsed.Query().Get("state"), authorizeRequest.State)
+	}
+
+	return parsed.Query().Get("code")
+}
+
+func TestExchangeCode(t *testing.T) {
+	tests := []struct {
+		name              string
+		clientID          string
+		authorizeRedirect string
+		tokenClientID     string
+		tokenRedirect     string
+		codeVerifier      string
+		wantErr           error
+	}{
+		{"redirect sent both times", "first-party", testRedirectURI, "", testRedirectURI, testCodeVerifier, nil},
+		{"redirect omitted both times", "first-party", "", "", "", testCodeVerifier, nil},
+		{"redirect only sent at token", "first-party", "", "", testRedirectURI, testCodeVerifier, nil},
+		{"redirect missing at token", "first-party", testRedirectURI, "", "", testCodeVerifier, entity.ErrInvalidGrant},
+		{"redirect mismatch", "multi-redirect", testRedirectURI, "", "https://app.example.com/other", testCodeVerifier, entity.ErrInvalidGrant},
+		{"wrong verifier", "first-party", testRedirectURI, "", testRedirectURI, "not-the-verifier-but-long-enough-to-be-plausible", entity.ErrInvalidGrant},
+		{"missing verifier", "first-party", testRedirectURI, "", testRedirectURI, "", entity.ErrInvalidRequest},
+		{"other client", "first-party", testRedirectURI, "multi-redirect", testRedirectURI, testCodeVerifier, entity.ErrInvalidGrant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			code := env.authorize(t, authorizeRequest(tt.clientID, tt.authorizeRedirect))
+
+			tokenClientID := tt.tokenClientID
+			if tokenClientID == "" {
+				tokenClientID = tt.clientID
+			}
+
+			tokenResponse, err := env.uc.Exchange(&dto.TokenRequest{
+				GrantType:    grantTypeAuthorizationCode,
+				ClientID:     tokenClientID,
+				Code:         code,
+				RedirectURI:  tt.tokenRedirect,
+				CodeVerifier: tt.codeVerifier,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Exchange err = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && tokenResponse.IDToken == "" {
+				t.Errorf("openid scope returned no id_token")
+			}
+		})
+	}
+}
+
+func TestExchangeCodeSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+	code := env.authorize(t, authorizeRequest("first-party", testRedirectURI))
+
+	tokenRequest := &dto.TokenRequest{
+		GrantType:    grantTypeAuthorizationCode,
+		ClientID:     "first-party",
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+	}
+
+	_, err := env.uc.Exchange(tokenRequest)
+	if err != nil {
+		t.Fatalf("first Exchange: %v", err)
+	}
+
+	_, err = env.uc.Exchange(tokenRequest)
+	if !errors.Is(err, entity.ErrInvalidGrant) {
+		t.Fatalf("second Exchange err = %v, want %v", err, entity.ErrInvalidGrant)
+	}
+}
+
+func TestExchangeCodeExpired(t *testing.T) {
+	env := newTestEnv(t)
+	code := env.authorize(t, authorizeRequest("first-party", testRedirectURI))
+
+	env.redis.FastForward(codeTTL + time.Second)
+
+	_, err := env.uc.Exchange(&dto.TokenRequest{
+		GrantType:    grantTypeAuthorizationCode,
+		ClientID:     "first-party",
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+	})
+	if !errors.Is(err, entity.ErrInvalidGrant) {
+		t.Fatalf("Exchange err = %v, want %v", err, entity.ErrInvalidGrant)
+	}
+}
+
+func TestAuthorizeRequiresPKCE(t *testing.T) {
+	env := newTestEnv(t)
+	request := authorizeRequest("first-party", testRedirectURI)
+	request.CodeChallengeMethod = "plain"
+
+	redirectURL, err := env.uc.Authorize("alice-access", request)
+	if !errors.Is(err, entity.ErrInvalidRequest) {
+		t.Fatalf("Authorize err = %v, want %v", err, entity.ErrInvalidRequest)
+	}
+
+	parsed, _ := url.Parse(redirectURL)
+	if parsed.Query().Get("error") != entity.ErrInvalidRequest.Error() {
+		t.Errorf("redirect = %q, want error=%s", redirectURL, entity.ErrInvalidRequest)
+	}
+}
+
+func TestAuthorizeConsent(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientID     string
+		prompt       string
+		wantErr      error
+		wantRedirect bool
+	}{
+		{"first party skips consent", "first-party", "", nil, true},
+		{"third party asks", "third-party", "", entity.ErrConsentRequired, false},
+		{"third party silent", "third-party", promptNone, entity.ErrConsentRequired, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			request := authorizeRequest(tt.clientID, testRedirectURI)
+			request.Prompt = tt.prompt
+
+			redirectURL, err := env.uc.Authorize("alice-access", request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authorize err = %v, want %v", err, tt.wantErr)
+			}
+
+			if (redirectURL != "") != tt.wantRedirect {
+				t.Fatalf("redirect = %q, want redirect %v", redirectURL, tt.wantRedirect)
+			}
+		})
+	}
+}
+
+func TestDecideConsent(t *testing.T) {
+	env := newTestEnv(t)
+
+	consent, err := env.uc.GetConsent("alice-access", "third-party", "openid profile")
+	if err != nil {
+		t.Fatalf("GetConsent: %v", err)
+	}
+
+	consentRequest := &dto.ConsentRequest{
+		ClientID: "third-party",
+		Scope:    "openid",
+		State:    "xyz",
+		Approve:  true,
+	}
+
+	bobConsent, err := env.uc.GetConsent("bob-access", "third-party", "openid")
+	if !errors.Is(err, entity.ErrLoginRequired) {
+		t.Fatalf("GetConsent for restricted user = %v, %v, want %v", bobConsent, err, entity.ErrLoginRequired)
+	}
+
+	otherCSRF, err := env.uc.issueCSRFToken("other-session", csrfPurposeConsent)
+	if err != nil {
+		t.Fatalf("issueCSRFToken: %v", err)
+	}
+
+	for _, csrfToken := range []string{"", "garbage", otherCSRF} {
+		_, err = env.uc.DecideConsent("alice-access", csrfToken, consentRequest)
+		if !errors.Is(err, entity.ErrInvalidCSRFToken) {
+			t.Fatalf("DecideConsent(%q) err = %v, want %v", csrfToken, err, entity.ErrInvalidCSRFToken)
+		}
+	}
+
+	_, err = env.uc.DecideConsent("alice-access", consent.CSRFToken, consentRequest)
+	if err != nil {
+		t.Fatalf("DecideConsent: %v", err)
+	}
+
+	env.authorize(t, authorizeRequest("third-party", testRedirectURI))
+
+	// Consent covers the granted scopes only.
+	wider := authorizeRequest("third-party", testRedirectURI)
+	wider.Scope = "openid profile"
+	_, err = env.uc.Authorize("alice-access", wider)
+	if !errors.Is(err, entity.ErrConsentRequired) {
+		t.Fatalf("Authorize with wider scope err = %v, want %v", err, entity.ErrConsentRequired)
+	}
+}
+
+func TestDenyConsent(t *testing.T) {
+	env := newTestEnv(t)
+
+	consent, err := env.uc.GetConsent("alice-access", "third-party", "openid")
+	if err != nil {
+		t.Fatalf("GetConsent: %v", err)
+	}
+
+	decision, err := env.uc.DecideConsent("alice-access", consent.CSRFToken, &dto.ConsentRequest{
+		ClientID: "third-party",
+		Scope:    "openid",
+		State:    "xyz",
+	})
+	if err != nil {
+		t.Fatalf("DecideConsent: %v", err)
+	}
+
+	parsed, _ := url.Parse(decision.RedirectTo)
+	if parsed.Query().Get("error") != entity.ErrAccessDenied.Error() || parsed.Query().Get("state") != "xyz" {
+		t.Errorf("redirect = %q, want access_denied with state", decision.RedirectTo)
+	}
+
+	_, err = env.uc.Authorize("alice-access", authorizeRequest("third-party", testRedirectURI))
+	if !errors.Is(err, entity.ErrConsentRequired) {
+		t.Fatalf("Authorize after denial err = %v, want %v", err, entity.ErrConsentRequired)
+	}
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/lightlink/auth-service/internal/passkey/domain/entity"
+	"github.com/lightlink/auth-service/internal/passkey/usecase"
+	sessionDelivery "github.com/lightlink/auth-service/internal/session/delivery/http"
+)
+
+const challengeCookie = "webauthn_challenge"
+
+type PasskeyHandler struct {
+	passkeyUC usecase.PasskeyUsecaseI
+}
+
+func NewPasskeyHandler(passkeyUsecase usecase.PasskeyUsecaseI) *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyUC: passkeyUsecase,
+	}
+}
+
+func (h *PasskeyHandler) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	userIDString := r.Header.Get("X-User-ID")
+	userID64, err := strconv.ParseUint(userIDString, 10, 32)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	creation, challengeID, err := h.passkeyUC.BeginRegistration(uint(userID64), r.Header.Get("X-Username"))
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("passkey begin registration err", err)
+		return
+	}
+
+	setChallengeCookie(w, challengeID)
+	writeJSON(w, http.StatusOK, creation)
+}
+
+func (h *PasskeyHandler) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	userIDString := r.Header.Get("X-User-ID")
+	userID64, err := strconv.ParseUint(userIDString, 10, 32)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	challenge, err := r.Cookie(challengeCookie)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("Challenge cookie missing")
+		return
+	}
+
+	response, err := protocol.ParseCredentialCreationResponseBody(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("passkey registration parse err", err)
+		return
+	}
+
+	err = h.passkeyUC.FinishRegistration(uint(userID64), challenge.Value, response)
+	clearChallengeCookie(w)
+	if errors.Is(err, entity.ErrCredentialExists) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Println("passkey finish registration err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("passkey finish registration err", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *PasskeyHandler) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	assertion, challengeID, err := h.passkeyUC.BeginLogin()
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("passkey begin login err", err)
+		return
+	}
+
+	setChallengeCookie(w, challengeID)
+	writeJSON(w, http.StatusOK, assertion)
+}
+
+func (h *PasskeyHandler) FinishLogin(w http.ResponseWriter, r *http.Request) {
+	challenge, err := r.Cookie(challengeCookie)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("Challenge cookie missing")
+		return
+	}
+
+	response, err := protocol.ParseCredentialRequestResponseBody(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("passkey login parse err", err)
+		return
+	}
+
+	createdSessionEntity, err := h.passkeyUC.FinishLogin(challenge.Value, response)
+	clearChallengeCookie(w)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("passkey finish login err", err)
+		return
+	}
+
+	sessionDelivery.SetSessionCookies(w, createdSessionEntity)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func setChallengeCookie(w http.ResponseWriter, challengeID string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     challengeCookie,
+		Value:    challengeID,
+		Path:     "/api/passkeys",
+		MaxAge:   300,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+		Secure:   false,
+	})
+}
+
+func clearChallengeCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     challengeCookie,
+		Value:    "",
+		Path:     "/api/passkeys",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   false,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("marshal err", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
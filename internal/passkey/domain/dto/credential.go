@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/lightlink/auth-service/internal/passkey/domain/entity"
+	"github.com/lightlink/auth-service/internal/passkey/domain/model"
+)
+
+func CredentialEntityToModel(credentialEntity *entity.Credential) *model.Credential {
+	return &model.Credential{
+		ID:              credentialEntity.ID,
+		UserID:          credentialEntity.UserID,
+		PublicKey:       credentialEntity.PublicKey,
+		AttestationType: credentialEntity.AttestationType,
+		Transports:      credentialEntity.Transports,
+		AAGUID:          credentialEntity.AAGUID,
+		SignCount:       credentialEntity.SignCount,
+		CloneWarning:    credentialEntity.CloneWarning,
+		UserPresent:     credentialEntity.UserPresent,
+		UserVerified:    credentialEntity.UserVerified,
+		BackupEligible:  credentialEntity.BackupEligible,
+		BackupState:     credentialEntity.BackupState,
+		CreatedAt:       credentialEntity.CreatedAt,
+	}
+}
+
+func CredentialModelToEntity(credentialModel *model.Credential) *entity.Credential {
+	return &entity.Credential{
+		ID:              credentialModel.ID,
+		UserID:          credentialModel.UserID,
+		PublicKey:       credentialModel.PublicKey,
+		AttestationType: credentialModel.AttestationType,
+		Transports:      credentialModel.Transports,
+		AAGUID:          credentialModel.AAGUID,
+		SignCount:       credentialModel.SignCount,
+		CloneWarning:    credentialModel.CloneWarning,
+		UserPresent:     credentialModel.UserPresent,
+		UserVerified:    credentialModel.UserVerified,
+		BackupEligible:  credentialModel.BackupEligible,
+		BackupState:     credentialModel.BackupState,
+		CreatedAt:       credentialModel.CreatedAt,
+	}
+}
+
+func WebAuthnCredentialToEntity(userID uint, credential *webauthn.Credential) *entity.Credential {
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	return &entity.Credential{
+		ID:              credential.ID,
+		UserID:          userID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      transports,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		CloneWarning:    credential.Authenticator.CloneWarning,
+		UserPresent:     credential.Flags.UserPresent,
+		UserVerified:    credential.Flags.UserVerified,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		CreatedAt:       time.Now(),
+	}
+}
+
+func CredentialModelToWebAuthn(credentialModel *model.Credential) webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, 0, len(credentialModel.Transports))
+	for _, transport := range credentialModel.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(transport))
+	}
+
+	return webauthn.Credential{
+		ID:              credentialModel.ID,
+		PublicKey:       credentialModel.PublicKey,
+		AttestationType: credentialModel.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			UserPresent:    credentialModel.UserPresent,
+			UserVerified:   credentialModel.UserVerified,
+			BackupEligible: credentialModel.BackupEligible,
+			BackupState:    credentialModel.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:       credentialModel.AAGUID,
+			SignCount:    credentialModel.SignCount,
+			CloneWarning: credentialModel.CloneWarning,
+		},
+	}
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type Credential struct {
+	ID              []byte
+	UserID          uint
+	PublicKey       []byte
+	AttestationType string
+	Transports      []string
+	AAGUID          []byte
+	SignCount       uint32
+	CloneWarning    bool
+	UserPresent     bool
+	UserVerified    bool
+	BackupEligible  bool
+	BackupState     bool
+	CreatedAt       time.Time
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "errors"
+
+var (
+	ErrNoChallenge       = errors.New("couldn't find webauthn challenge")
+	ErrChallengeMismatch = errors.New("webauthn challenge belongs to another user")
+	ErrCredentialExists  = errors.New("credential is already registered")
+	ErrNoCredential      = errors.New("couldn't find credential")
+	ErrClonedCredential  = errors.New("credential sign counter went backwards")
+)
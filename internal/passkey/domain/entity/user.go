@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"strconv"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+type PasskeyUser struct {
+	ID          uint
+	Username    string
+	Credentials []webauthn.Credential
+}
+
+func UserHandle(userID uint) []byte {
+	return []byte(strconv.Itoa(int(userID)))
+}
+
+func (u *PasskeyUser) WebAuthnID() []byte {
+	return UserHandle(u.ID)
+}
+
+func (u *PasskeyUser) WebAuthnName() string {
+	return u.Username
+}
+
+func (u *PasskeyUser) WebAuthnDisplayName() string {
+	return u.Username
+}
+
+func (u *PasskeyUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.Credentials
+}
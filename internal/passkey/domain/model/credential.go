@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+type Credential struct {
+	ID              []byte    `json:"id"`
+	UserID          uint      `json:"user_id"`
+	PublicKey       []byte    `json:"public_key"`
+	AttestationType string    `json:"attestation_type"`
+	Transports      []string  `json:"transports"`
+	AAGUID          []byte    `json:"aaguid"`
+	SignCount       uint32    `json:"sign_count"`
+	CloneWarning    bool      `json:"clone_warning"`
+	UserPresent     bool      `json:"user_present"`
+	UserVerified    bool      `json:"user_verified"`
+	BackupEligible  bool      `json:"backup_eligible"`
+	BackupState     bool      `json:"backup_state"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type Challenge struct {
+	UserID      uint                 `json:"user_id"`
+	SessionData webauthn.SessionData `json:"session_data"`
+}
@@ -0,0 +1,74 @@
+package redis
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/passkey/domain/entity"
+	"github.com/lightlink/auth-service/internal/passkey/domain/model"
+)
+
+type ChallengeRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewChallengeRedisRepository(conn redis.Conn) *ChallengeRedisRepository {
+	return &ChallengeRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+func challengeKey(challengeID string) string {
+	return "webauthn_challenges:" + challengeID
+}
+
+func (repo *ChallengeRedisRepository) Set(challengeID string, userID uint, sessionData *webauthn.SessionData, ttl time.Duration) error {
+	challengeSerialized, err := json.Marshal(&model.Challenge{
+		UserID:      userID,
+		SessionData: *sessionData,
+	})
+	if err != nil {
+		return err
+	}
+
+	repo.mu.Lock()
+	_, err = repo.redisConn.Do("SET", challengeKey(challengeID), challengeSerialized, "EX", int(ttl.Seconds()))
+	repo.mu.Unlock()
+
+	return err
+}
+
+func (repo *ChallengeRedisRepository) Pop(challengeID string) (*model.Challenge, error) {
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("GET", challengeKey(challengeID))
+	repo.redisConn.Send("DEL", challengeKey(challengeID))
+	result, err := redis.Values(repo.redisConn.Do("EXEC"))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := redis.Bytes(result[0], nil)
+	if err == redis.ErrNil {
+		return nil, entity.ErrNoChallenge
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &model.Challenge{}
+	err = json.Unmarshal(bytes, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
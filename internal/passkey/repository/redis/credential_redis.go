@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/passkey/domain/dto"
+	"github.com/lightlink/auth-service/internal/passkey/domain/entity"
+	"github.com/lightlink/auth-service/internal/passkey/domain/model"
+)
+
+type CredentialRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewCredentialRedisRepository(conn redis.Conn) *CredentialRedisRepository {
+	return &CredentialRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+func credentialsKey(userID uint) string {
+	return "webauthn_credentials:" + strconv.Itoa(int(userID))
+}
+
+func credentialField(credentialID []byte) string {
+	return base64.RawURLEncoding.EncodeToString(credentialID)
+}
+
+func (repo *CredentialRedisRepository) Create(credentialEntity *entity.Credential) (*model.Credential, error) {
+	credentialModel := dto.CredentialEntityToModel(credentialEntity)
+	credentialSerialized, err := json.Marshal(credentialModel)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.mu.Lock()
+	created, err := redis.Int(repo.redisConn.Do(
+		"HSETNX",
+		credentialsKey(credentialModel.UserID),
+		credentialField(credentialModel.ID),
+		credentialSerialized,
+	))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if created == 0 {
+		return nil, entity.ErrCredentialExists
+	}
+
+	return credentialModel, nil
+}
+
+func (repo *CredentialRedisRepository) Update(credentialEntity *entity.Credential) (*model.Credential, error) {
+	credentialModel := dto.CredentialEntityToModel(credentialEntity)
+	credentialSerialized, err := json.Marshal(credentialModel)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.mu.Lock()
+	_, err = repo.redisConn.Do(
+		"HSET",
+		credentialsKey(credentialModel.UserID),
+		credentialField(credentialModel.ID),
+		credentialSerialized,
+	)
+	repo.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return credentialModel, nil
+}
+
+func (repo *CredentialRedisRepository) ListByUser(userID uint) ([]*model.Credential, error) {
+	repo.mu.Lock()
+	values, err := redis.ByteSlices(repo.redisConn.Do("HVALS", credentialsKey(userID)))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]*model.Credential, 0, len(values))
+	for _, value := range values {
+		credential := &model.Credential{}
+		err = json.Unmarshal(value, credential)
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, credential)
+	}
+
+	return credentials, nil
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/lightlink/auth-service/internal/passkey/domain/entity"
+	"github.com/lightlink/auth-service/internal/passkey/domain/model"
+)
+
+type CredentialRepositoryI interface {
+	Create(credentialEntity *entity.Credential) (*model.Credential, error)
+	Update(credentialEntity *entity.Credential) (*model.Credential, error)
+	ListByUser(userID uint) ([]*model.Credential, error)
+}
+
+type ChallengeRepositoryI interface {
+	Set(challengeID string, userID uint, sessionData *webauthn.SessionData, ttl time.Duration) error
+	Pop(challengeID string) (*model.Challenge, error)
+}
@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/lightlink/auth-service/internal/passkey/domain/dto"
+	"github.com/lightlink/auth-service/internal/passkey/domain/entity"
+	passkeyRepo "github.com/lightlink/auth-service/internal/passkey/repository"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	userRepo "github.com/lightlink/auth-service/internal/user/repository"
+)
+
+const challengeTTL = 5 * time.Minute
+
+type PasskeyUsecaseI interface {
+	BeginRegistration(userID uint, username string) (*protocol.CredentialCreation, string, error)
+	FinishRegistration(userID uint, challengeID string, response *protocol.ParsedCredentialCreationData) error
+	BeginLogin() (*protocol.CredentialAssertion, string, error)
+	FinishLogin(challengeID string, response *protocol.ParsedCredentialAssertionData) (*sessionEntity.Session, error)
+}
+
+type PasskeyUsecase struct {
+	webAuthn       *webauthn.WebAuthn
+	credentialRepo passkeyRepo.CredentialRepositoryI
+	challengeRepo  passkeyRepo.ChallengeRepositoryI
+	userRepo       userRepo.UserRepositoryI
+	sessionUC      sessionUsecase.SessionUsecaseI
+}
+
+func NewPasskeyUsecase(
+	webAuthn *webauthn.WebAuthn,
+	credentialRepository passkeyRepo.CredentialRepositoryI,
+	challengeRepository passkeyRepo.ChallengeRepositoryI,
+	userRepository userRepo.UserRepositoryI,
+	sessionUsecase sessionUsecase.SessionUsecaseI,
+) *PasskeyUsecase {
+	return &PasskeyUsecase{
+		webAuthn:       webAuthn,
+		credentialRepo: credentialRepository,
+		challengeRepo:  challengeRepository,
+		userRepo:       userRepository,
+		sessionUC:      sessionUsecase,
+	}
+}
+
+func NewWebAuthnFromEnv() (*webauthn.WebAuthn, error) {
+	attestation := protocol.PreferNoAttestation
+	if os.Getenv("WEBAUTHN_ATTESTATION") == "direct" {
+		attestation = protocol.PreferDirectAttestation
+	}
+
+	return webauthn.New(&webauthn.Config{
+		RPID:                  os.Getenv("WEBAUTHN_RP_ID"),
+		RPDisplayName:         os.Getenv("WEBAUTHN_RP_NAME"),
+		RPOrigins:             strings.Split(os.Getenv("WEBAUTHN_RP_ORIGINS"), ","),
+		AttestationPreference: attestation,
+	})
+}
+
+func (uc *PasskeyUsecase) BeginRegistration(userID uint, username string) (*protocol.CredentialCreation, string, error) {
+	user, err := uc.loadUser(userID, username)
+	if err != nil {
+		return nil, "", err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.Credentials))
+	for _, credential := range user.Credentials {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	creation, sessionData, err := uc.webAuthn.BeginRegistration(
+		user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	challengeID, err := generateChallengeID()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = uc.challengeRepo.Set(challengeID, userID, sessionData, challengeTTL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return creation, challengeID, nil
+}
+
+func (uc *PasskeyUsecase) FinishRegistration(userID uint, challengeID string, response *protocol.ParsedCredentialCreationData) error {
+	challenge, err := uc.challengeRepo.Pop(challengeID)
+	if err != nil {
+		return err
+	}
+
+	if challenge.UserID != userID {
+		return entity.ErrChallengeMismatch
+	}
+
+	user, err := uc.loadUser(userID, "")
+	if err != nil {
+		return err
+	}
+
+	credential, err := uc.webAuthn.CreateCredential(user, challenge.SessionData, response)
+	if err != nil {
+		return err
+	}
+
+	_, err = uc.credentialRepo.Create(dto.WebAuthnCredentialToEntity(userID, credential))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (uc *PasskeyUsecase) BeginLogin() (*protocol.CredentialAssertion, string, error) {
+	assertion, sessionData, err := uc.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	challengeID, err := generateChallengeID()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = uc.challengeRepo.Set(challengeID, 0, sessionData, challengeTTL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return assertion, challengeID, nil
+}
+
+func (uc *PasskeyUsecase) FinishLogin(challengeID string, response *protocol.ParsedCredentialAssertionData) (*sessionEntity.Session, error) {
+	challenge, err := uc.challengeRepo.Pop(challengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var loggedInUser *entity.PasskeyUser
+	credential, err := uc.webAuthn.ValidateDiscoverableLogin(
+		func(rawID, userHandle []byte) (webauthn.User, error) {
+			userID64, err := strconv.ParseUint(string(userHandle), 10, 32)
+			if err != nil {
+				return nil, entity.ErrNoCredential
+			}
+
+			loggedInUser, err = uc.loadUser(uint(userID64), "")
+			if err != nil {
+				return nil, err
+			}
+
+			return loggedInUser, nil
+		},
+		challenge.SessionData,
+		response,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if credential.Authenticator.CloneWarning {
+		log.Printf("passkey clone warning: user %d, credential %x", loggedInUser.ID, credential.ID)
+		return nil, entity.ErrClonedCredential
+	}
+
+	err = uc.updateAuthenticator(loggedInUser.ID, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without user verification the assertion only shows that someone holds
+	// the key, a single factor, so the session usecase still asks for TOTP.
+	amr := []string{sessionUsecase.AMRUserPresence}
+	if credential.Flags.UserVerified {
+		amr = []string{sessionUsecase.AMRHardwareKey}
+	}
+
+	return uc.sessionUC.CreateSession(loggedInUser.Username, loggedInUser.ID, amr)
+}
+
+func (uc *PasskeyUsecase) updateAuthenticator(userID uint, credential *webauthn.Credential) error {
+	credentialModels, err := uc.credentialRepo.ListByUser(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, credentialModel := range credentialModels {
+		if !bytes.Equal(credentialModel.ID, credential.ID) {
+			continue
+		}
+
+		credentialEntity := dto.CredentialModelToEntity(credentialModel)
+		credentialEntity.SignCount = credential.Authenticator.SignCount
+		credentialEntity.BackupState = credential.Flags.BackupState
+
+		_, err = uc.credentialRepo.Update(credentialEntity)
+		return err
+	}
+
+	return entity.ErrNoCredential
+}
+
+func (uc *PasskeyUsecase) loadUser(userID uint, username string) (*entity.PasskeyUser, error) {
+	if username == "" {
+		user, err := uc.userRepo.GetById(userID)
+		if err != nil {
+			return nil, err
+		}
+		username = user.Username
+	}
+
+	credentialModels, err := uc.credentialRepo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(credentialModels))
+	for _, credentialModel := range credentialModels {
+		credentials = append(credentials, dto.CredentialModelToWebAuthn(credentialModel))
+	}
+
+	return &entity.PasskeyUser{
+		ID:          userID,
+		Username:    username,
+		Credentials: credentials,
+	}, nil
+}
+
+func generateChallengeID() (string, error) {
+	buf := make([]byte, 16)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,368 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/lightlink/auth-service/internal/passkey/domain/entity"
+	passkeyRepo "github.com/lightlink/auth-service/internal/passkey/repository/redis"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	"github.com/lightlink/auth-service/internal/testutil"
+	userDTO "github.com/lightlink/auth-service/internal/user/domain/dto"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+// Authenticator data flags, WebAuthn section 6.1.
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttestedData = 0x40
+)
+
+// softAuthenticator is a platform authenticator in software: one P-256
+// credential with "none" attestation and a signature counter the test can
+// wind back. Assertions carry the UV flag unless presenceOnly is set.
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+	presenceOnly bool
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	credentialID := make([]byte, 16)
+	rand.Read(credentialID)
+
+	return &softAuthenticator{key: key, credentialID: credentialID}
+}
+
+func clientDataJSON(ceremony string, challenge []byte, origin string) []byte {
+	clientData, _ := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    origin,
+	})
+
+	return clientData
+}
+
+func (a *softAuthenticator) authenticatorData(flags byte, attestedData []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+
+	authData := append([]byte{}, rpIDHash[:]...)
+	authData = append(authData, flags)
+	authData = binary.BigEndian.AppendUint32(authData, a.signCount)
+
+	return append(authData, attestedData...)
+}
+
+func (a *softAuthenticator) register(t *testing.T, challenge []byte, origin string) *protocol.ParsedCredentialCreationData {
+	t.Helper()
+
+	publicKey, err := webauthncbor.Marshal(map[int]interface{}{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+
+	attestedData := make([]byte, 16) // zero AAGUID
+	attestedData = binary.BigEndian.AppendUint16(attestedData, uint16(len(a.credentialID)))
+	attestedData = append(attestedData, a.credentialID...)
+	attestedData = append(attestedData, publicKey...)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authenticatorData(flagUserPresent|flagUserVerified|flagAttestedData, attestedData),
+	})
+	if err != nil {
+		t.Fatalf("marshal attestation object: %v", err)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientDataJSON("webauthn.create", challenge, origin)),
+			"attestationObject": base64.RawURLEncoding.EncodeToString(attestationObject),
+		},
+	})
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("parse creation response: %v", err)
+	}
+
+	return parsed
+}
+
+func (a *softAuthenticator) assert(t *testing.T, challenge []byte, origin string, userHandle []byte) *protocol.ParsedCredentialAssertionData {
+	t.Helper()
+
+	flags := byte(flagUserPresent | flagUserVerified)
+	if a.presenceOnly {
+		flags = flagUserPresent
+	}
+
+	authData := a.authenticatorData(flags, nil)
+	clientData := clientDataJSON("webauthn.get", challenge, origin)
+	clientDataHash := sha256.Sum256(clientData)
+	signed := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, signed[:])
+	if err != nil {
+		t.Fatalf("sign assertion: %v", err)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+			"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+			"signature":         base64.RawURLEncoding.EncodeToString(signature),
+			"userHandle":        base64.RawURLEncoding.EncodeToString(userHandle),
+		},
+	})
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("parse assertion response: %v", err)
+	}
+
+	return parsed
+}
+
+type fakeSessionUsecase struct {
+	sessionUsecase.SessionUsecaseI
+}
+
+func (f *fakeSessionUsecase) CreateSession(username string, userID uint, amr []string) (*sessionEntity.Session, error) {
+	return &sessionEntity.Session{UserID: userID, Username: username, AMR: amr}, nil
+}
+
+func newTestUsecase(t *testing.T) *PasskeyUsecase {
+	t.Helper()
+
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "Example",
+		RPOrigins:     []string{testOrigin},
+	})
+	if err != nil {
+		t.Fatalf("webauthn.New: %v", err)
+	}
+
+	base := testutil.NewEnv(t)
+
+	return NewPasskeyUsecase(
+		webAuthn,
+		passkeyRepo.NewCredentialRedisRepository(base.Dial()),
+		passkeyRepo.NewChallengeRedisRepository(base.Dial()),
+		testutil.NewUserRepository(
+			&userDTO.UserTransfer{Id: 1, Username: "alice"},
+			&userDTO.UserTransfer{Id: 2, Username: "bob"},
+		),
+		&fakeSessionUsecase{},
+	)
+}
+
+// registerPasskey runs a full registration ceremony for alice.
+func registerPasskey(t *testing.T, uc *PasskeyUsecase, authenticator *softAuthenticator) {
+	t.Helper()
+
+	creation, challengeID, err := uc.BeginRegistration(1, "alice")
+	if err != nil {
+		t.Fatalf("BeginRegistration: %v", err)
+	}
+
+	err = uc.FinishRegistration(1, challengeID, authenticator.register(t, creation.Response.Challenge, testOrigin))
+	if err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+}
+
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	uc := newTestUsecase(t)
+	authenticator := newSoftAuthenticator(t)
+	registerPasskey(t, uc, authenticator)
+
+	assertion, challengeID, err := uc.BeginLogin()
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+
+	authenticator.signCount = 1
+	session, err := uc.FinishLogin(challengeID, authenticator.assert(t, assertion.Response.Challenge, testOrigin, entity.UserHandle(1)))
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+
+	if session.UserID != 1 || len(session.AMR) != 1 || session.AMR[0] != sessionUsecase.AMRHardwareKey {
+		t.Errorf("FinishLogin session = user %d amr %v, want user 1 with hwk", session.UserID, session.AMR)
+	}
+
+	credentials, err := uc.credentialRepo.ListByUser(1)
+	if err != nil || len(credentials) != 1 || credentials[0].SignCount != 1 {
+		t.Fatalf("stored credentials = %v, %v, want one with sign count 1", credentials, err)
+	}
+}
+
+func TestFinishLoginUserVerification(t *testing.T) {
+	tests := []struct {
+		name         string
+		presenceOnly bool
+		wantAMR      string
+	}{
+		{"user verified", false, sessionUsecase.AMRHardwareKey},
+		{"presence only", true, sessionUsecase.AMRUserPresence},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newTestUsecase(t)
+			authenticator := newSoftAuthenticator(t)
+			registerPasskey(t, uc, authenticator)
+
+			assertion, challengeID, err := uc.BeginLogin()
+			if err != nil {
+				t.Fatalf("BeginLogin: %v", err)
+			}
+
+			authenticator.presenceOnly = tt.presenceOnly
+			authenticator.signCount = 1
+			session, err := uc.FinishLogin(challengeID, authenticator.assert(t, assertion.Response.Challenge, testOrigin, entity.UserHandle(1)))
+			if err != nil {
+				t.Fatalf("FinishLogin: %v", err)
+			}
+
+			if len(session.AMR) != 1 || session.AMR[0] != tt.wantAMR {
+				t.Errorf("FinishLogin amr = %v, want [%s]", session.AMR, tt.wantAMR)
+			}
+		})
+	}
+}
+
+func TestFinishRegistrationRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		userID    uint
+		challenge func(issued []byte) []byte
+		origin    string
+		wantErr   error
+	}{
+		{"other challenge", 1, func(issued []byte) []byte { return []byte("not the issued challenge") }, testOrigin, nil},
+		{"wrong origin", 1, func(issued []byte) []byte { return issued }, "https://evil.example.net", nil},
+		{"other user", 2, func(issued []byte) []byte { return issued }, testOrigin, entity.ErrChallengeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newTestUsecase(t)
+			authenticator := newSoftAuthenticator(t)
+
+			creation, challengeID, err := uc.BeginRegistration(1, "alice")
+			if err != nil {
+				t.Fatalf("BeginRegistration: %v", err)
+			}
+
+			err = uc.FinishRegistration(tt.userID, challengeID, authenticator.register(t, tt.challenge(creation.Response.Challenge), tt.origin))
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("FinishRegistration err = %v, want %v", err, tt.wantErr)
+			}
+
+			credentials, _ := uc.credentialRepo.ListByUser(1)
+			if len(credentials) != 0 {
+				t.Errorf("rejected registration stored %d credentials", len(credentials))
+			}
+
+			// The challenge is gone even though the ceremony failed.
+			err = uc.FinishRegistration(1, challengeID, authenticator.register(t, creation.Response.Challenge, testOrigin))
+			if !errors.Is(err, entity.ErrNoChallenge) {
+				t.Fatalf("retry err = %v, want %v", err, entity.ErrNoChallenge)
+			}
+		})
+	}
+}
+
+func TestFinishLoginRejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		challenge  func(issued []byte) []byte
+		origin     string
+		userHandle []byte
+		signCount  uint32
+		wantErr    error
+	}{
+		{"other challenge", func(issued []byte) []byte { return []byte("not the issued challenge") }, testOrigin, entity.UserHandle(1), 6, nil},
+		{"wrong origin", func(issued []byte) []byte { return issued }, "https://evil.example.net", entity.UserHandle(1), 6, nil},
+		{"other user handle", func(issued []byte) []byte { return issued }, testOrigin, entity.UserHandle(2), 6, nil},
+		{"sign count regression", func(issued []byte) []byte { return issued }, testOrigin, entity.UserHandle(1), 3, entity.ErrClonedCredential},
+		{"sign count replay", func(issued []byte) []byte { return issued }, testOrigin, entity.UserHandle(1), 5, entity.ErrClonedCredential},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newTestUsecase(t)
+			authenticator := newSoftAuthenticator(t)
+			registerPasskey(t, uc, authenticator)
+
+			assertion, challengeID, err := uc.BeginLogin()
+			if err != nil {
+				t.Fatalf("BeginLogin: %v", err)
+			}
+
+			authenticator.signCount = 5
+			_, err = uc.FinishLogin(challengeID, authenticator.assert(t, assertion.Response.Challenge, testOrigin, entity.UserHandle(1)))
+			if err != nil {
+				t.Fatalf("first FinishLogin: %v", err)
+			}
+
+			assertion, challengeID, err = uc.BeginLogin()
+			if err != nil {
+				t.Fatalf("BeginLogin: %v", err)
+			}
+
+			authenticator.signCount = tt.signCount
+			session, err := uc.FinishLogin(challengeID, authenticator.assert(t, tt.challenge(assertion.Response.Challenge), tt.origin, tt.userHandle))
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("FinishLogin = %v, %v, want error %v", session, err, tt.wantErr)
+			}
+
+			credentials, _ := uc.credentialRepo.ListByUser(1)
+			if len(credentials) != 1 || credentials[0].SignCount != 5 {
+				t.Errorf("stored sign count changed after a rejected login: %v", credentials)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lightlink/auth-service/internal/room/domain/dto"
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+	"github.com/lightlink/auth-service/internal/room/usecase"
+)
+
+type RoomHandler struct {
+	roomUC      usecase.RoomUsecaseI
+	rolesHeader string
+}
+
+func NewRoomHandler(roomUsecase usecase.RoomUsecaseI, rolesHeader string) *RoomHandler {
+	return &RoomHandler{
+		roomUC:      roomUsecase,
+		rolesHeader: rolesHeader,
+	}
+}
+
+func (h *RoomHandler) JoinToken(w http.ResponseWriter, r *http.Request) {
+	participant, err := participantFromHeaders(r, h.rolesHeader)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	joinRequest := &dto.JoinTokenRequest{}
+	err = json.Unmarshal(body, joinRequest)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	joinToken, err := h.roomUC.CreateJoinToken(participant, joinRequest)
+	if errors.Is(err, entity.ErrInvalidRoomID) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("join token err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrRoomForbidden) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Println("join token err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("join token err", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.JoinTokenEntityToResponse(joinToken))
+}
+
+func (h *RoomHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	participant, err := participantFromHeaders(r, h.rolesHeader)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	inviteRequest := &dto.CreateInviteRequest{}
+	err = json.Unmarshal(body, inviteRequest)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	invite, err := h.roomUC.CreateInvite(participant, inviteRequest)
+	if errors.Is(err, entity.ErrInvalidRoomID) || errors.Is(err, entity.ErrInvalidInvite) || errors.Is(err, entity.ErrInvalidRole) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("create invite err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrInviteForbidden) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Println("create invite err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("create invite err", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, dto.InviteEntityToResponse(invite))
+}
+
+func (h *RoomHandler) RedeemInvite(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	redeemRequest := &dto.RedeemInviteRequest{}
+	err = json.Unmarshal(body, redeemRequest)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	redeemResponse, err := h.roomUC.RedeemInvite(redeemRequest, accessTokenFromRequest(r))
+	if errors.Is(err, entity.ErrInvalidDisplayName) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("redeem invite err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrInvalidInvite) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Println("redeem invite err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrInviteExhausted) {
+		w.WriteHeader(http.StatusGone)
+		fmt.Println("redeem invite err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrInviteRoleRequired) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Println("redeem invite err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("redeem invite err", err)
+		return
+	}
+
+	if redeemResponse.AccessToken != "" {
+		http.SetCookie(w, &http.Cookie{
+			Name:    "access_token",
+			Value:   redeemResponse.AccessToken,
+			Path:    "/",
+			Expires: time.Unix(redeemResponse.AccessExpiresAt, 0),
+			Secure:  false,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, redeemResponse)
+}
+
+func (h *RoomHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	participant, err := participantFromHeaders(r, h.rolesHeader)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	err = h.roomUC.RevokeInvite(participant, mux.Vars(r)["inviteID"])
+	if errors.Is(err, entity.ErrNoInvite) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Println("revoke invite err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrInviteForbidden) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Println("revoke invite err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("revoke invite err", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// participantFromHeaders reads the identity Check put on the request. Account
+// roles come from the rolesHeader it overwrites with the token's roles.
+func participantFromHeaders(r *http.Request, rolesHeader string) (*entity.Participant, error) {
+	if r.Header.Get("X-Guest") == "true" {
+		guestID := r.Header.Get("X-Guest-ID")
+		if guestID == "" {
+			return nil, errors.New("missing guest id")
+		}
+
+		return &entity.Participant{
+			Identity: entity.GuestIdentity(guestID),
+			Name:     r.Header.Get("X-Username"),
+			Roles:    []string{},
+			Guest:    true,
+			RoomID:   r.Header.Get("X-Room-ID"),
+		}, nil
+	}
+
+	userIDString := r.Header.Get("X-User-ID")
+	userID64, err := strconv.ParseUint(userIDString, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Participant{
+		Identity: entity.UserIdentity(userIDString),
+		Name:     r.Header.Get("X-Username"),
+		UserID:   uint(userID64),
+		Roles:    splitRoles(r.Header.Get(rolesHeader)),
+	}, nil
+}
+
+func accessTokenFromRequest(r *http.Request) string {
+	if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, "Bearer ") {
+		return strings.TrimPrefix(authorization, "Bearer ")
+	}
+
+	if accessCookie, err := r.Cookie("access_token"); err == nil {
+		return accessCookie.Value
+	}
+
+	return ""
+}
+
+func splitRoles(header string) []string {
+	roles := []string{}
+	for _, role := range strings.Split(header, ",") {
+		role = strings.TrimSpace(role)
+		if role != "" {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("marshal err", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	forwardAuthEntity "github.com/lightlink/auth-service/internal/forwardauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/room/domain/dto"
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+	roomRepo "github.com/lightlink/auth-service/internal/room/repository/redis"
+	"github.com/lightlink/auth-service/internal/room/usecase"
+	sessionDelivery "github.com/lightlink/auth-service/internal/session/delivery/http"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	"github.com/lightlink/auth-service/internal/testutil"
+	"github.com/lightlink/auth-service/internal/token/signer"
+)
+
+const testRolesHeader = "X-User-Roles"
+
+type fakeSessionUsecase struct {
+	sessionUsecase.SessionUsecaseI
+	identities map[string]*sessionEntity.Identity
+}
+
+func (f *fakeSessionUsecase) ValidateAccessToken(tokenString string) (*sessionEntity.Identity, error) {
+	identity, ok := f.identities[tokenString]
+	if !ok {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	return identity, nil
+}
+
+type testEnv struct {
+	check    *sessionDelivery.SessionHandler
+	router   *mux.Router
+	inviteID string
+}
+
+// newTestEnv puts the room handlers behind Check the way the gateway does,
+// with alice hosting room-1 and its one invite, and carol an account admin.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	base := testutil.NewEnv(t)
+	sessions := &fakeSessionUsecase{
+		identities: map[string]*sessionEntity.Identity{
+			"alice-access": {UserID: 1, Username: "alice", SessionID: "sid", Roles: []string{}},
+			"bob-access":   {UserID: 2, Username: "bob", SessionID: "sid", Roles: []string{}},
+			"carol-access": {UserID: 3, Username: "carol", SessionID: "sid", Roles: []string{"admin"}},
+		},
+	}
+
+	roleRepository := roomRepo.NewRoleRedisRepository(base.Dial())
+	err := roleRepository.Set("room-1", entity.UserIdentity("1"), entity.RoleHost)
+	if err != nil {
+		t.Fatalf("seed host: %v", err)
+	}
+
+	roleUC := usecase.NewRoleUsecase(roleRepository)
+	roleHandler := NewRoleHandler(roleUC, testRolesHeader)
+
+	roomUC := usecase.NewRoomUsecase(
+		signer.NewHMACSigner("media-key", []byte("media secret")),
+		0,
+		roleUC,
+		roomRepo.NewInviteRedisRepository(base.Dial()),
+		base.Signer,
+		time.Hour,
+		sessions,
+		"https://meet.example.com/join",
+	)
+	roomHandler := NewRoomHandler(roomUC, testRolesHeader)
+
+	host := &entity.Participant{Identity: entity.UserIdentity("1"), Name: "alice", UserID: 1, Roles: []string{}}
+	invite, err := roomUC.CreateInvite(host, &dto.CreateInviteRequest{RoomID: "room-1"})
+	if err != nil {
+		t.Fatalf("seed invite: %v", err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/rooms/{roomID}/roles", roleHandler.ListRoles).Methods("GET")
+	router.HandleFunc("/api/rooms/{roomID}/roles/{identity}", roleHandler.GrantRole).Methods("PUT")
+	router.HandleFunc("/api/rooms/{roomID}/roles/{identity}", roleHandler.RevokeRole).Methods("DELETE")
+	router.HandleFunc("/api/rooms/invites", roomHandler.CreateInvite).Methods("POST")
+	router.HandleFunc("/api/rooms/invites/{inviteID}", roomHandler.RevokeInvite).Methods("DELETE")
+
+	return &testEnv{
+		check:    sessionDelivery.NewSessionHandler(sessions, roleUC, &forwardAuthEntity.Config{RolesHeader: testRolesHeader}),
+		router:   router,
+		inviteID: invite.ID,
+	}
+}
+
+// serve runs Check first and, like the gateway, copies the identity headers
+// it answered with onto the request over whatever the client sent.
+func (env *testEnv) serve(t *testing.T, request *http.Request) int {
+	t.Helper()
+
+	checkRecorder := httptest.NewRecorder()
+	env.check.Check(checkRecorder, request)
+	if checkRecorder.Code != http.StatusOK {
+		return checkRecorder.Code
+	}
+
+	for name, values := range checkRecorder.Header() {
+		request.Header[name] = values
+	}
+
+	recorder := httptest.NewRecorder()
+	env.router.ServeHTTP(recorder, request)
+
+	return recorder.Code
+}
+
+func newRequest(method string, target string, body string, token string, roles string) *http.Request {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	if roles != "" {
+		request.Header.Set(testRolesHeader, roles)
+	}
+
+	return request
+}
+
+func TestForgedRolesHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		token      string
+		wantStatus int
+	}{
+		{"list roles", http.MethodGet, "/api/rooms/room-1/roles", "", "bob-access", http.StatusForbidden},
+		{"grant co-host", http.MethodPut, "/api/rooms/room-1/roles/user:2", `{"role":"co-host"}`, "bob-access", http.StatusForbidden},
+		{"demote host", http.MethodDelete, "/api/rooms/room-1/roles/user:1", "", "bob-access", http.StatusForbidden},
+		{"admin from token", http.MethodPut, "/api/rooms/room-1/roles/user:2", `{"role":"co-host"}`, "carol-access", http.StatusNoContent},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			status := env.serve(t, newRequest(test.method, test.target, test.body, test.token, "admin"))
+			if status != test.wantStatus {
+				t.Errorf("status = %d, want %d", status, test.wantStatus)
+			}
+		})
+	}
+}
+
+func TestForgedRolesHeaderOnInvites(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		token      string
+		wantStatus int
+	}{
+		{"create invite", http.MethodPost, "/api/rooms/invites", `{"room_id":"room-1"}`, "bob-access", http.StatusForbidden},
+		{"revoke invite", http.MethodDelete, "/api/rooms/invites/", "", "bob-access", http.StatusForbidden},
+		{"create invite as admin", http.MethodPost, "/api/rooms/invites", `{"room_id":"room-1"}`, "carol-access", http.StatusCreated},
+		{"revoke invite as admin", http.MethodDelete, "/api/rooms/invites/", "", "carol-access", http.StatusNoContent},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			target := test.target
+			if test.method == http.MethodDelete {
+				target += env.inviteID
+			}
+
+			status := env.serve(t, newRequest(test.method, target, test.body, test.token, "admin"))
+			if status != test.wantStatus {
+				t.Errorf("status = %d, want %d", status, test.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/lightlink/auth-service/internal/room/domain/dto"
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+	"github.com/lightlink/auth-service/internal/room/usecase"
+)
+
+type RoleHandler struct {
+	roleUC      usecase.RoleUsecaseI
+	rolesHeader string
+}
+
+func NewRoleHandler(roleUsecase usecase.RoleUsecaseI, rolesHeader string) *RoleHandler {
+	return &RoleHandler{
+		roleUC:      roleUsecase,
+		rolesHeader: rolesHeader,
+	}
+}
+
+func (h *RoleHandler) CreateRoom(w http.ResponseWriter, r *http.Request) {
+	creator, err := participantFromHeaders(r, h.rolesHeader)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	roomID, err := h.roleUC.CreateRoom(creator)
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, &dto.CreateRoomResponse{
+		RoomID: roomID,
+		Role:   entity.RoleHost,
+	})
+}
+
+func (h *RoleHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	actor, err := participantFromHeaders(r, h.rolesHeader)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	roomID := mux.Vars(r)["roomID"]
+	roles, err := h.roleUC.ListRoles(actor, roomID)
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &dto.RoomRolesResponse{
+		RoomID: roomID,
+		Roles:  roles,
+	})
+}
+
+func (h *RoleHandler) GrantRole(w http.ResponseWriter, r *http.Request) {
+	actor, err := participantFromHeaders(r, h.rolesHeader)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	grantRequest := &dto.GrantRoleRequest{}
+	err = json.Unmarshal(body, grantRequest)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	vars := mux.Vars(r)
+	err = h.roleUC.GrantRole(actor, vars["roomID"], vars["identity"], grantRequest.Role)
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RoleHandler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	actor, err := participantFromHeaders(r, h.rolesHeader)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println(err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	err = h.roleUC.RevokeRole(actor, vars["roomID"], vars["identity"])
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeRoleError(w http.ResponseWriter, err error) {
+	fmt.Println("room role err", err)
+
+	switch {
+	case errors.Is(err, entity.ErrInvalidRoomID),
+		errors.Is(err, entity.ErrInvalidRole),
+		errors.Is(err, entity.ErrInvalidIdentity):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, entity.ErrRoleForbidden):
+		w.WriteHeader(http.StatusForbidden)
+	case errors.Is(err, entity.ErrLastHost),
+		errors.Is(err, entity.ErrRoomExists):
+		w.WriteHeader(http.StatusConflict)
+	default:
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
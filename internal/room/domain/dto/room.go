@@ -0,0 +1,113 @@
+package dto
+
+import (
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+	"github.com/lightlink/auth-service/internal/room/domain/model"
+)
+
+type JoinTokenRequest struct {
+	RoomID string         `json:"room_id"`
+	Grants *entity.Grants `json:"grants,omitempty"`
+}
+
+type JoinTokenResponse struct {
+	Token     string        `json:"token"`
+	RoomID    string        `json:"room_id"`
+	Identity  string        `json:"identity"`
+	Role      string        `json:"role"`
+	Grants    entity.Grants `json:"grants"`
+	ExpiresAt int64         `json:"expires_at"`
+}
+
+func JoinTokenEntityToResponse(joinToken *entity.JoinToken) *JoinTokenResponse {
+	return &JoinTokenResponse{
+		Token:     joinToken.Token,
+		RoomID:    joinToken.RoomID,
+		Identity:  joinToken.Identity,
+		Role:      joinToken.Role,
+		Grants:    joinToken.Grants,
+		ExpiresAt: joinToken.ExpiresAt.Unix(),
+	}
+}
+
+type CreateInviteRequest struct {
+	RoomID    string `json:"room_id"`
+	ExpiresIn int64  `json:"expires_in"`
+	MaxUses   int    `json:"max_uses"`
+	Role      string `json:"role,omitempty"`
+}
+
+type InviteResponse struct {
+	ID        string `json:"invite_id"`
+	Token     string `json:"token"`
+	URL       string `json:"url,omitempty"`
+	RoomID    string `json:"room_id"`
+	Role      string `json:"role,omitempty"`
+	MaxUses   int    `json:"max_uses"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+type RedeemInviteRequest struct {
+	Token       string `json:"token"`
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+type RedeemInviteResponse struct {
+	JoinToken       *JoinTokenResponse `json:"join_token"`
+	AccessToken     string             `json:"access_token,omitempty"`
+	AccessExpiresAt int64              `json:"access_expires_at,omitempty"`
+	GuestID         string             `json:"guest_id,omitempty"`
+	Uses            int                `json:"uses"`
+}
+
+func InviteEntityToModel(inviteEntity *entity.Invite) *model.Invite {
+	return &model.Invite{
+		ID:        inviteEntity.ID,
+		CreatorID: inviteEntity.CreatorID,
+		RoomID:    inviteEntity.RoomID,
+		Role:      inviteEntity.Role,
+		MaxUses:   inviteEntity.MaxUses,
+		Uses:      inviteEntity.Uses,
+		CreatedAt: inviteEntity.CreatedAt,
+		ExpiresAt: inviteEntity.ExpiresAt,
+	}
+}
+
+func InviteModelToEntity(inviteModel *model.Invite) *entity.Invite {
+	return &entity.Invite{
+		ID:        inviteModel.ID,
+		CreatorID: inviteModel.CreatorID,
+		RoomID:    inviteModel.RoomID,
+		Role:      inviteModel.Role,
+		MaxUses:   inviteModel.MaxUses,
+		Uses:      inviteModel.Uses,
+		CreatedAt: inviteModel.CreatedAt,
+		ExpiresAt: inviteModel.ExpiresAt,
+	}
+}
+
+func InviteEntityToResponse(inviteEntity *entity.Invite) *InviteResponse {
+	return &InviteResponse{
+		ID:        inviteEntity.ID,
+		Token:     inviteEntity.Token,
+		URL:       inviteEntity.URL,
+		RoomID:    inviteEntity.RoomID,
+		Role:      inviteEntity.Role,
+		MaxUses:   inviteEntity.MaxUses,
+		ExpiresAt: inviteEntity.ExpiresAt.Unix(),
+	}
+}
+
+type CreateRoomResponse struct {
+	RoomID string `json:"room_id"`
+	Role   string `json:"role"`
+}
+
+type GrantRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type RoomRolesResponse struct {
+	RoomID string            `json:"room_id"`
+	Roles  map[string]string `json:"roles"`
+}
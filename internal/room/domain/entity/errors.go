@@ -0,0 +1,22 @@
+package entity
+
+import "errors"
+
+var (
+	ErrInvalidRoomID = errors.New("room id is invalid")
+	ErrNoMediaKey    = errors.New("media server key is not configured")
+	ErrRoomForbidden = errors.New("participant may not join this room")
+	ErrRoomExists    = errors.New("room already exists")
+
+	ErrNoInvite           = errors.New("couldn't find invite")
+	ErrInvalidInvite      = errors.New("invite is invalid")
+	ErrInviteExhausted    = errors.New("invite has no uses left")
+	ErrInviteForbidden    = errors.New("participant may not manage invites to this room")
+	ErrInviteRoleRequired = errors.New("invite requires a role the participant lacks")
+	ErrInvalidDisplayName = errors.New("display name is invalid")
+
+	ErrInvalidRole     = errors.New("meeting role is invalid")
+	ErrInvalidIdentity = errors.New("participant identity is invalid")
+	ErrRoleForbidden   = errors.New("participant may not manage this role")
+	ErrLastHost        = errors.New("meeting must keep at least one host")
+)
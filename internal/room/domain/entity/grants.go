@@ -0,0 +1,21 @@
+package entity
+
+type Grants struct {
+	CanPublish     bool `json:"canPublish"`
+	CanSubscribe   bool `json:"canSubscribe"`
+	CanPublishData bool `json:"canPublishData"`
+	CanShareScreen bool `json:"canShareScreen"`
+	IsModerator    bool `json:"isModerator"`
+}
+
+// Intersect keeps only the grants present in both sets, so a participant can
+// ask for less than they are allowed but never for more.
+func (g Grants) Intersect(other Grants) Grants {
+	return Grants{
+		CanPublish:     g.CanPublish && other.CanPublish,
+		CanSubscribe:   g.CanSubscribe && other.CanSubscribe,
+		CanPublishData: g.CanPublishData && other.CanPublishData,
+		CanShareScreen: g.CanShareScreen && other.CanShareScreen,
+		IsModerator:    g.IsModerator && other.IsModerator,
+	}
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// Invite lets people into a room without being added to it one by one. A zero
+// MaxUses means the invite can be redeemed until it expires.
+type Invite struct {
+	ID        string
+	Token     string
+	URL       string
+	CreatorID uint
+	RoomID    string
+	Role      string
+	MaxUses   int
+	Uses      int
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+type JoinToken struct {
+	Token     string
+	RoomID    string
+	Identity  string
+	Role      string
+	Grants    Grants
+	ExpiresAt time.Time
+}
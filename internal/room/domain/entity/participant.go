@@ -0,0 +1,12 @@
+package entity
+
+// Participant describes who asks to join. A guest is confined to the one room
+// its session was issued for.
+type Participant struct {
+	Identity string
+	Name     string
+	UserID   uint
+	Roles    []string
+	Guest    bool
+	RoomID   string
+}
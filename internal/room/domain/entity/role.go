@@ -0,0 +1,73 @@
+package entity
+
+const (
+	RoleHost      = "host"
+	RoleCoHost    = "co-host"
+	RolePresenter = "presenter"
+	RoleAttendee  = "attendee"
+	RoleViewer    = "viewer"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer:    1,
+	RoleAttendee:  2,
+	RolePresenter: 3,
+	RoleCoHost:    4,
+	RoleHost:      5,
+}
+
+func IsMeetingRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleRank orders meeting roles from viewer up to host; unknown roles rank
+// below all of them.
+func RoleRank(role string) int {
+	return roleRanks[role]
+}
+
+// IsModeratorRole reports whether the role may run the meeting: manage other
+// participants' roles and moderate them in the SFU and chat.
+func IsModeratorRole(role string) bool {
+	return role == RoleHost || role == RoleCoHost
+}
+
+// RoleGrants is the single place meeting roles turn into media permissions.
+func RoleGrants(role string) Grants {
+	switch role {
+	case RoleHost, RoleCoHost:
+		return Grants{
+			CanPublish:     true,
+			CanSubscribe:   true,
+			CanPublishData: true,
+			CanShareScreen: true,
+			IsModerator:    true,
+		}
+	case RolePresenter:
+		return Grants{
+			CanPublish:     true,
+			CanSubscribe:   true,
+			CanPublishData: true,
+			CanShareScreen: true,
+		}
+	case RoleAttendee:
+		return Grants{
+			CanPublish:     true,
+			CanSubscribe:   true,
+			CanPublishData: true,
+		}
+	}
+
+	return Grants{
+		CanSubscribe: true,
+	}
+}
+
+func UserIdentity(userID string) string {
+	return "user:" + userID
+}
+
+func GuestIdentity(guestID string) string {
+	return "guest:" + guestID
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type Invite struct {
+	ID        string    `json:"id"`
+	CreatorID uint      `json:"creator_id"`
+	RoomID    string    `json:"room_id"`
+	Role      string    `json:"role,omitempty"`
+	MaxUses   int       `json:"max_uses"`
+	Uses      int       `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
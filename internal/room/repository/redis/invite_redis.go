@@ -0,0 +1,141 @@
+package redis
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/room/domain/dto"
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+	"github.com/lightlink/auth-service/internal/room/domain/model"
+)
+
+type InviteRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewInviteRedisRepository(conn redis.Conn) *InviteRedisRepository {
+	return &InviteRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+// The use counter is checked and bumped in one script, so concurrent
+// redemptions can never push an invite past its limit.
+var redeemScript = redis.NewScript(2, `
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return -1
+end
+local invite = cjson.decode(current)
+local maxUses = tonumber(invite['max_uses']) or 0
+local uses = tonumber(redis.call('GET', KEYS[2]) or '0')
+if maxUses > 0 and uses >= maxUses then
+	return -2
+end
+return redis.call('INCR', KEYS[2])
+`)
+
+func inviteKey(inviteID string) string {
+	return "room_invites:" + inviteID
+}
+
+func inviteUsesKey(inviteID string) string {
+	return "room_invite_uses:" + inviteID
+}
+
+func (repo *InviteRedisRepository) Set(inviteEntity *entity.Invite) error {
+	inviteSerialized, err := json.Marshal(dto.InviteEntityToModel(inviteEntity))
+	if err != nil {
+		return err
+	}
+
+	ttl := int(time.Until(inviteEntity.ExpiresAt).Seconds())
+	if ttl <= 0 {
+		return entity.ErrInvalidInvite
+	}
+
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("SET", inviteKey(inviteEntity.ID), inviteSerialized, "EX", ttl)
+	repo.redisConn.Send("SET", inviteUsesKey(inviteEntity.ID), 0, "EX", ttl)
+	_, err = repo.redisConn.Do("EXEC")
+	repo.mu.Unlock()
+
+	return err
+}
+
+func (repo *InviteRedisRepository) Get(inviteID string) (*model.Invite, error) {
+	repo.mu.Lock()
+	values, err := redis.Values(repo.redisConn.Do("MGET", inviteKey(inviteID), inviteUsesKey(inviteID)))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != 2 || values[0] == nil {
+		return nil, entity.ErrNoInvite
+	}
+
+	bytes, err := redis.Bytes(values[0], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	inviteModel := &model.Invite{}
+	err = json.Unmarshal(bytes, inviteModel)
+	if err != nil {
+		return nil, err
+	}
+
+	if values[1] != nil {
+		inviteModel.Uses, err = redis.Int(values[1], nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return inviteModel, nil
+}
+
+func (repo *InviteRedisRepository) Redeem(inviteID string) (int, error) {
+	repo.mu.Lock()
+	result, err := redis.Int(redeemScript.Do(
+		repo.redisConn,
+		inviteKey(inviteID), inviteUsesKey(inviteID),
+	))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return 0, err
+	}
+
+	switch result {
+	case -1:
+		return 0, entity.ErrNoInvite
+	case -2:
+		return 0, entity.ErrInviteExhausted
+	}
+
+	return result, nil
+}
+
+func (repo *InviteRedisRepository) Delete(inviteID string) error {
+	repo.mu.Lock()
+	deleted, err := redis.Int(repo.redisConn.Do("DEL", inviteKey(inviteID), inviteUsesKey(inviteID)))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return entity.ErrNoInvite
+	}
+
+	return nil
+}
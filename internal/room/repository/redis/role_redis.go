@@ -0,0 +1,125 @@
+package redis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+)
+
+type RoleRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewRoleRedisRepository(conn redis.Conn) *RoleRedisRepository {
+	return &RoleRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+// Roles outlive the meeting itself, so every write pushes the room's expiry
+// forward and rooms nobody touches for this long are forgotten.
+const roomRolesTTL = 30 * 24 * time.Hour
+
+// A new room goes to its creator; the check and the write happen in one script
+// so the creator can't take over a room that already has roles.
+var claimHostScript = redis.NewScript(1, `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+return 1
+`)
+
+// Every role change goes through one script that refuses to take the host
+// role from the room's last host, so two hosts demoting each other at once
+// can't leave the room without one. An empty role removes the participant's.
+var changeRoleScript = redis.NewScript(1, `
+if ARGV[2] ~= ARGV[3] and redis.call('HGET', KEYS[1], ARGV[1]) == ARGV[3] then
+	local roles = redis.call('HGETALL', KEYS[1])
+	local otherHost = false
+	for i = 1, #roles, 2 do
+		if roles[i] ~= ARGV[1] and roles[i + 1] == ARGV[3] then
+			otherHost = true
+			break
+		end
+	end
+	if not otherHost then
+		return 0
+	end
+end
+if ARGV[2] == '' then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+	return 1
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('EXPIRE', KEYS[1], ARGV[4])
+return 1
+`)
+
+func roomRolesKey(roomID string) string {
+	return "room_roles:" + roomID
+}
+
+// Get returns an empty role for participants nobody granted one to.
+func (repo *RoleRedisRepository) Get(roomID string, identity string) (string, error) {
+	repo.mu.Lock()
+	role, err := redis.String(repo.redisConn.Do("HGET", roomRolesKey(roomID), identity))
+	repo.mu.Unlock()
+
+	if err == redis.ErrNil {
+		return "", nil
+	}
+
+	return role, err
+}
+
+func (repo *RoleRedisRepository) List(roomID string) (map[string]string, error) {
+	repo.mu.Lock()
+	roles, err := redis.StringMap(repo.redisConn.Do("HGETALL", roomRolesKey(roomID)))
+	repo.mu.Unlock()
+
+	return roles, err
+}
+
+// Set fails with ErrLastHost rather than demote the room's only host.
+func (repo *RoleRedisRepository) Set(roomID string, identity string, role string) error {
+	return repo.changeRole(roomID, identity, role)
+}
+
+// Delete fails with ErrLastHost rather than remove the room's only host.
+func (repo *RoleRedisRepository) Delete(roomID string, identity string) error {
+	return repo.changeRole(roomID, identity, "")
+}
+
+func (repo *RoleRedisRepository) changeRole(roomID string, identity string, role string) error {
+	repo.mu.Lock()
+	changed, err := redis.Bool(changeRoleScript.Do(
+		repo.redisConn,
+		roomRolesKey(roomID),
+		identity, role, entity.RoleHost, int(roomRolesTTL.Seconds()),
+	))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		return entity.ErrLastHost
+	}
+
+	return nil
+}
+
+func (repo *RoleRedisRepository) ClaimHost(roomID string, identity string) (bool, error) {
+	repo.mu.Lock()
+	claimed, err := redis.Bool(claimHostScript.Do(repo.redisConn, roomRolesKey(roomID), identity, entity.RoleHost, int(roomRolesTTL.Seconds())))
+	repo.mu.Unlock()
+
+	return claimed, err
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+	"github.com/lightlink/auth-service/internal/room/domain/model"
+)
+
+type InviteRepositoryI interface {
+	Set(inviteEntity *entity.Invite) error
+	Get(inviteID string) (*model.Invite, error)
+	Redeem(inviteID string) (int, error)
+	Delete(inviteID string) error
+}
+
+type RoleRepositoryI interface {
+	Get(roomID string, identity string) (string, error)
+	List(roomID string) (map[string]string, error)
+	Set(roomID string, identity string, role string) error
+	Delete(roomID string, identity string) error
+	ClaimHost(roomID string, identity string) (bool, error)
+}
@@ -0,0 +1,298 @@
+package usecase
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/room/domain/dto"
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+)
+
+const (
+	defaultInviteTTL = 24 * time.Hour
+	maxInviteTTL     = 7 * 24 * time.Hour
+
+	tokenTypeInvite = "invite"
+)
+
+// CreateInvite signs an invite token for the room. The token carries the
+// invite terms so forged or altered links are rejected without a lookup, while
+// the stored copy holds the use counter and lets the room's hosts revoke it.
+// Only the room's host may invite people into it.
+func (uc *RoomUsecase) CreateInvite(creator *entity.Participant, inviteRequest *dto.CreateInviteRequest) (*entity.Invite, error) {
+	err := uc.authorizeInvites(creator, inviteRequest.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	role := strings.TrimSpace(inviteRequest.Role)
+	if role != "" && !entity.IsMeetingRole(role) {
+		return nil, entity.ErrInvalidRole
+	}
+
+	ttl := time.Duration(inviteRequest.ExpiresIn) * time.Second
+	if inviteRequest.ExpiresIn == 0 {
+		ttl = defaultInviteTTL
+		if ttl > uc.inviteMaxTTL {
+			ttl = uc.inviteMaxTTL
+		}
+	}
+
+	if ttl <= 0 || ttl > uc.inviteMaxTTL || inviteRequest.MaxUses < 0 {
+		return nil, entity.ErrInvalidInvite
+	}
+
+	inviteID, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invite := &entity.Invite{
+		ID:        inviteID,
+		CreatorID: creator.UserID,
+		RoomID:    inviteRequest.RoomID,
+		Role:      role,
+		MaxUses:   inviteRequest.MaxUses,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	invite.Token, err = uc.inviteSigner.Sign(jwt.MapClaims{
+		"typ":      tokenTypeInvite,
+		"jti":      invite.ID,
+		"sub":      strconv.Itoa(int(invite.CreatorID)),
+		"room":     invite.RoomID,
+		"role":     invite.Role,
+		"max_uses": invite.MaxUses,
+		"iat":      now.Unix(),
+		"exp":      invite.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.inviteRepo.Set(invite)
+	if err != nil {
+		return nil, err
+	}
+
+	invite.URL = uc.inviteLink(invite.Token)
+
+	return invite, nil
+}
+
+// RedeemInvite admits the caller to the invited room. A logged-in user joins
+// under their own identity; anyone else becomes a guest of that room.
+func (uc *RoomUsecase) RedeemInvite(redeemRequest *dto.RedeemInviteRequest, accessToken string) (*dto.RedeemInviteResponse, error) {
+	invite, err := uc.verifyInvite(redeemRequest.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	participant := uc.loggedInParticipant(accessToken, invite.RoomID)
+	displayName := strings.TrimSpace(redeemRequest.DisplayName)
+	if participant == nil && displayName == "" {
+		return nil, entity.ErrInvalidDisplayName
+	}
+
+	if invite.Role != "" {
+		err = uc.requireMeetingRole(participant, invite)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	uses, err := uc.inviteRepo.Redeem(invite.ID)
+	if err == entity.ErrNoInvite {
+		return nil, entity.ErrInvalidInvite
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	redeemResponse := &dto.RedeemInviteResponse{
+		Uses: uses,
+	}
+
+	if participant == nil {
+		guestSession, err := uc.sessionUC.CreateGuestSession(displayName, invite.RoomID)
+		if errors.Is(err, sessionEntity.ErrInvalidDisplayName) {
+			return nil, entity.ErrInvalidDisplayName
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		participant = &entity.Participant{
+			Identity: entity.GuestIdentity(guestSession.GuestID),
+			Name:     guestSession.Username,
+			Roles:    []string{},
+			Guest:    true,
+			RoomID:   guestSession.RoomID,
+		}
+		redeemResponse.AccessToken = guestSession.JWTAccess
+		redeemResponse.AccessExpiresAt = guestSession.AccessExpiresAt.Unix()
+		redeemResponse.GuestID = guestSession.GuestID
+	}
+
+	joinToken, err := uc.joinToken(invite.RoomID, participant, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	redeemResponse.JoinToken = dto.JoinTokenEntityToResponse(joinToken)
+
+	return redeemResponse, nil
+}
+
+// RevokeInvite is up to whoever hosts the room now, so an invite outlives
+// neither its creator's demotion nor a hand-over to another host.
+func (uc *RoomUsecase) RevokeInvite(revoker *entity.Participant, inviteID string) error {
+	inviteModel, err := uc.inviteRepo.Get(inviteID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.authorizeInvites(revoker, inviteModel.RoomID)
+	if err != nil {
+		return err
+	}
+
+	return uc.inviteRepo.Delete(inviteID)
+}
+
+// authorizeInvites lets the room's hosts and admins manage its invites.
+func (uc *RoomUsecase) authorizeInvites(participant *entity.Participant, roomID string) error {
+	if participant.Guest || participant.UserID == 0 {
+		return entity.ErrInviteForbidden
+	}
+
+	role, err := uc.roleUC.GetRole(participant, roomID)
+	if err != nil {
+		return err
+	}
+
+	if role != entity.RoleHost && !isAdmin(participant) {
+		return entity.ErrInviteForbidden
+	}
+
+	return nil
+}
+
+func (uc *RoomUsecase) verifyInvite(tokenString string) (*entity.Invite, error) {
+	token, err := jwt.Parse(tokenString, uc.inviteSigner.Keyfunc)
+	if err != nil || !token.Valid {
+		return nil, entity.ErrInvalidInvite
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != tokenTypeInvite {
+		return nil, entity.ErrInvalidInvite
+	}
+
+	inviteID, _ := claims["jti"].(string)
+	roomID, _ := claims["room"].(string)
+	if inviteID == "" || roomID == "" {
+		return nil, entity.ErrInvalidInvite
+	}
+
+	inviteModel, err := uc.inviteRepo.Get(inviteID)
+	if err == entity.ErrNoInvite {
+		return nil, entity.ErrInvalidInvite
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if inviteModel.RoomID != roomID {
+		return nil, entity.ErrInvalidInvite
+	}
+
+	return dto.InviteModelToEntity(inviteModel), nil
+}
+
+// requireMeetingRole checks the invite's role requirement against the role the
+// participant already holds in that meeting. Someone joining as a new guest
+// holds no role there yet and is measured against the default.
+func (uc *RoomUsecase) requireMeetingRole(participant *entity.Participant, invite *entity.Invite) error {
+	meetingRole := defaultMeetingRole
+	if participant != nil {
+		var err error
+		meetingRole, err = uc.roleUC.GetRole(participant, invite.RoomID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if entity.RoleRank(meetingRole) < entity.RoleRank(invite.Role) {
+		return entity.ErrInviteRoleRequired
+	}
+
+	return nil
+}
+
+// loggedInParticipant returns nil when the caller should join as a new guest,
+// which includes guests whose session belongs to a different room.
+func (uc *RoomUsecase) loggedInParticipant(accessToken string, roomID string) *entity.Participant {
+	if accessToken == "" {
+		return nil
+	}
+
+	identity, err := uc.sessionUC.ValidateAccessToken(accessToken)
+	if err != nil || identity.Service || identity.ClientID != "" || identity.Restricted {
+		return nil
+	}
+
+	if identity.Guest {
+		if identity.RoomID != roomID {
+			return nil
+		}
+
+		return &entity.Participant{
+			Identity: entity.GuestIdentity(identity.GuestID),
+			Name:     identity.Username,
+			Roles:    []string{},
+			Guest:    true,
+			RoomID:   identity.RoomID,
+		}
+	}
+
+	return &entity.Participant{
+		Identity: entity.UserIdentity(strconv.Itoa(int(identity.UserID))),
+		Name:     identity.Username,
+		UserID:   identity.UserID,
+		Roles:    identity.Roles,
+	}
+}
+
+func (uc *RoomUsecase) inviteLink(token string) string {
+	if uc.inviteURL == "" {
+		return ""
+	}
+
+	separator := "?"
+	if strings.Contains(uc.inviteURL, "?") {
+		separator = "&"
+	}
+
+	return uc.inviteURL + separator + "invite=" + url.QueryEscape(token)
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, candidate := range roles {
+		if candidate == role {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lightlink/auth-service/internal/room/domain/dto"
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+)
+
+func TestCreateInvite(t *testing.T) {
+	tests := []struct {
+		name    string
+		creator *entity.Participant
+		request *dto.CreateInviteRequest
+		wantTTL time.Duration
+		wantErr error
+	}{
+		{"host", testHost, &dto.CreateInviteRequest{RoomID: "room-1"}, time.Hour, nil},
+		{"admin", testAdmin, &dto.CreateInviteRequest{RoomID: "room-1", ExpiresIn: 600}, 10 * time.Minute, nil},
+		{"meeting role", testHost, &dto.CreateInviteRequest{RoomID: "room-1", Role: entity.RolePresenter}, time.Hour, nil},
+		{"attendee", testAttendee, &dto.CreateInviteRequest{RoomID: "room-1"}, 0, entity.ErrInviteForbidden},
+		{"host of another room", testHost, &dto.CreateInviteRequest{RoomID: "room-2"}, 0, entity.ErrInviteForbidden},
+		{"guest", &entity.Participant{Identity: "guest:known", Guest: true, RoomID: "room-1"}, &dto.CreateInviteRequest{RoomID: "room-1"}, 0, entity.ErrInviteForbidden},
+		{"account role", testHost, &dto.CreateInviteRequest{RoomID: "room-1", Role: adminRole}, 0, entity.ErrInvalidRole},
+		{"beyond key retention", testHost, &dto.CreateInviteRequest{RoomID: "room-1", ExpiresIn: 2 * 3600}, 0, entity.ErrInvalidInvite},
+		{"negative uses", testHost, &dto.CreateInviteRequest{RoomID: "room-1", MaxUses: -1}, 0, entity.ErrInvalidInvite},
+		{"invalid room", testHost, &dto.CreateInviteRequest{RoomID: "room 1"}, 0, entity.ErrInvalidRoomID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			before := time.Now()
+			invite, err := env.uc.CreateInvite(tt.creator, tt.request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateInvite err = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			ttl := invite.ExpiresAt.Sub(before)
+			if ttl < tt.wantTTL-time.Second || ttl > tt.wantTTL+time.Second {
+				t.Errorf("invite lives %v, want %v", ttl, tt.wantTTL)
+			}
+
+			if invite.URL == "" || invite.Token == "" {
+				t.Errorf("invite URL %q token %q, want both set", invite.URL, invite.Token)
+			}
+		})
+	}
+}
+
+func TestRedeemInvite(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        string
+		accessToken string
+		displayName string
+		wantGuest   bool
+		wantErr     error
+	}{
+		{"new guest", "", "", "Visitor", true, nil},
+		{"guest without a name", "", "", "", false, entity.ErrInvalidDisplayName},
+		{"signed-in user", "", "bob-access", "", false, nil},
+		{"guest of the room", "", "room-1-guest", "", false, nil},
+		{"guest of another room", "", "other-room-guest", "Visitor", true, nil},
+		{"restricted account", "", "restricted", "Visitor", true, nil},
+		{"delegated token", "", "delegated", "Visitor", true, nil},
+		{"holds the meeting role", entity.RolePresenter, "erin-access", "", false, nil},
+		{"lacks the meeting role", entity.RolePresenter, "bob-access", "", false, entity.ErrInviteRoleRequired},
+		{"new guest below the role", entity.RolePresenter, "", "Visitor", false, entity.ErrInviteRoleRequired},
+		{"default role", entity.RoleAttendee, "", "Visitor", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			invite, err := env.uc.CreateInvite(testHost, &dto.CreateInviteRequest{RoomID: "room-1", Role: tt.role})
+			if err != nil {
+				t.Fatalf("CreateInvite: %v", err)
+			}
+
+			redeemed, err := env.uc.RedeemInvite(&dto.RedeemInviteRequest{
+				Token:       invite.Token,
+				DisplayName: tt.displayName,
+			}, tt.accessToken)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RedeemInvite err = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if (redeemed.GuestID != "") != tt.wantGuest || (redeemed.AccessToken != "") != tt.wantGuest {
+				t.Errorf("guest id %q access token %q, want a new guest: %v", redeemed.GuestID, redeemed.AccessToken, tt.wantGuest)
+			}
+
+			claims := env.parseJoinToken(t, redeemed.JoinToken.Token)
+			if claims["room"] != "room-1" {
+				t.Errorf("join token room = %v, want room-1", claims["room"])
+			}
+		})
+	}
+}
+
+func TestRedeemInviteUses(t *testing.T) {
+	env := newTestEnv(t)
+
+	invite, err := env.uc.CreateInvite(testHost, &dto.CreateInviteRequest{RoomID: "room-1", MaxUses: 2})
+	if err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+
+	redeemRequest := &dto.RedeemInviteRequest{Token: invite.Token, DisplayName: "Visitor"}
+	for want := 1; want <= 2; want++ {
+		redeemed, err := env.uc.RedeemInvite(redeemRequest, "")
+		if err != nil {
+			t.Fatalf("redeem %d: %v", want, err)
+		}
+
+		if redeemed.Uses != want {
+			t.Errorf("redeem %d counted %d uses", want, redeemed.Uses)
+		}
+	}
+
+	_, err = env.uc.RedeemInvite(redeemRequest, "")
+	if !errors.Is(err, entity.ErrInviteExhausted) {
+		t.Fatalf("third redeem err = %v, want %v", err, entity.ErrInviteExhausted)
+	}
+
+	// A refused role check must not spend a use.
+	limited, err := env.uc.CreateInvite(testHost, &dto.CreateInviteRequest{RoomID: "room-1", MaxUses: 1, Role: entity.RolePresenter})
+	if err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+
+	_, err = env.uc.RedeemInvite(&dto.RedeemInviteRequest{Token: limited.Token}, "bob-access")
+	if !errors.Is(err, entity.ErrInviteRoleRequired) {
+		t.Fatalf("redeem without the role err = %v, want %v", err, entity.ErrInviteRoleRequired)
+	}
+
+	_, err = env.uc.RedeemInvite(&dto.RedeemInviteRequest{Token: limited.Token}, "erin-access")
+	if err != nil {
+		t.Fatalf("redeem with the role: %v", err)
+	}
+}
+
+func TestRevokeInvite(t *testing.T) {
+	tests := []struct {
+		name    string
+		revoker *entity.Participant
+		wantErr error
+	}{
+		{"creator", testHost, nil},
+		{"admin", testAdmin, nil},
+		{"another host", testAttendee, nil},
+		{"attendee", &entity.Participant{Identity: "user:5", Name: "erin", UserID: 5, Roles: []string{}}, entity.ErrInviteForbidden},
+		{"guest", &entity.Participant{Identity: "guest:known", Guest: true, RoomID: "room-1", Roles: []string{adminRole}}, entity.ErrInviteForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			invite, err := env.uc.CreateInvite(testHost, &dto.CreateInviteRequest{RoomID: "room-1"})
+			if err != nil {
+				t.Fatalf("CreateInvite: %v", err)
+			}
+
+			// The creator hands the room over and steps down.
+			err = env.roleUC.GrantRole(testHost, "room-1", testAttendee.Identity, entity.RoleHost)
+			if err != nil {
+				t.Fatalf("GrantRole host: %v", err)
+			}
+
+			if tt.revoker != testHost {
+				err = env.roleUC.RevokeRole(testAttendee, "room-1", testHost.Identity)
+				if err != nil {
+					t.Fatalf("RevokeRole: %v", err)
+				}
+			}
+
+			err = env.uc.RevokeInvite(tt.revoker, invite.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RevokeInvite err = %v, want %v", err, tt.wantErr)
+			}
+
+			_, err = env.uc.RedeemInvite(&dto.RedeemInviteRequest{Token: invite.Token, DisplayName: "Visitor"}, "")
+			if tt.wantErr == nil && !errors.Is(err, entity.ErrInvalidInvite) {
+				t.Fatalf("redeem of a revoked invite err = %v, want %v", err, entity.ErrInvalidInvite)
+			}
+
+			if tt.wantErr != nil && err != nil {
+				t.Fatalf("redeem of a kept invite: %v", err)
+			}
+		})
+	}
+}
+
+func TestRevokeInviteByFormerHost(t *testing.T) {
+	env := newTestEnv(t)
+
+	invite, err := env.uc.CreateInvite(testHost, &dto.CreateInviteRequest{RoomID: "room-1"})
+	if err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+
+	err = env.roleUC.GrantRole(testHost, "room-1", testAttendee.Identity, entity.RoleHost)
+	if err != nil {
+		t.Fatalf("GrantRole host: %v", err)
+	}
+
+	err = env.roleUC.RevokeRole(testAttendee, "room-1", testHost.Identity)
+	if err != nil {
+		t.Fatalf("RevokeRole: %v", err)
+	}
+
+	err = env.uc.RevokeInvite(testHost, invite.ID)
+	if !errors.Is(err, entity.ErrInviteForbidden) {
+		t.Fatalf("RevokeInvite by a former host err = %v, want %v", err, entity.ErrInviteForbidden)
+	}
+}
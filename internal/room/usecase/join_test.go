@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lightlink/auth-service/internal/room/domain/dto"
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+)
+
+func TestCreateJoinToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		participant *entity.Participant
+		request     *dto.JoinTokenRequest
+		wantRole    string
+		wantGrants  entity.Grants
+		wantErr     error
+	}{
+		{"host", testHost, &dto.JoinTokenRequest{RoomID: "room-1"}, entity.RoleHost, entity.RoleGrants(entity.RoleHost), nil},
+		{"attendee", testAttendee, &dto.JoinTokenRequest{RoomID: "room-1"}, entity.RoleAttendee, entity.RoleGrants(entity.RoleAttendee), nil},
+		{
+			"fewer grants than the role allows",
+			testHost,
+			&dto.JoinTokenRequest{RoomID: "room-1", Grants: &entity.Grants{CanSubscribe: true}},
+			entity.RoleHost,
+			entity.Grants{CanSubscribe: true},
+			nil,
+		},
+		{
+			"more grants than the role allows",
+			testAttendee,
+			&dto.JoinTokenRequest{RoomID: "room-1", Grants: &entity.Grants{CanSubscribe: true, CanShareScreen: true, IsModerator: true}},
+			entity.RoleAttendee,
+			entity.Grants{CanSubscribe: true},
+			nil,
+		},
+		{
+			"admin moderates any room",
+			testAdmin,
+			&dto.JoinTokenRequest{RoomID: "room-1"},
+			entity.RoleAttendee,
+			entity.Grants{CanPublish: true, CanSubscribe: true, CanPublishData: true, IsModerator: true},
+			nil,
+		},
+		{
+			"guest of the room",
+			&entity.Participant{Identity: "guest:known", Name: "Guest", Guest: true, RoomID: "room-1"},
+			&dto.JoinTokenRequest{RoomID: "room-1"},
+			entity.RoleAttendee,
+			entity.RoleGrants(entity.RoleAttendee),
+			nil,
+		},
+		{
+			"guest of another room",
+			&entity.Participant{Identity: "guest:stray", Name: "Guest", Guest: true, RoomID: "room-2"},
+			&dto.JoinTokenRequest{RoomID: "room-1"},
+			"",
+			entity.Grants{},
+			entity.ErrRoomForbidden,
+		},
+		{"invalid room", testHost, &dto.JoinTokenRequest{RoomID: "../room"}, "", entity.Grants{}, entity.ErrInvalidRoomID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			joinToken, err := env.uc.CreateJoinToken(tt.participant, tt.request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateJoinToken err = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if joinToken.Role != tt.wantRole || joinToken.Grants != tt.wantGrants {
+				t.Errorf("role %q grants %+v, want %q %+v", joinToken.Role, joinToken.Grants, tt.wantRole, tt.wantGrants)
+			}
+
+			claims := env.parseJoinToken(t, joinToken.Token)
+			if claims["sub"] != tt.participant.Identity || claims["room"] != tt.request.RoomID || claims["role"] != tt.wantRole {
+				t.Errorf("claims sub %v room %v role %v, want %s %s %s", claims["sub"], claims["room"], claims["role"], tt.participant.Identity, tt.request.RoomID, tt.wantRole)
+			}
+
+			if claims["iss"] != "media-key" {
+				t.Errorf("iss = %v, want the media API key", claims["iss"])
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"regexp"
+
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+	roomRepo "github.com/lightlink/auth-service/internal/room/repository"
+)
+
+const defaultMeetingRole = entity.RoleAttendee
+
+var participantIdentityPattern = regexp.MustCompile(`^(user|guest):[A-Za-z0-9]+$`)
+
+type RoleUsecaseI interface {
+	GetRole(participant *entity.Participant, roomID string) (string, error)
+	CreateRoom(creator *entity.Participant) (string, error)
+	ListRoles(actor *entity.Participant, roomID string) (map[string]string, error)
+	GrantRole(actor *entity.Participant, roomID string, identity string, role string) error
+	RevokeRole(actor *entity.Participant, roomID string, identity string) error
+}
+
+type RoleUsecase struct {
+	roleRepo roomRepo.RoleRepositoryI
+}
+
+func NewRoleUsecase(roleRepository roomRepo.RoleRepositoryI) *RoleUsecase {
+	return &RoleUsecase{
+		roleRepo: roleRepository,
+	}
+}
+
+// GetRole returns the participant's role in the room, falling back to
+// attendee for anyone who was never granted one.
+func (uc *RoleUsecase) GetRole(participant *entity.Participant, roomID string) (string, error) {
+	if !roomIDPattern.MatchString(roomID) {
+		return "", entity.ErrInvalidRoomID
+	}
+
+	role, err := uc.roleRepo.Get(roomID, participant.Identity)
+	if err != nil {
+		return "", err
+	}
+
+	if role == "" {
+		return defaultMeetingRole, nil
+	}
+
+	return role, nil
+}
+
+// CreateRoom opens a room under a fresh ID with its creator as host. Hosts are
+// only ever assigned here, never to whoever happens to join an unknown room
+// first, so a room ID that leaks ahead of a meeting can't be taken over.
+func (uc *RoleUsecase) CreateRoom(creator *entity.Participant) (string, error) {
+	if creator.Guest || creator.UserID == 0 {
+		return "", entity.ErrRoleForbidden
+	}
+
+	roomID, err := generateID()
+	if err != nil {
+		return "", err
+	}
+
+	claimed, err := uc.roleRepo.ClaimHost(roomID, creator.Identity)
+	if err != nil {
+		return "", err
+	}
+
+	if !claimed {
+		return "", entity.ErrRoomExists
+	}
+
+	return roomID, nil
+}
+
+func (uc *RoleUsecase) ListRoles(actor *entity.Participant, roomID string) (map[string]string, error) {
+	actorRole, err := uc.GetRole(actor, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !entity.IsModeratorRole(actorRole) && !isAdmin(actor) {
+		return nil, entity.ErrRoleForbidden
+	}
+
+	return uc.roleRepo.List(roomID)
+}
+
+func (uc *RoleUsecase) GrantRole(actor *entity.Participant, roomID string, identity string, role string) error {
+	if !entity.IsMeetingRole(role) {
+		return entity.ErrInvalidRole
+	}
+
+	err := uc.authorizeChange(actor, roomID, identity, role)
+	if err != nil {
+		return err
+	}
+
+	// The repository refuses to demote the last host.
+	return uc.roleRepo.Set(roomID, identity, role)
+}
+
+// RevokeRole drops the participant back to the default role.
+func (uc *RoleUsecase) RevokeRole(actor *entity.Participant, roomID string, identity string) error {
+	err := uc.authorizeChange(actor, roomID, identity, defaultMeetingRole)
+	if err != nil {
+		return err
+	}
+
+	return uc.roleRepo.Delete(roomID, identity)
+}
+
+// authorizeChange lets hosts change any role, while co-hosts may only move
+// participants among the roles below their own.
+func (uc *RoleUsecase) authorizeChange(actor *entity.Participant, roomID string, identity string, role string) error {
+	if !participantIdentityPattern.MatchString(identity) {
+		return entity.ErrInvalidIdentity
+	}
+
+	actorRole, err := uc.GetRole(actor, roomID)
+	if err != nil {
+		return err
+	}
+
+	currentRole, err := uc.GetRole(&entity.Participant{Identity: identity}, roomID)
+	if err != nil {
+		return err
+	}
+
+	if actorRole == entity.RoleHost || isAdmin(actor) {
+		return nil
+	}
+
+	if actorRole != entity.RoleCoHost ||
+		entity.RoleRank(currentRole) >= entity.RoleRank(entity.RoleCoHost) ||
+		entity.RoleRank(role) >= entity.RoleRank(entity.RoleCoHost) {
+		return entity.ErrRoleForbidden
+	}
+
+	return nil
+}
+
+func isAdmin(participant *entity.Participant) bool {
+	return !participant.Guest && hasRole(participant.Roles, adminRole)
+}
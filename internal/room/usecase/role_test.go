@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/lightlink/auth-service/internal/room/domain/dto"
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+)
+
+var testCoHost = &entity.Participant{Identity: "user:7", Name: "grace", UserID: 7, Roles: []string{}}
+
+func TestCreateRoom(t *testing.T) {
+	env := newTestEnv(t)
+
+	roomID, err := env.roleUC.CreateRoom(testAttendee)
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+
+	if !roomIDPattern.MatchString(roomID) {
+		t.Fatalf("room id %q is not a valid room id", roomID)
+	}
+
+	role, err := env.roleUC.GetRole(testAttendee, roomID)
+	if err != nil || role != entity.RoleHost {
+		t.Fatalf("creator role = %q, %v, want %q", role, err, entity.RoleHost)
+	}
+
+	if ttl := env.redis.TTL("room_roles:" + roomID); ttl <= 0 {
+		t.Errorf("room roles ttl = %v, want an expiry", ttl)
+	}
+
+	other, err := env.roleUC.CreateRoom(testAttendee)
+	if err != nil || other == roomID {
+		t.Errorf("second room = %q, %v, want a new room id", other, err)
+	}
+
+	_, err = env.roleUC.CreateRoom(&entity.Participant{Identity: "guest:known", Guest: true, RoomID: "room-1"})
+	if !errors.Is(err, entity.ErrRoleForbidden) {
+		t.Errorf("CreateRoom by a guest err = %v, want %v", err, entity.ErrRoleForbidden)
+	}
+}
+
+func TestJoinUnknownRoom(t *testing.T) {
+	env := newTestEnv(t)
+
+	joinToken, err := env.uc.CreateJoinToken(testAttendee, &dto.JoinTokenRequest{RoomID: "unclaimed"})
+	if err != nil {
+		t.Fatalf("CreateJoinToken: %v", err)
+	}
+
+	if joinToken.Role != entity.RoleAttendee || joinToken.Grants.IsModerator {
+		t.Errorf("first joiner got role %q grants %+v, want a plain attendee", joinToken.Role, joinToken.Grants)
+	}
+
+	if env.redis.Exists("room_roles:unclaimed") {
+		t.Errorf("joining an unknown room stored roles for it")
+	}
+}
+
+func TestGrantRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		actor    *entity.Participant
+		identity string
+		role     string
+		wantErr  error
+	}{
+		{"host grants co-host", testHost, testCoHost.Identity, entity.RoleCoHost, nil},
+		{"host grants host", testHost, testAttendee.Identity, entity.RoleHost, nil},
+		{"admin grants presenter", testAdmin, testAttendee.Identity, entity.RolePresenter, nil},
+		{"co-host grants presenter", testCoHost, testAttendee.Identity, entity.RolePresenter, nil},
+		{"co-host demotes presenter", testCoHost, "user:5", entity.RoleViewer, nil},
+		{"co-host grants co-host", testCoHost, testAttendee.Identity, entity.RoleCoHost, entity.ErrRoleForbidden},
+		{"co-host demotes host", testCoHost, testHost.Identity, entity.RoleAttendee, entity.ErrRoleForbidden},
+		{"attendee grants presenter", testAttendee, testAttendee.Identity, entity.RolePresenter, entity.ErrRoleForbidden},
+		{"guest grants presenter", &entity.Participant{Identity: "guest:known", Guest: true, RoomID: "room-1", Roles: []string{adminRole}}, "guest:known", entity.RolePresenter, entity.ErrRoleForbidden},
+		{"account role", testHost, testAttendee.Identity, adminRole, entity.ErrInvalidRole},
+		{"invalid identity", testHost, "user:../1", entity.RolePresenter, entity.ErrInvalidIdentity},
+		{"last host steps down", testHost, testHost.Identity, entity.RoleCoHost, entity.ErrLastHost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			err := env.roleUC.roleRepo.Set("room-1", testCoHost.Identity, entity.RoleCoHost)
+			if err != nil {
+				t.Fatalf("seed co-host: %v", err)
+			}
+
+			err = env.roleUC.GrantRole(tt.actor, "room-1", tt.identity, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GrantRole err = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			role, err := env.roleUC.GetRole(&entity.Participant{Identity: tt.identity}, "room-1")
+			if err != nil || role != tt.role {
+				t.Errorf("role after grant = %q, %v, want %q", role, err, tt.role)
+			}
+		})
+	}
+}
+
+func TestRevokeRole(t *testing.T) {
+	env := newTestEnv(t)
+
+	err := env.roleUC.RevokeRole(testAttendee, "room-1", "user:5")
+	if !errors.Is(err, entity.ErrRoleForbidden) {
+		t.Fatalf("RevokeRole by an attendee err = %v, want %v", err, entity.ErrRoleForbidden)
+	}
+
+	err = env.roleUC.RevokeRole(testHost, "room-1", "user:5")
+	if err != nil {
+		t.Fatalf("RevokeRole: %v", err)
+	}
+
+	role, err := env.roleUC.GetRole(&entity.Participant{Identity: "user:5"}, "room-1")
+	if err != nil || role != defaultMeetingRole {
+		t.Errorf("role after revoke = %q, %v, want %q", role, err, defaultMeetingRole)
+	}
+
+	err = env.roleUC.RevokeRole(testHost, "room-1", testHost.Identity)
+	if !errors.Is(err, entity.ErrLastHost) {
+		t.Fatalf("revoking the last host err = %v, want %v", err, entity.ErrLastHost)
+	}
+
+	err = env.roleUC.GrantRole(testHost, "room-1", testAttendee.Identity, entity.RoleHost)
+	if err != nil {
+		t.Fatalf("GrantRole host: %v", err)
+	}
+
+	err = env.roleUC.RevokeRole(testAttendee, "room-1", testHost.Identity)
+	if err != nil {
+		t.Fatalf("revoking a host while another remains: %v", err)
+	}
+
+	roles, err := env.roleUC.ListRoles(testAttendee, "room-1")
+	if err != nil {
+		t.Fatalf("ListRoles: %v", err)
+	}
+
+	if _, ok := roles[testHost.Identity]; ok || roles[testAttendee.Identity] != entity.RoleHost {
+		t.Errorf("roles = %v, want only %s as host", roles, testAttendee.Identity)
+	}
+}
+
+// Two hosts demoting each other at once can both pass authorization, so the
+// write itself has to keep one of them in charge.
+func TestDemoteHostsConcurrently(t *testing.T) {
+	env := newTestEnv(t)
+	err := env.roleUC.GrantRole(testHost, "room-1", testAttendee.Identity, entity.RoleHost)
+	if err != nil {
+		t.Fatalf("GrantRole host: %v", err)
+	}
+
+	errs := make(chan error, 2)
+	wg := &sync.WaitGroup{}
+	for _, host := range []*entity.Participant{testHost, testAttendee} {
+		wg.Add(1)
+		go func(identity string) {
+			defer wg.Done()
+			errs <- env.roleUC.roleRepo.Delete("room-1", identity)
+		}(host.Identity)
+	}
+	wg.Wait()
+	close(errs)
+
+	lastHost := 0
+	for err := range errs {
+		if errors.Is(err, entity.ErrLastHost) {
+			lastHost++
+		} else if err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+	}
+
+	roles, err := env.roleUC.roleRepo.List("room-1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	hosts := 0
+	for _, role := range roles {
+		if role == entity.RoleHost {
+			hosts++
+		}
+	}
+
+	if hosts != 1 || lastHost != 1 {
+		t.Errorf("room has %d hosts after %d refusals, want 1 host and 1 refusal", hosts, lastHost)
+	}
+}
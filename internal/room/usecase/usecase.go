@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/room/domain/dto"
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+	roomRepo "github.com/lightlink/auth-service/internal/room/repository"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	"github.com/lightlink/auth-service/internal/token/signer"
+)
+
+const (
+	defaultJoinTokenTTL = 10 * time.Minute
+
+	adminRole = "admin"
+)
+
+var roomIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+type RoomUsecaseI interface {
+	CreateJoinToken(participant *entity.Participant, joinRequest *dto.JoinTokenRequest) (*entity.JoinToken, error)
+	CreateInvite(creator *entity.Participant, inviteRequest *dto.CreateInviteRequest) (*entity.Invite, error)
+	RedeemInvite(redeemRequest *dto.RedeemInviteRequest, accessToken string) (*dto.RedeemInviteResponse, error)
+	RevokeInvite(revoker *entity.Participant, inviteID string) error
+}
+
+type RoomUsecase struct {
+	mediaSigner  signer.Signer
+	tokenTTL     time.Duration
+	roleUC       RoleUsecaseI
+	inviteRepo   roomRepo.InviteRepositoryI
+	inviteSigner signer.Signer
+	inviteMaxTTL time.Duration
+	sessionUC    sessionUsecase.SessionUsecaseI
+	inviteURL    string
+}
+
+// NewRoomUsecase caps invite lifetimes at inviteMaxTTL, which should be the
+// retention of the keys inviteSigner rotates through: an invite must not
+// outlive the key that verifies it.
+func NewRoomUsecase(mediaSigner signer.Signer, tokenTTL time.Duration, roleUsecase RoleUsecaseI, inviteRepository roomRepo.InviteRepositoryI, inviteSigner signer.Signer, inviteMaxTTL time.Duration, sessionUsecase sessionUsecase.SessionUsecaseI, inviteURL string) *RoomUsecase {
+	if tokenTTL <= 0 {
+		tokenTTL = defaultJoinTokenTTL
+	}
+
+	if inviteMaxTTL <= 0 || inviteMaxTTL > maxInviteTTL {
+		inviteMaxTTL = maxInviteTTL
+	}
+
+	return &RoomUsecase{
+		mediaSigner:  mediaSigner,
+		tokenTTL:     tokenTTL,
+		roleUC:       roleUsecase,
+		inviteRepo:   inviteRepository,
+		inviteSigner: inviteSigner,
+		inviteMaxTTL: inviteMaxTTL,
+		sessionUC:    sessionUsecase,
+		inviteURL:    inviteURL,
+	}
+}
+
+// LoadMediaSignerFromEnv returns the key shared with the media server. Its key
+// id is the API key the SFU uses to look the secret up.
+func LoadMediaSignerFromEnv() (signer.Signer, error) {
+	secret := os.Getenv("MEDIA_API_SECRET")
+	if secret == "" {
+		return nil, entity.ErrNoMediaKey
+	}
+
+	return signer.NewHMACSigner(os.Getenv("MEDIA_API_KEY"), []byte(secret)), nil
+}
+
+func (uc *RoomUsecase) CreateJoinToken(participant *entity.Participant, joinRequest *dto.JoinTokenRequest) (*entity.JoinToken, error) {
+	if !roomIDPattern.MatchString(joinRequest.RoomID) {
+		return nil, entity.ErrInvalidRoomID
+	}
+
+	if participant.Guest && participant.RoomID != joinRequest.RoomID {
+		return nil, entity.ErrRoomForbidden
+	}
+
+	return uc.joinToken(joinRequest.RoomID, participant, joinRequest.Grants)
+}
+
+// joinToken derives the grants from the participant's meeting role, so the SFU
+// and every other service reading the token enforce the same rules.
+func (uc *RoomUsecase) joinToken(roomID string, participant *entity.Participant, requestedGrants *entity.Grants) (*entity.JoinToken, error) {
+	role, err := uc.roleUC.GetRole(participant, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	grants := entity.RoleGrants(role)
+	if isAdmin(participant) {
+		grants.IsModerator = true
+	}
+
+	if requestedGrants != nil {
+		grants = grants.Intersect(*requestedGrants)
+	}
+
+	return uc.signJoinToken(roomID, participant, role, grants)
+}
+
+func (uc *RoomUsecase) signJoinToken(roomID string, participant *entity.Participant, role string, grants entity.Grants) (*entity.JoinToken, error) {
+	jti, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(uc.tokenTTL)
+
+	token, err := uc.mediaSigner.Sign(jwt.MapClaims{
+		"iss":    uc.mediaSigner.KeyID(),
+		"sub":    participant.Identity,
+		"name":   participant.Name,
+		"jti":    jti,
+		"nbf":    now.Unix(),
+		"iat":    now.Unix(),
+		"exp":    expiresAt.Unix(),
+		"room":   roomID,
+		"role":   role,
+		"grants": grants,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.JoinToken{
+		Token:     token,
+		RoomID:    roomID,
+		Identity:  participant.Identity,
+		Role:      role,
+		Grants:    grants,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func generateID() (string, error) {
+	buf := make([]byte, 16)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
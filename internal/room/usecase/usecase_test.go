@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/room/domain/entity"
+	roomRepo "github.com/lightlink/auth-service/internal/room/repository/redis"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	sessionUsecase "github.com/lightlink/auth-service/internal/session/usecase"
+	"github.com/lightlink/auth-service/internal/testutil"
+	"github.com/lightlink/auth-service/internal/token/signer"
+)
+
+type fakeSessionUsecase struct {
+	sessionUsecase.SessionUsecaseI
+	identities map[string]*sessionEntity.Identity
+	guests     int
+}
+
+func (f *fakeSessionUsecase) ValidateAccessToken(tokenString string) (*sessionEntity.Identity, error) {
+	identity, ok := f.identities[tokenString]
+	if !ok {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	return identity, nil
+}
+
+func (f *fakeSessionUsecase) CreateGuestSession(displayName string, roomID string) (*sessionEntity.Session, error) {
+	if displayName == "" {
+		return nil, sessionEntity.ErrInvalidDisplayName
+	}
+
+	f.guests++
+	guestID := "guest" + strconv.Itoa(f.guests)
+
+	return &sessionEntity.Session{
+		ID:              guestID + "-session",
+		JWTAccess:       guestID + "-access",
+		Username:        displayName,
+		GuestID:         guestID,
+		RoomID:          roomID,
+		AccessExpiresAt: time.Now().Add(time.Hour),
+	}, nil
+}
+
+type testEnv struct {
+	uc     *RoomUsecase
+	roleUC *RoleUsecase
+	media  *signer.KeySigner
+	redis  *miniredis.Miniredis
+}
+
+var (
+	testHost     = &entity.Participant{Identity: "user:1", Name: "alice", UserID: 1, Roles: []string{}}
+	testAttendee = &entity.Participant{Identity: "user:2", Name: "bob", UserID: 2, Roles: []string{}}
+	testAdmin    = &entity.Participant{Identity: "user:3", Name: "carol", UserID: 3, Roles: []string{adminRole}}
+)
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	base := testutil.NewEnv(t)
+	sessions := &fakeSessionUsecase{
+		identities: map[string]*sessionEntity.Identity{
+			"alice-access":     {UserID: 1, Username: "alice"},
+			"bob-access":       {UserID: 2, Username: "bob"},
+			"erin-access":      {UserID: 5, Username: "erin"},
+			"restricted":       {UserID: 2, Username: "bob", Restricted: true},
+			"delegated":        {UserID: 2, Username: "bob", ClientID: "partner"},
+			"room-1-guest":     {Username: "Guest", Guest: true, GuestID: "known", RoomID: "room-1"},
+			"other-room-guest": {Username: "Guest", Guest: true, GuestID: "stray", RoomID: "room-2"},
+		},
+	}
+
+	roleUC := NewRoleUsecase(roomRepo.NewRoleRedisRepository(base.Dial()))
+	media := signer.NewHMACSigner("media-key", []byte("media secret"))
+	uc := NewRoomUsecase(
+		media,
+		0,
+		roleUC,
+		roomRepo.NewInviteRedisRepository(base.Dial()),
+		base.Signer,
+		time.Hour,
+		sessions,
+		"https://meet.example.com/join",
+	)
+
+	err := roleUC.roleRepo.Set("room-1", testHost.Identity, entity.RoleHost)
+	if err != nil {
+		t.Fatalf("seed host: %v", err)
+	}
+
+	err = roleUC.roleRepo.Set("room-1", "user:5", entity.RolePresenter)
+	if err != nil {
+		t.Fatalf("seed presenter: %v", err)
+	}
+
+	return &testEnv{
+		uc:     uc,
+		roleUC: roleUC,
+		media:  media,
+		redis:  base.Redis,
+	}
+}
+
+// parseJoinToken checks the media token is signed for the SFU and returns its
+// claims.
+func (env *testEnv) parseJoinToken(t *testing.T, tokenString string) jwt.MapClaims {
+	t.Helper()
+
+	token, err := jwt.Parse(tokenString, env.media.Keyfunc)
+	if err != nil || !token.Valid {
+		t.Fatalf("join token does not verify with the media key: %v", err)
+	}
+
+	return token.Claims.(jwt.MapClaims)
+}
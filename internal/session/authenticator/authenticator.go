@@ -0,0 +1,135 @@
+package authenticator
+
+import (
+	"strings"
+
+	"github.com/lightlink/auth-service/internal/session/domain/entity"
+	userRepo "github.com/lightlink/auth-service/internal/user/repository"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Authenticator checks a username and password against some account store.
+// It returns entity.ErrInvalidCredentials when the pair is wrong.
+type Authenticator interface {
+	Authenticate(username string, password string) (*entity.AuthenticatedUser, error)
+	// Roles looks up the roles the account holds now, so sessions don't keep
+	// roles the store has since taken away. It returns
+	// entity.ErrInvalidCredentials when the account is gone.
+	Roles(username string) ([]string, error)
+	// IsExternal reports whether the account behind username is provisioned
+	// by an external directory, in which case it can't be signed up for.
+	IsExternal(username string) bool
+}
+
+// dummyPasswordHash is compared against when the user doesn't exist, so an
+// unknown username costs as much bcrypt work as a wrong password. It uses the
+// cost signup hashes with.
+const dummyPasswordHash = "$2a$10$oSTpDmzoRr/FeDwSWPV0veyW7Con6PeUUHn9vxn7M7ju9EN1rUXc6"
+
+type LocalAuthenticator struct {
+	userRepo userRepo.UserRepositoryI
+}
+
+func NewLocalAuthenticator(userRepository userRepo.UserRepositoryI) *LocalAuthenticator {
+	return &LocalAuthenticator{
+		userRepo: userRepository,
+	}
+}
+
+func (a *LocalAuthenticator) Authenticate(username string, password string) (*entity.AuthenticatedUser, error) {
+	user, err := a.userRepo.GetCredentials(username)
+	if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+		bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+		return nil, entity.ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return nil, entity.ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.AuthenticatedUser{
+		UserID:     user.Id,
+		Username:   user.Username,
+		Roles:      []string{},
+		Restricted: user.Email != "" && !user.EmailVerified,
+	}, nil
+}
+
+func (a *LocalAuthenticator) Roles(username string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (a *LocalAuthenticator) IsExternal(username string) bool {
+	return false
+}
+
+// DomainAuthenticator picks a backend by the domain part of the username
+// ("alice@corp.example.com" or "CORP\alice") and falls back to the default
+// one for plain usernames and unknown domains.
+type DomainAuthenticator struct {
+	byDomain map[string]Authenticator
+	fallback Authenticator
+}
+
+func NewDomainAuthenticator(fallback Authenticator) *DomainAuthenticator {
+	return &DomainAuthenticator{
+		byDomain: map[string]Authenticator{},
+		fallback: fallback,
+	}
+}
+
+func (a *DomainAuthenticator) Register(domain string, authenticator Authenticator) {
+	a.byDomain[strings.ToLower(domain)] = authenticator
+}
+
+func (a *DomainAuthenticator) Authenticate(username string, password string) (*entity.AuthenticatedUser, error) {
+	_, domain := SplitUsername(username)
+	if authenticator, ok := a.byDomain[domain]; ok {
+		return authenticator.Authenticate(username, password)
+	}
+
+	return a.fallback.Authenticate(username, password)
+}
+
+func (a *DomainAuthenticator) Roles(username string) ([]string, error) {
+	_, domain := SplitUsername(username)
+	if authenticator, ok := a.byDomain[domain]; ok {
+		return authenticator.Roles(username)
+	}
+
+	return a.fallback.Roles(username)
+}
+
+func (a *DomainAuthenticator) IsExternal(username string) bool {
+	_, domain := SplitUsername(username)
+	if authenticator, ok := a.byDomain[domain]; ok {
+		return authenticator.IsExternal(username)
+	}
+
+	return a.fallback.IsExternal(username)
+}
+
+// SplitUsername returns the account name and the lowercased domain of a
+// username, or an empty domain when it has none.
+func SplitUsername(username string) (string, string) {
+	if index := strings.LastIndex(username, "@"); index > 0 {
+		return username[:index], strings.ToLower(username[index+1:])
+	}
+
+	if index := strings.Index(username, "\\"); index > 0 {
+		return username[index+1:], strings.ToLower(username[:index])
+	}
+
+	return username, ""
+}
@@ -0,0 +1,59 @@
+package authenticator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lightlink/auth-service/internal/session/domain/entity"
+	"github.com/lightlink/auth-service/internal/testutil"
+	userDTO "github.com/lightlink/auth-service/internal/user/domain/dto"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLocalAuthenticate(t *testing.T) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("alice password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+
+	users := testutil.NewUserRepository(&userDTO.UserTransfer{Id: 1, Username: "alice", Email: "alice@example.com"})
+	users.PasswordHashes[1] = string(passwordHash)
+	localAuthenticator := NewLocalAuthenticator(users)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{"right password", "alice", "alice password", nil},
+		{"wrong password", "alice", "guess", entity.ErrInvalidCredentials},
+		{"unknown user", "mallory", "alice password", entity.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := localAuthenticator.Authenticate(tt.username, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate err = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && (user.UserID != 1 || !user.Restricted) {
+				t.Errorf("Authenticate = %+v, want user 1 restricted until verified", user)
+			}
+		})
+	}
+}
+
+// An unknown username has to cost a full bcrypt comparison, or response time
+// tells which usernames exist.
+func TestDummyPasswordHashMatchesSignupCost(t *testing.T) {
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	if err != nil {
+		t.Fatalf("dummy hash: %v", err)
+	}
+
+	if cost != bcrypt.DefaultCost {
+		t.Errorf("dummy hash cost = %d, want the signup cost %d", cost, bcrypt.DefaultCost)
+	}
+}
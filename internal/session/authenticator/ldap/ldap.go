@@ -0,0 +1,312 @@
+package ldap
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/lightlink/auth-service/internal/session/authenticator"
+	"github.com/lightlink/auth-service/internal/session/domain/entity"
+	userEntity "github.com/lightlink/auth-service/internal/user/domain/entity"
+	userRepo "github.com/lightlink/auth-service/internal/user/repository"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultTimeout = 10 * time.Second
+
+var ErrNoCertificates = errors.New("couldn't load any certificate from ldap ca file")
+
+// Config describes one directory. Users log in as name@domain, where domain is
+// any of Domains; the first domain is used for the LightLink username.
+type Config struct {
+	Domains            []string          `json:"domains"`
+	URL                string            `json:"url"`
+	StartTLS           bool              `json:"start_tls"`
+	CAFile             string            `json:"ca_file"`
+	InsecureSkipVerify bool              `json:"insecure_skip_verify"`
+	BindDN             string            `json:"bind_dn"`
+	BindPassword       string            `json:"bind_password"`
+	BaseDN             string            `json:"base_dn"`
+	UserFilter         string            `json:"user_filter"`
+	GroupAttribute     string            `json:"group_attribute"`
+	GroupRoles         map[string]string `json:"group_roles"`
+	Timeout            int               `json:"timeout"`
+}
+
+func LoadConfigsFromFile(path string) ([]*Config, error) {
+	configs := []*Config{}
+	if path == "" {
+		return configs, nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(contents, &configs)
+	if err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+type LDAPAuthenticator struct {
+	config    *Config
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	userRepo  userRepo.UserRepositoryI
+}
+
+func NewLDAPAuthenticator(config *Config, userRepository userRepo.UserRepositoryI) (*LDAPAuthenticator, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CAFile != "" {
+		caPEM, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, ErrNoCertificates
+		}
+	}
+
+	timeout := defaultTimeout
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Second
+	}
+
+	if config.UserFilter == "" {
+		config.UserFilter = "(&(objectClass=person)(uid={username}))"
+	}
+
+	if config.GroupAttribute == "" {
+		config.GroupAttribute = "memberOf"
+	}
+
+	return &LDAPAuthenticator{
+		config:    config,
+		tlsConfig: tlsConfig,
+		timeout:   timeout,
+		userRepo:  userRepository,
+	}, nil
+}
+
+// Authenticate looks the user up with the service account, then binds as the
+// user to check the password (search-then-bind).
+func (a *LDAPAuthenticator) Authenticate(username string, password string) (*entity.AuthenticatedUser, error) {
+	accountName, _ := authenticator.SplitUsername(username)
+
+	// An empty password would turn the user bind into an unauthenticated
+	// bind, which most servers accept.
+	if accountName == "" || password == "" {
+		return nil, entity.ErrInvalidCredentials
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	userEntry, err := a.findUser(conn, accountName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = conn.Bind(userEntry.DN, password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return nil, entity.ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	localUsername := accountName
+	if len(a.config.Domains) > 0 {
+		localUsername = accountName + "@" + strings.ToLower(a.config.Domains[0])
+	}
+
+	userID, err := a.provisionUser(localUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.AuthenticatedUser{
+		UserID:   userID,
+		Username: localUsername,
+		Roles:    a.mapRoles(userEntry.GetAttributeValues(a.config.GroupAttribute)),
+	}, nil
+}
+
+// Roles looks the user up again with the service account, so a refresh drops
+// the roles of groups the user has left since logging in.
+func (a *LDAPAuthenticator) Roles(username string) ([]string, error) {
+	accountName, _ := authenticator.SplitUsername(username)
+	if accountName == "" {
+		return nil, entity.ErrInvalidCredentials
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	userEntry, err := a.findUser(conn, accountName)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.mapRoles(userEntry.GetAttributeValues(a.config.GroupAttribute)), nil
+}
+
+// findUser searches for the account as the service account. Anything but a
+// single match counts as an unknown user.
+func (a *LDAPAuthenticator) findUser(conn *ldap.Conn, accountName string) (*ldap.Entry, error) {
+	if a.config.BindDN != "" {
+		err := conn.Bind(a.config.BindDN, a.config.BindPassword)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.config.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2,
+		int(a.timeout.Seconds()),
+		false,
+		strings.ReplaceAll(a.config.UserFilter, "{username}", ldap.EscapeFilter(accountName)),
+		[]string{"dn", a.config.GroupAttribute},
+		nil,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Entries) != 1 {
+		return nil, entity.ErrInvalidCredentials
+	}
+
+	return result.Entries[0], nil
+}
+
+func (a *LDAPAuthenticator) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(
+		a.config.URL,
+		ldap.DialWithTLSConfig(a.tlsConfig),
+		ldap.DialWithDialer(&net.Dialer{Timeout: a.timeout}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetTimeout(a.timeout)
+
+	if a.config.StartTLS {
+		err = conn.StartTLS(a.tlsConfig)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+func (a *LDAPAuthenticator) IsExternal(username string) bool {
+	return true
+}
+
+// provisionUser creates the LightLink account on first login and links it to
+// the directory, and only a linked account is reused later. Its password hash
+// is random, so the account can only be used through the directory. A local
+// account that merely has the same username is refused, otherwise whoever
+// registered the name first would take over the directory user.
+func (a *LDAPAuthenticator) provisionUser(username string) (uint, error) {
+	provider := a.identityProvider()
+	user, err := a.userRepo.GetByExternalIdentity(provider, username)
+	if err == nil {
+		return user.Id, nil
+	}
+
+	if st, ok := status.FromError(err); !ok || st.Code() != codes.NotFound {
+		return 0, err
+	}
+
+	_, err = a.userRepo.GetByUsername(username)
+	if err == nil {
+		return 0, entity.ErrAccountConflict
+	}
+
+	if st, ok := status.FromError(err); !ok || st.Code() != codes.NotFound {
+		return 0, err
+	}
+
+	randomPassword := make([]byte, 32)
+	_, err = rand.Read(randomPassword)
+	if err != nil {
+		return 0, err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(randomPassword)), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+
+	createdUser, err := a.userRepo.Create(&userEntity.User{
+		Username:     username,
+		PasswordHash: string(passwordHash),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = a.userRepo.LinkIdentity(createdUser.Id, provider, username)
+	if err != nil {
+		return 0, err
+	}
+
+	return createdUser.Id, nil
+}
+
+// identityProvider names the directory in the external identities of the
+// accounts it provisioned.
+func (a *LDAPAuthenticator) identityProvider() string {
+	if len(a.config.Domains) == 0 {
+		return "ldap"
+	}
+
+	return "ldap:" + strings.ToLower(a.config.Domains[0])
+}
+
+func (a *LDAPAuthenticator) mapRoles(groups []string) []string {
+	roles := []string{}
+	for _, group := range groups {
+		for groupDN, role := range a.config.GroupRoles {
+			if strings.EqualFold(group, groupDN) {
+				roles = append(roles, role)
+			}
+		}
+	}
+
+	return roles
+}
@@ -0,0 +1,301 @@
+package ldap
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jimlambrt/gldap"
+	"github.com/lightlink/auth-service/internal/session/domain/entity"
+	"github.com/lightlink/auth-service/internal/testutil"
+	userDTO "github.com/lightlink/auth-service/internal/user/domain/dto"
+)
+
+const (
+	testBaseDN          = "dc=example,dc=com"
+	testServiceDN       = "cn=service,dc=example,dc=com"
+	testServicePassword = "service secret"
+	testAdminsGroup     = "cn=admins,ou=groups,dc=example,dc=com"
+	testStaffGroup      = "cn=staff,ou=groups,dc=example,dc=com"
+)
+
+type directoryUser struct {
+	dn       string
+	password string
+	groups   []string
+}
+
+var uidFilter = regexp.MustCompile(`\(uid=([^)]*)\)`)
+
+// testDirectory is an in-process LDAP server holding a few people. Its search
+// treats * in the uid assertion as a wildcard, like a real server would, so
+// an unescaped username would match more than it should.
+type testDirectory struct {
+	mu      sync.Mutex
+	users   map[string]*directoryUser
+	filters []string
+}
+
+func startTestDirectory(t *testing.T) (*testDirectory, string) {
+	t.Helper()
+
+	directory := &testDirectory{
+		users: map[string]*directoryUser{
+			"alice": {dn: "uid=alice,ou=people," + testBaseDN, password: "alice password", groups: []string{testAdminsGroup, testStaffGroup}},
+			"bob":   {dn: "uid=bob,ou=people," + testBaseDN, password: "bob password"},
+		},
+	}
+
+	server, err := gldap.NewServer()
+	if err != nil {
+		t.Fatalf("gldap.NewServer: %v", err)
+	}
+
+	mux, err := gldap.NewMux()
+	if err != nil {
+		t.Fatalf("gldap.NewMux: %v", err)
+	}
+	mux.Bind(directory.bind)
+	mux.Search(directory.search)
+	server.Router(mux)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("reserve port: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	go server.Run(addr)
+	t.Cleanup(func() { server.Stop() })
+
+	for deadline := time.Now().Add(5 * time.Second); !server.Ready(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("ldap server didn't start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return directory, "ldap://" + addr
+}
+
+func (d *testDirectory) bind(w *gldap.ResponseWriter, r *gldap.Request) {
+	response := r.NewBindResponse(gldap.WithResponseCode(gldap.ResultInvalidCredentials))
+	defer w.Write(response)
+
+	message, err := r.GetSimpleBindMessage()
+	if err != nil {
+		return
+	}
+
+	if message.UserName == testServiceDN && string(message.Password) == testServicePassword {
+		response.SetResultCode(gldap.ResultSuccess)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, user := range d.users {
+		if user.dn == message.UserName && user.password == string(message.Password) {
+			response.SetResultCode(gldap.ResultSuccess)
+			return
+		}
+	}
+}
+
+func (d *testDirectory) search(w *gldap.ResponseWriter, r *gldap.Request) {
+	response := r.NewSearchDoneResponse(gldap.WithResponseCode(gldap.ResultSuccess))
+	defer w.Write(response)
+
+	message, err := r.GetSearchMessage()
+	if err != nil {
+		response.SetResultCode(gldap.ResultOperationsError)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.filters = append(d.filters, message.Filter)
+
+	match := uidFilter.FindStringSubmatch(message.Filter)
+	if match == nil {
+		return
+	}
+
+	pattern := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(match[1]), `\*`, ".*") + "$")
+	for uid, user := range d.users {
+		if !pattern.MatchString(uid) {
+			continue
+		}
+
+		w.Write(r.NewSearchResponseEntry(user.dn, gldap.WithAttributes(map[string][]string{
+			"memberOf": user.groups,
+		})))
+	}
+}
+
+// setGroups changes the user's group memberships, nil removes the user.
+func (d *testDirectory) setGroups(uid string, groups []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if groups == nil {
+		delete(d.users, uid)
+		return
+	}
+
+	d.users[uid].groups = groups
+}
+
+func (d *testDirectory) lastFilter() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.filters) == 0 {
+		return ""
+	}
+
+	return d.filters[len(d.filters)-1]
+}
+
+func newTestAuthenticator(t *testing.T) (*LDAPAuthenticator, *testDirectory, *testutil.UserRepository) {
+	t.Helper()
+
+	directory, url := startTestDirectory(t)
+	users := testutil.NewUserRepository()
+
+	ldapAuthenticator, err := NewLDAPAuthenticator(&Config{
+		Domains:      []string{"Example.COM"},
+		URL:          url,
+		BindDN:       testServiceDN,
+		BindPassword: testServicePassword,
+		BaseDN:       testBaseDN,
+		GroupRoles: map[string]string{
+			"CN=Admins,OU=Groups,DC=example,DC=com": "admin",
+		},
+		Timeout: 5,
+	}, users)
+	if err != nil {
+		t.Fatalf("NewLDAPAuthenticator: %v", err)
+	}
+
+	return ldapAuthenticator, directory, users
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name      string
+		username  string
+		password  string
+		wantErr   error
+		wantRoles []string
+	}{
+		{"success with group role", "alice@example.com", "alice password", nil, []string{"admin"}},
+		{"success without groups", "bob@example.com", "bob password", nil, []string{}},
+		{"wrong password", "alice@example.com", "guess", entity.ErrInvalidCredentials, nil},
+		{"empty password", "alice@example.com", "", entity.ErrInvalidCredentials, nil},
+		{"unknown user", "mallory@example.com", "alice password", entity.ErrInvalidCredentials, nil},
+		{"wildcard username", "*@example.com", "alice password", entity.ErrInvalidCredentials, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ldapAuthenticator, _, users := newTestAuthenticator(t)
+
+			user, err := ldapAuthenticator.Authenticate(tt.username, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate err = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				if len(users.Users) != 0 {
+					t.Errorf("failed login provisioned %d users", len(users.Users))
+				}
+				return
+			}
+
+			if user.Username != tt.username || strings.Join(user.Roles, ",") != strings.Join(tt.wantRoles, ",") {
+				t.Errorf("Authenticate = %q roles %v, want %q roles %v", user.Username, user.Roles, tt.username, tt.wantRoles)
+			}
+
+			again, err := ldapAuthenticator.Authenticate(tt.username, tt.password)
+			if err != nil || again.UserID != user.UserID {
+				t.Errorf("second login = %v, %v, want the provisioned user %d", again, err, user.UserID)
+			}
+		})
+	}
+}
+
+// An account someone signed up for locally under a directory username must
+// not be handed to the directory user, only accounts the directory created.
+func TestAuthenticateRefusesLocalAccount(t *testing.T) {
+	ldapAuthenticator, _, users := newTestAuthenticator(t)
+	users.Users[7] = &userDTO.UserTransfer{Id: 7, Username: "alice@example.com"}
+
+	_, err := ldapAuthenticator.Authenticate("alice@example.com", "alice password")
+	if !errors.Is(err, entity.ErrAccountConflict) {
+		t.Fatalf("Authenticate err = %v, want %v", err, entity.ErrAccountConflict)
+	}
+
+	if len(users.Identities) != 0 {
+		t.Errorf("refused login linked identities %v", users.Identities)
+	}
+}
+
+// Group changes after login have to show up the next time roles are looked
+// up, and a removed user has none to give.
+func TestRoles(t *testing.T) {
+	ldapAuthenticator, directory, _ := newTestAuthenticator(t)
+
+	roles, err := ldapAuthenticator.Roles("alice@example.com")
+	if err != nil || strings.Join(roles, ",") != "admin" {
+		t.Fatalf("Roles = %v, %v, want [admin]", roles, err)
+	}
+
+	directory.setGroups("alice", []string{testStaffGroup})
+
+	roles, err = ldapAuthenticator.Roles("alice@example.com")
+	if err != nil || len(roles) != 0 {
+		t.Fatalf("Roles after leaving admins = %v, %v, want none", roles, err)
+	}
+
+	directory.setGroups("alice", nil)
+
+	_, err = ldapAuthenticator.Roles("alice@example.com")
+	if !errors.Is(err, entity.ErrInvalidCredentials) {
+		t.Fatalf("Roles of a removed user err = %v, want %v", err, entity.ErrInvalidCredentials)
+	}
+}
+
+func TestAuthenticateEscapesFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		username   string
+		wantFilter string
+	}{
+		{"wildcard", "*", `(&(objectClass=person)(uid=\2a))`},
+		{"filter injection", "alice)(uid=*", `(&(objectClass=person)(uid=alice\29\28uid=\2a))`},
+		{"backslash", `alice\`, `(&(objectClass=person)(uid=alice\5c))`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ldapAuthenticator, directory, _ := newTestAuthenticator(t)
+
+			_, err := ldapAuthenticator.Authenticate(tt.username+"@example.com", "alice password")
+			if !errors.Is(err, entity.ErrInvalidCredentials) {
+				t.Fatalf("Authenticate err = %v, want %v", err, entity.ErrInvalidCredentials)
+			}
+
+			if filter := directory.lastFilter(); filter != tt.wantFilter {
+				t.Errorf("search filter = %q, want %q", filter, tt.wantFilter)
+			}
+		})
+	}
+}
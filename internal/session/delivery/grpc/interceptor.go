@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	proto "github.com/lightlink/auth-service/protogen/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Methods that act on arbitrary users' sessions. Token validation and ticket
+// redemption stay open, the caller has to present the secret being checked.
+var managementMethods = map[string]bool{
+	proto.AuthService_RevokeSession_FullMethodName:     true,
+	proto.AuthService_RevokeAllSessions_FullMethodName: true,
+	proto.AuthService_ListSessions_FullMethodName:      true,
+	proto.AuthService_GetSessionInfo_FullMethodName:    true,
+}
+
+// NewServiceAuthInterceptor requires the shared service token on session
+// management calls. Without a token configured those calls are refused.
+func NewServiceAuthInterceptor(serviceToken string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !managementMethods[info.FullMethod] {
+			return handler(ctx, request)
+		}
+
+		if serviceToken == "" {
+			return nil, status.Error(codes.PermissionDenied, "session management is disabled")
+		}
+
+		if !hasServiceToken(ctx, serviceToken) {
+			return nil, status.Error(codes.Unauthenticated, "service token is invalid")
+		}
+
+		return handler(ctx, request)
+	}
+}
+
+func hasServiceToken(ctx context.Context, serviceToken string) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	for _, authorization := range md.Get("authorization") {
+		presented := strings.TrimPrefix(authorization, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(serviceToken)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
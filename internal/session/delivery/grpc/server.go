@@ -0,0 +1,132 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lightlink/auth-service/internal/session/domain/entity"
+	"github.com/lightlink/auth-service/internal/session/usecase"
+	proto "github.com/lightlink/auth-service/protogen/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type AuthGrpcServer struct {
+	proto.UnimplementedAuthServiceServer
+	sessionUC usecase.SessionUsecaseI
+}
+
+func NewAuthGrpcServer(sessionUsecase usecase.SessionUsecaseI) *AuthGrpcServer {
+	return &AuthGrpcServer{
+		sessionUC: sessionUsecase,
+	}
+}
+
+func (s *AuthGrpcServer) ValidateToken(ctx context.Context, request *proto.ValidateTokenRequest) (*proto.ValidateTokenResponse, error) {
+	identity, err := s.sessionUC.ValidateAccessToken(request.AccessToken)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &proto.ValidateTokenResponse{
+		UserId:     uint32(identity.UserID),
+		Username:   identity.Username,
+		SessionId:  identity.SessionID,
+		ExpiresAt:  identity.ExpiresAt.Unix(),
+		ClientId:   identity.ClientID,
+		Scope:      identity.Scope,
+		Service:    identity.Service,
+		Guest:      identity.Guest,
+		GuestId:    identity.GuestID,
+		RoomId:     identity.RoomID,
+		Restricted: identity.Restricted,
+		Roles:      identity.Roles,
+	}, nil
+}
+
+func (s *AuthGrpcServer) RedeemTicket(ctx context.Context, request *proto.RedeemTicketRequest) (*proto.ValidateTokenResponse, error) {
+	identity, err := s.sessionUC.RedeemTicket(request.Ticket, request.Origin)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &proto.ValidateTokenResponse{
+		UserId:     uint32(identity.UserID),
+		Username:   identity.Username,
+		SessionId:  identity.SessionID,
+		ExpiresAt:  identity.ExpiresAt.Unix(),
+		Guest:      identity.Guest,
+		GuestId:    identity.GuestID,
+		RoomId:     identity.RoomID,
+		Restricted: identity.Restricted,
+		Roles:      identity.Roles,
+	}, nil
+}
+
+func (s *AuthGrpcServer) RevokeSession(ctx context.Context, request *proto.RevokeSessionRequest) (*proto.RevokeSessionResponse, error) {
+	err := s.sessionUC.Delete(uint(request.UserId), request.SessionId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &proto.RevokeSessionResponse{}, nil
+}
+
+func (s *AuthGrpcServer) RevokeAllSessions(ctx context.Context, request *proto.RevokeAllSessionsRequest) (*proto.RevokeSessionResponse, error) {
+	err := s.sessionUC.RevokeAllSessions(uint(request.UserId))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &proto.RevokeSessionResponse{}, nil
+}
+
+func (s *AuthGrpcServer) ListSessions(ctx context.Context, request *proto.ListSessionsRequest) (*proto.ListSessionsResponse, error) {
+	sessions, err := s.sessionUC.ListSessions(uint(request.UserId))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	sessionInfos := make([]*proto.SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		sessionInfos = append(sessionInfos, sessionEntityToInfo(session))
+	}
+
+	return &proto.ListSessionsResponse{
+		Sessions: sessionInfos,
+	}, nil
+}
+
+func (s *AuthGrpcServer) GetSessionInfo(ctx context.Context, request *proto.GetSessionInfoRequest) (*proto.SessionInfo, error) {
+	session, err := s.sessionUC.GetSession(uint(request.UserId), request.SessionId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return sessionEntityToInfo(session), nil
+}
+
+func sessionEntityToInfo(session *entity.Session) *proto.SessionInfo {
+	return &proto.SessionInfo{
+		SessionId:        session.ID,
+		UserId:           uint32(session.UserID),
+		Username:         session.Username,
+		AccessExpiresAt:  session.AccessExpiresAt.Unix(),
+		RefreshExpiresAt: session.RefreshExpiresAt.Unix(),
+	}
+}
+
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, entity.ErrNoSession):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, entity.ErrInvalidToken),
+		errors.Is(err, entity.ErrTokenRevoked),
+		errors.Is(err, entity.ErrInvalidTicket):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, entity.ErrInvalidOrigin):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
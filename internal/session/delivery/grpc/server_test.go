@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lightlink/auth-service/internal/session/domain/entity"
+	"github.com/lightlink/auth-service/internal/session/usecase"
+	proto "github.com/lightlink/auth-service/protogen/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeSessionUsecase struct {
+	usecase.SessionUsecaseI
+	identity *entity.Identity
+}
+
+func (f *fakeSessionUsecase) ValidateAccessToken(tokenString string) (*entity.Identity, error) {
+	return f.identity, nil
+}
+
+func (f *fakeSessionUsecase) RedeemTicket(ticket string, origin string) (*entity.Identity, error) {
+	return f.identity, nil
+}
+
+func TestValidateTokenCarriesRestrictionAndRoles(t *testing.T) {
+	server := NewAuthGrpcServer(&fakeSessionUsecase{
+		identity: &entity.Identity{UserID: 2, Username: "bob", Roles: []string{"admin", "support"}, Restricted: true},
+	})
+
+	validated, err := server.ValidateToken(context.Background(), &proto.ValidateTokenRequest{AccessToken: "token"})
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+
+	redeemed, err := server.RedeemTicket(context.Background(), &proto.RedeemTicketRequest{Ticket: "ticket"})
+	if err != nil {
+		t.Fatalf("RedeemTicket: %v", err)
+	}
+
+	for name, response := range map[string]*proto.ValidateTokenResponse{"ValidateToken": validated, "RedeemTicket": redeemed} {
+		if !response.GetRestricted() || strings.Join(response.GetRoles(), ",") != "admin,support" {
+			t.Errorf("%s restricted %v roles %v, want restricted with admin,support", name, response.GetRestricted(), response.GetRoles())
+		}
+	}
+}
+
+func TestToStatusErrorUnwraps(t *testing.T) {
+	tests := []struct {
+		err      error
+		wantCode codes.Code
+	}{
+		{entity.ErrNoSession, codes.NotFound},
+		{fmt.Errorf("get session: %w", entity.ErrNoSession), codes.NotFound},
+		{fmt.Errorf("validate: %w", entity.ErrTokenRevoked), codes.Unauthenticated},
+		{fmt.Errorf("redeem: %w", entity.ErrInvalidOrigin), codes.InvalidArgument},
+		{errors.New("redis down"), codes.Internal},
+	}
+
+	for _, test := range tests {
+		if code := status.Code(toStatusError(test.err)); code != test.wantCode {
+			t.Errorf("toStatusError(%v) code = %v, want %v", test.err, code, test.wantCode)
+		}
+	}
+}
@@ -6,38 +6,51 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	forwardAuthEntity "github.com/lightlink/auth-service/internal/forwardauth/domain/entity"
+	forwardAuthUsecase "github.com/lightlink/auth-service/internal/forwardauth/usecase"
+	mfaEntity "github.com/lightlink/auth-service/internal/mfa/domain/entity"
+	roomEntity "github.com/lightlink/auth-service/internal/room/domain/entity"
+	roomUsecase "github.com/lightlink/auth-service/internal/room/usecase"
 	"github.com/lightlink/auth-service/internal/session/domain/dto"
+	"github.com/lightlink/auth-service/internal/session/domain/entity"
 	"github.com/lightlink/auth-service/internal/session/usecase"
 )
 
+const mfaTokenCookie = "mfa_token"
+
 type SessionHandler struct {
-	sessionUC usecase.SessionUsecaseI
+	sessionUC         usecase.SessionUsecaseI
+	roleUC            roomUsecase.RoleUsecaseI
+	forwardAuthConfig *forwardAuthEntity.Config
 }
 
-func NewSessionHandler(sessionUsecase usecase.SessionUsecaseI) *SessionHandler {
+func NewSessionHandler(
+	sessionUsecase usecase.SessionUsecaseI,
+	roleUsecase roomUsecase.RoleUsecaseI,
+	forwardAuthConfig *forwardAuthEntity.Config,
+) *SessionHandler {
 	return &SessionHandler{
-		sessionUC: sessionUsecase,
+		sessionUC:         sessionUsecase,
+		roleUC:            roleUsecase,
+		forwardAuthConfig: forwardAuthConfig,
 	}
 }
 
-func (h *SessionHandler) Signup(w http.ResponseWriter, r *http.Request) {
+func (h *SessionHandler) Login(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	defer r.Body.Close()
 	if err != nil {
-		/*Handle*/
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Println("body err")
 		return
 	}
 
-	signupRequest := &dto.SignupRequest{}
-	err = json.Unmarshal(body, signupRequest)
+	loginRequest := &dto.LoginRequest{}
+	err = json.Unmarshal(body, loginRequest)
 	if err != nil {
 		/*Handle*/
 		w.WriteHeader(http.StatusBadRequest)
@@ -45,39 +58,41 @@ func (h *SessionHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	createdSessionEntity, err := h.sessionUC.Signup(signupRequest)
+	createdSessionEntity, err := h.sessionUC.Login(loginRequest)
+	mfaRequired := &entity.MFARequiredError{}
+	if errors.As(err, &mfaRequired) {
+		writeJSON(w, http.StatusAccepted, &dto.MFARequiredResponse{
+			MFARequired: true,
+			MFAToken:    mfaRequired.MFAToken,
+		})
+		return
+	}
+
+	if errors.Is(err, entity.ErrInvalidCredentials) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("login err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrAccountConflict) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Println("login err", err)
+		return
+	}
+
 	if err != nil {
 		/*Handle*/
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Println("session create err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("login err", err)
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:    "access_token",
-		Value:   createdSessionEntity.JWTAccess,
-		Path:    "/",
-		Expires: createdSessionEntity.AccessExpiresAt, /*TODO*/
-		Secure:  false,
-	})
-	http.SetCookie(w, &http.Cookie{
-		Name:    "refresh_token",
-		Value:   createdSessionEntity.JWTRefresh,
-		Path:    "/",
-		Expires: createdSessionEntity.RefreshExpiresAt, /*TODO*/
-		Secure:  false,
-	})
-	http.SetCookie(w, &http.Cookie{
-		Name:   "user_id",
-		Value:  strconv.Itoa(int(createdSessionEntity.UserID)),
-		Path:   "/",
-		Secure: false,
-	})
+	SetSessionCookies(w, createdSessionEntity)
 
 	w.WriteHeader(http.StatusOK)
 }
 
-func (h *SessionHandler) Login(w http.ResponseWriter, r *http.Request) {
+func (h *SessionHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	defer r.Body.Close()
 	if err != nil {
@@ -86,8 +101,8 @@ func (h *SessionHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	loginRequest := &dto.LoginRequest{}
-	err = json.Unmarshal(body, loginRequest)
+	loginMFARequest := &dto.LoginMFARequest{}
+	err = json.Unmarshal(body, loginMFARequest)
 	if err != nil {
 		/*Handle*/
 		w.WriteHeader(http.StatusBadRequest)
@@ -95,39 +110,53 @@ func (h *SessionHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	createdSessionEntity, err := h.sessionUC.Login(loginRequest)
+	if loginMFARequest.MFAToken == "" {
+		if mfaCookie, err := r.Cookie(mfaTokenCookie); err == nil {
+			loginMFARequest.MFAToken = mfaCookie.Value
+		}
+	}
+
+	createdSessionEntity, err := h.sessionUC.LoginMFA(loginMFARequest)
+	if errors.Is(err, mfaEntity.ErrTooManyAttempts) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Println("login mfa err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrInvalidToken) ||
+		errors.Is(err, entity.ErrTokenRevoked) ||
+		errors.Is(err, mfaEntity.ErrInvalidCode) ||
+		errors.Is(err, mfaEntity.ErrNotEnrolled) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("login mfa err", err)
+		return
+	}
+
 	if err != nil {
 		/*Handle*/
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Println("login err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("login mfa err", err)
 		return
 	}
 
 	http.SetCookie(w, &http.Cookie{
-		Name:    "access_token",
-		Value:   createdSessionEntity.JWTAccess,
-		Path:    "/",
-		Expires: createdSessionEntity.AccessExpiresAt,
-		Secure:  false,
-	})
-	http.SetCookie(w, &http.Cookie{
-		Name:    "refresh_token",
-		Value:   createdSessionEntity.JWTRefresh,
-		Path:    "/",
-		Expires: createdSessionEntity.RefreshExpiresAt,
+		Name:    mfaTokenCookie,
+		Value:   "",
+		Path:    "/api/login/mfa",
+		Expires: time.Unix(0, 0),
 		Secure:  false,
 	})
-	http.SetCookie(w, &http.Cookie{
-		Name:   "user_id",
-		Value:  strconv.Itoa(int(createdSessionEntity.UserID)),
-		Path:   "/",
-		Secure: false,
-	})
+	SetSessionCookies(w, createdSessionEntity)
 
 	w.WriteHeader(http.StatusOK)
 }
 
 func (h *SessionHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Guest") == "true" {
+		h.guestLogout(w, r)
+		return
+	}
+
 	userIDString := r.Header.Get("X-User-ID")
 	userID64, err := strconv.ParseUint(userIDString, 10, 32)
 	if err != nil {
@@ -138,7 +167,15 @@ func (h *SessionHandler) Logout(w http.ResponseWriter, r *http.Request) {
 
 	userID := uint(userID64)
 
-	err = h.sessionUC.Delete(userID)
+	sessionID := r.Header.Get("X-Session-ID")
+	if sessionID == "" {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("Missing session id")
+		return
+	}
+
+	err = h.sessionUC.Delete(userID, sessionID)
 	if err != nil {
 		/*Handle*/
 		fmt.Println("uc logout err", err)
@@ -170,9 +207,35 @@ func (h *SessionHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func (h *SessionHandler) Check(w http.ResponseWriter, r *http.Request) {
-	tokenKey := []byte(os.Getenv("TOKEN_KEY"))
+func (h *SessionHandler) guestLogout(w http.ResponseWriter, r *http.Request) {
+	guestID := r.Header.Get("X-Guest-ID")
+	sessionID := r.Header.Get("X-Session-ID")
+	if guestID == "" || sessionID == "" {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("Missing guest session id")
+		return
+	}
 
+	err := h.sessionUC.DeleteGuestSession(guestID, sessionID)
+	if err != nil {
+		/*Handle*/
+		fmt.Println("uc guest logout err", err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:    "access_token",
+		Value:   "",
+		Path:    "/",
+		Expires: time.Unix(0, 0),
+		Secure:  false,
+	})
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *SessionHandler) Check(w http.ResponseWriter, r *http.Request) {
 	tokenString := r.Header.Get("Authorization")
 	if tokenString == "" {
 		/*Handle*/
@@ -190,45 +253,100 @@ func (h *SessionHandler) Check(w http.ResponseWriter, r *http.Request) {
 	}
 	pureToken := fieldParts[1]
 
-	token, err := jwt.Parse(pureToken, func(token *jwt.Token) (interface{}, error) {
-		method, ok := token.Method.(*jwt.SigningMethodHMAC)
-		if !ok || method.Alg() != "HS256" {
-			return nil, errors.New("bad sign method")
-		}
-		return tokenKey, nil
-	})
-	if err != nil || !token.Valid {
+	identity, err := h.sessionUC.ValidateAccessToken(pureToken)
+	if err != nil {
 		/*Handle*/
 		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Println("Token is invalid")
+		fmt.Println("Token is invalid", err)
 		return
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		/*Handle*/
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Println("User claims type cast err")
+	// Always answer with the roles header, even empty, so a value the client
+	// sent itself never reaches the services behind the gateway.
+	w.Header().Set(h.forwardAuthConfig.RolesHeader, strings.Join(identity.Roles, ","))
+
+	if identity.Service {
+		w.Header().Set("X-Subject-Type", "service")
+		w.Header().Set("X-Client-ID", identity.ClientID)
+		w.Header().Set("X-Scope", identity.Scope)
+		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	claimsUser, ok := claims["user"].(map[string]interface{})
-	if !ok {
-		/*Handle*/
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Println("User claims are missing")
-		return
+	// A guest's room is fixed by its session; anyone else names the room
+	// they want their meeting role for.
+	roomID := r.URL.Query().Get("room_id")
+	if roomID == "" {
+		roomID = r.Header.Get("X-Room-ID")
 	}
 
-	userIDString, ok := claimsUser["id"].(string)
-	if !ok {
-		/*Handle*/
-		w.WriteHeader(http.StatusUnauthorized)
-		fmt.Println("Couldn't parse user id")
-		return
+	var participant *roomEntity.Participant
+	if identity.Guest {
+		roomID = identity.RoomID
+		participant = &roomEntity.Participant{
+			Identity: roomEntity.GuestIdentity(identity.GuestID),
+			Guest:    true,
+			RoomID:   identity.RoomID,
+		}
+
+		w.Header().Set("X-Subject-Type", "guest")
+		w.Header().Set("X-Guest", "true")
+		w.Header().Set("X-Guest-ID", identity.GuestID)
+		w.Header().Set("X-Session-ID", identity.SessionID)
+		w.Header().Set("X-Username", identity.Username)
+	} else {
+		userIDString := strconv.Itoa(int(identity.UserID))
+		participant = &roomEntity.Participant{
+			Identity: roomEntity.UserIdentity(userIDString),
+			UserID:   identity.UserID,
+			Roles:    identity.Roles,
+		}
+
+		w.Header().Set("X-Subject-Type", "user")
+		w.Header().Set("X-User-ID", userIDString)
+		w.Header().Set("X-Session-ID", identity.SessionID)
+		w.Header().Set("X-Username", identity.Username)
+		if identity.ClientID != "" {
+			w.Header().Set("X-Client-ID", identity.ClientID)
+			w.Header().Set("X-Scope", identity.Scope)
+		}
+		// Same policy as forward auth: an unverified account only reaches
+		// the paths allowed for it.
+		if identity.Restricted {
+			originalURI := r.Header.Get("X-Forwarded-Uri")
+			if originalURI == "" {
+				originalURI = r.Header.Get("X-Original-URI")
+			}
+
+			if !forwardAuthUsecase.HasPathPrefix(originalURI, h.forwardAuthConfig.RestrictedPaths) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Println("Account is restricted", originalURI)
+				return
+			}
+
+			w.Header().Set("X-Restricted", "true")
+		}
+	}
+
+	if roomID != "" {
+		meetingRole, err := h.roleUC.GetRole(participant, roomID)
+		if errors.Is(err, roomEntity.ErrInvalidRoomID) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println("Room id is invalid", err)
+			return
+		}
+
+		if err != nil {
+			/*Handle*/
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Println("meeting role err", err)
+			return
+		}
+
+		w.Header().Set("X-Room-ID", roomID)
+		w.Header().Set("X-Meeting-Role", meetingRole)
 	}
 
-	w.Header().Set("X-User-ID", userIDString)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -241,13 +359,7 @@ func (h *SessionHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokenKey := []byte(os.Getenv("TOKEN_KEY"))
-	token, err := jwt.Parse(refreshCookie.Value, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method")
-		}
-		return tokenKey, nil
-	})
+	token, err := h.sessionUC.ParseToken(refreshCookie.Value)
 	if err != nil || !token.Valid {
 		/*Handle*/
 		w.WriteHeader(http.StatusUnauthorized)
@@ -256,6 +368,14 @@ func (h *SessionHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	}
 
 	refreshedSession, err := h.sessionUC.RefreshSession(token)
+	if errors.Is(err, entity.ErrNoSession) ||
+		errors.Is(err, entity.ErrInvalidRefreshToken) ||
+		errors.Is(err, entity.ErrRefreshTokenReused) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("refresh err", err)
+		return
+	}
+
 	if err != nil {
 		/*Handle*/
 		w.WriteHeader(http.StatusInternalServerError)
@@ -279,3 +399,187 @@ func (h *SessionHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+func (h *SessionHandler) IssueTicket(w http.ResponseWriter, r *http.Request) {
+	identity, err := identityFromHeaders(r)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("ticket identity err", err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	issueRequest := &dto.IssueTicketRequest{}
+	if len(body) > 0 {
+		err = json.Unmarshal(body, issueRequest)
+		if err != nil {
+			/*Handle*/
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println("unmarshal err")
+			return
+		}
+	}
+
+	if issueRequest.Origin == "" {
+		issueRequest.Origin = r.Header.Get("Origin")
+	}
+
+	ticket, err := h.sessionUC.IssueTicket(identity, issueRequest.Origin)
+	if errors.Is(err, entity.ErrInvalidOrigin) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("issue ticket err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrInvalidToken) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("issue ticket err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("issue ticket err", err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, &dto.TicketResponse{
+		Ticket:    ticket.Ticket,
+		ExpiresAt: ticket.ExpiresAt.Unix(),
+	})
+}
+
+func (h *SessionHandler) RedeemTicket(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("body err")
+		return
+	}
+
+	redeemRequest := &dto.RedeemTicketRequest{}
+	err = json.Unmarshal(body, redeemRequest)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("unmarshal err")
+		return
+	}
+
+	identity, err := h.sessionUC.RedeemTicket(redeemRequest.Ticket, redeemRequest.Origin)
+	if errors.Is(err, entity.ErrInvalidOrigin) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Println("redeem ticket err", err)
+		return
+	}
+
+	if errors.Is(err, entity.ErrInvalidTicket) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("redeem ticket err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("redeem ticket err", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.IdentityToTicketResponse(identity))
+}
+
+// identityFromHeaders reads the identity Check put on the request.
+func identityFromHeaders(r *http.Request) (*entity.Identity, error) {
+	sessionID := r.Header.Get("X-Session-ID")
+	if sessionID == "" {
+		return nil, errors.New("missing session id")
+	}
+
+	if r.Header.Get("X-Guest") == "true" {
+		guestID := r.Header.Get("X-Guest-ID")
+		if guestID == "" {
+			return nil, errors.New("missing guest id")
+		}
+
+		return &entity.Identity{
+			SessionID: sessionID,
+			Guest:     true,
+			GuestID:   guestID,
+		}, nil
+	}
+
+	userID64, err := strconv.ParseUint(r.Header.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Identity{
+		UserID:    uint(userID64),
+		SessionID: sessionID,
+	}, nil
+}
+
+// SetMFATokenCookie hands a pending token to the second factor step when the
+// first one ended in a redirect, e.g. a federated login callback.
+func SetMFATokenCookie(w http.ResponseWriter, mfaToken string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     mfaTokenCookie,
+		Value:    mfaToken,
+		Path:     "/api/login/mfa",
+		Expires:  time.Now().Add(5 * time.Minute),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   false,
+	})
+}
+
+func SetSessionCookies(w http.ResponseWriter, session *entity.Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:    "access_token",
+		Value:   session.JWTAccess,
+		Path:    "/",
+		Expires: session.AccessExpiresAt, /*TODO*/
+		Secure:  false,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:    "refresh_token",
+		Value:   session.JWTRefresh,
+		Path:    "/",
+		Expires: session.RefreshExpiresAt, /*TODO*/
+		Secure:  false,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:   "user_id",
+		Value:  strconv.Itoa(int(session.UserID)),
+		Path:   "/",
+		Secure: false,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("marshal err", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
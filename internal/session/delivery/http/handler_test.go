@@ -0,0 +1,114 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	forwardAuthEntity "github.com/lightlink/auth-service/internal/forwardauth/domain/entity"
+	"github.com/lightlink/auth-service/internal/session/domain/entity"
+	"github.com/lightlink/auth-service/internal/session/usecase"
+)
+
+type fakeSessionUsecase struct {
+	usecase.SessionUsecaseI
+	identities map[string]*entity.Identity
+}
+
+func (f *fakeSessionUsecase) ValidateAccessToken(tokenString string) (*entity.Identity, error) {
+	identity, ok := f.identities[tokenString]
+	if !ok {
+		return nil, entity.ErrInvalidToken
+	}
+
+	return identity, nil
+}
+
+func TestCheckRestricted(t *testing.T) {
+	handler := NewSessionHandler(&fakeSessionUsecase{
+		identities: map[string]*entity.Identity{
+			"verified":   {UserID: 7, Username: "alice", SessionID: "sid"},
+			"restricted": {UserID: 8, Username: "bob", SessionID: "sid", Restricted: true},
+		},
+	}, nil, &forwardAuthEntity.Config{
+		RolesHeader:     "X-User-Roles",
+		RestrictedPaths: []string{"/api/email/verify"},
+	})
+
+	tests := []struct {
+		name           string
+		token          string
+		headers        map[string]string
+		wantStatus     int
+		wantRestricted string
+	}{
+		{"verified", "verified", map[string]string{"X-Forwarded-Uri": "/api/rooms"}, http.StatusOK, ""},
+		{"restricted elsewhere", "restricted", map[string]string{"X-Forwarded-Uri": "/api/rooms"}, http.StatusForbidden, ""},
+		{"restricted without uri", "restricted", nil, http.StatusForbidden, ""},
+		{"restricted allowlisted", "restricted", map[string]string{"X-Forwarded-Uri": "/api/email/verify"}, http.StatusOK, "true"},
+		{"restricted allowlisted nginx", "restricted", map[string]string{"X-Original-URI": "/api/email/verify?x=1"}, http.StatusOK, "true"},
+		{"restricted walking out", "restricted", map[string]string{"X-Forwarded-Uri": "/api/email/verify/../../rooms"}, http.StatusForbidden, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/check", nil)
+			request.Header.Set("Authorization", "Bearer "+test.token)
+			for name, value := range test.headers {
+				request.Header.Set(name, value)
+			}
+			recorder := httptest.NewRecorder()
+
+			handler.Check(recorder, request)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+
+			if got := recorder.Header().Get("X-Restricted"); got != test.wantRestricted {
+				t.Errorf("X-Restricted = %q, want %q", got, test.wantRestricted)
+			}
+		})
+	}
+}
+
+// The client's own roles header must never survive Check, whatever the token
+// carries.
+func TestCheckOverwritesRoles(t *testing.T) {
+	handler := NewSessionHandler(&fakeSessionUsecase{
+		identities: map[string]*entity.Identity{
+			"admin":   {UserID: 7, Username: "alice", SessionID: "sid", Roles: []string{"admin"}},
+			"user":    {UserID: 8, Username: "bob", SessionID: "sid", Roles: []string{}},
+			"service": {Service: true, ClientID: "svc", Roles: []string{}},
+		},
+	}, nil, &forwardAuthEntity.Config{RolesHeader: "X-Account-Roles"})
+
+	tests := []struct {
+		token     string
+		wantRoles string
+	}{
+		{"admin", "admin"},
+		{"user", ""},
+		{"service", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.token, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/check", nil)
+			request.Header.Set("Authorization", "Bearer "+test.token)
+			request.Header.Set("X-Account-Roles", "admin,superuser")
+			recorder := httptest.NewRecorder()
+
+			handler.Check(recorder, request)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+			}
+
+			roles := recorder.Header().Values("X-Account-Roles")
+			if len(roles) != 1 || roles[0] != test.wantRoles {
+				t.Errorf("X-Account-Roles = %q, want %q", roles, test.wantRoles)
+			}
+		})
+	}
+}
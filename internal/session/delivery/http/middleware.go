@@ -0,0 +1,22 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// RequireFirstParty refuses requests the gateway authenticated with an
+// access token delegated to an OAuth client. Such a token names the user but
+// only carries the scopes they consented to, and none of those cover
+// managing the account, so Check and forward auth flag it with X-Client-ID.
+func RequireFirstParty(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if clientID := r.Header.Get("X-Client-ID"); clientID != "" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Println("delegated token refused", r.Method, r.URL.Path, clientID)
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireFirstParty(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{"first-party session", map[string]string{"X-User-ID": "1"}, http.StatusNoContent},
+		{"delegated token", map[string]string{"X-User-ID": "1", "X-Client-ID": "partner"}, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			called := false
+			handler := RequireFirstParty(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			request := httptest.NewRequest(http.MethodPost, "/api/mfa/totp/enroll", nil)
+			for name, value := range test.headers {
+				request.Header.Set(name, value)
+			}
+			recorder := httptest.NewRecorder()
+
+			handler(recorder, request)
+
+			if recorder.Code != test.wantStatus || called != (test.wantStatus == http.StatusNoContent) {
+				t.Errorf("status = %d, handler called %v, want %d", recorder.Code, called, test.wantStatus)
+			}
+		})
+	}
+}
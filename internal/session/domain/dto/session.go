@@ -8,6 +8,7 @@ import (
 type SignupRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Email    string `json:"email"`
 }
 
 type LoginRequest struct {
@@ -15,12 +16,34 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+type MFARequiredResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
+
 func SessionEntityToModel(sessionEntity *entity.Session) *model.Session {
 	return &model.Session{
+		ID:               sessionEntity.ID,
+		FamilyID:         sessionEntity.FamilyID,
+		AccessJTI:        sessionEntity.AccessJTI,
+		RefreshJTI:       sessionEntity.RefreshJTI,
 		JWTAccess:        sessionEntity.JWTAccess,
 		JWTRefresh:       sessionEntity.JWTRefresh,
 		UserID:           sessionEntity.UserID,
 		Username:         sessionEntity.Username,
+		ClientID:         sessionEntity.ClientID,
+		Scope:            sessionEntity.Scope,
+		AuthTime:         sessionEntity.AuthTime,
+		AMR:              sessionEntity.AMR,
+		Roles:            sessionEntity.Roles,
+		Restricted:       sessionEntity.Restricted,
+		GuestID:          sessionEntity.GuestID,
+		RoomID:           sessionEntity.RoomID,
 		AccessExpiresAt:  sessionEntity.AccessExpiresAt,
 		RefreshExpiresAt: sessionEntity.RefreshExpiresAt,
 	}
@@ -28,10 +51,22 @@ func SessionEntityToModel(sessionEntity *entity.Session) *model.Session {
 
 func SessionModelToEntity(sessionModel *model.Session) *entity.Session {
 	return &entity.Session{
+		ID:               sessionModel.ID,
+		FamilyID:         sessionModel.FamilyID,
+		AccessJTI:        sessionModel.AccessJTI,
+		RefreshJTI:       sessionModel.RefreshJTI,
 		JWTAccess:        sessionModel.JWTAccess,
 		JWTRefresh:       sessionModel.JWTRefresh,
 		UserID:           sessionModel.UserID,
 		Username:         sessionModel.Username,
+		ClientID:         sessionModel.ClientID,
+		Scope:            sessionModel.Scope,
+		AuthTime:         sessionModel.AuthTime,
+		AMR:              sessionModel.AMR,
+		Roles:            sessionModel.Roles,
+		Restricted:       sessionModel.Restricted,
+		GuestID:          sessionModel.GuestID,
+		RoomID:           sessionModel.RoomID,
 		AccessExpiresAt:  sessionModel.AccessExpiresAt,
 		RefreshExpiresAt: sessionModel.RefreshExpiresAt,
 	}
@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"github.com/lightlink/auth-service/internal/session/domain/entity"
+	"github.com/lightlink/auth-service/internal/session/domain/model"
+)
+
+type IssueTicketRequest struct {
+	Origin string `json:"origin"`
+}
+
+type TicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+type RedeemTicketRequest struct {
+	Ticket string `json:"ticket"`
+	Origin string `json:"origin"`
+}
+
+type TicketIdentityResponse struct {
+	UserID     uint     `json:"user_id,omitempty"`
+	Username   string   `json:"username"`
+	SessionID  string   `json:"session_id"`
+	Roles      []string `json:"roles"`
+	Restricted bool     `json:"restricted,omitempty"`
+	Guest      bool     `json:"guest,omitempty"`
+	GuestID    string   `json:"guest_id,omitempty"`
+	RoomID     string   `json:"room_id,omitempty"`
+}
+
+func TicketEntityToModel(ticketEntity *entity.Ticket) *model.Ticket {
+	return &model.Ticket{
+		UserID:    ticketEntity.UserID,
+		Username:  ticketEntity.Username,
+		SessionID: ticketEntity.SessionID,
+		GuestID:   ticketEntity.GuestID,
+		RoomID:    ticketEntity.RoomID,
+		Origin:    ticketEntity.Origin,
+		ExpiresAt: ticketEntity.ExpiresAt,
+	}
+}
+
+func TicketModelToEntity(ticket string, ticketModel *model.Ticket) *entity.Ticket {
+	return &entity.Ticket{
+		Ticket:    ticket,
+		UserID:    ticketModel.UserID,
+		Username:  ticketModel.Username,
+		SessionID: ticketModel.SessionID,
+		GuestID:   ticketModel.GuestID,
+		RoomID:    ticketModel.RoomID,
+		Origin:    ticketModel.Origin,
+		ExpiresAt: ticketModel.ExpiresAt,
+	}
+}
+
+func IdentityToTicketResponse(identity *entity.Identity) *TicketIdentityResponse {
+	return &TicketIdentityResponse{
+		UserID:     identity.UserID,
+		Username:   identity.Username,
+		SessionID:  identity.SessionID,
+		Roles:      identity.Roles,
+		Restricted: identity.Restricted,
+		Guest:      identity.Guest,
+		GuestID:    identity.GuestID,
+		RoomID:     identity.RoomID,
+	}
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// AuthContext describes how and when the user behind a session authenticated
+// and, for sessions issued to an OAuth client, what the client may do.
+type AuthContext struct {
+	ClientID string
+	Scope    string
+	AuthTime time.Time
+	AMR      []string
+	Roles    []string
+	// Restricted sessions belong to accounts whose email isn't verified yet.
+	Restricted bool
+}
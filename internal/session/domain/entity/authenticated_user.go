@@ -0,0 +1,9 @@
+package entity
+
+type AuthenticatedUser struct {
+	UserID   uint
+	Username string
+	Roles    []string
+	// Restricted marks an account that still has to confirm its email.
+	Restricted bool
+}
@@ -5,4 +5,29 @@ import "errors"
 var (
 	ErrNoSession      = errors.New("couldn't find session")
 	ErrAlreadyCreated = errors.New("session is already created")
+
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidEmail       = errors.New("email address is invalid")
+	ErrExternalAccount    = errors.New("account belongs to an external directory")
+	ErrAccountConflict    = errors.New("a local account already has the directory username")
+
+	ErrInvalidDisplayName = errors.New("display name is invalid")
+	ErrInvalidRoomID      = errors.New("room id is invalid")
+
+	ErrInvalidToken        = errors.New("invalid access token")
+	ErrTokenRevoked        = errors.New("access token has been revoked")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+
+	ErrNoTicket      = errors.New("couldn't find ticket")
+	ErrInvalidTicket = errors.New("ticket is invalid or has already been used")
+	ErrInvalidOrigin = errors.New("origin is invalid")
 )
+
+type MFARequiredError struct {
+	MFAToken string
+}
+
+func (e *MFARequiredError) Error() string {
+	return "second factor is required"
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+type Identity struct {
+	UserID     uint
+	Username   string
+	SessionID  string
+	Roles      []string
+	ClientID   string
+	Scope      string
+	AuthTime   time.Time
+	AMR        []string
+	Service    bool
+	Restricted bool
+	Guest      bool
+	GuestID    string
+	RoomID     string
+	ExpiresAt  time.Time
+}
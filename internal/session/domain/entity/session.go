@@ -3,10 +3,22 @@ package entity
 import "time"
 
 type Session struct {
+	ID               string
+	FamilyID         string
+	AccessJTI        string
+	RefreshJTI       string
 	JWTAccess        string
 	JWTRefresh       string
 	UserID           uint
 	Username         string
+	ClientID         string
+	Scope            string
+	AuthTime         time.Time
+	AMR              []string
+	Roles            []string
+	Restricted       bool
+	GuestID          string
+	RoomID           string
 	AccessExpiresAt  time.Time
 	RefreshExpiresAt time.Time
 }
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// Ticket stands in for the access token on a WebSocket upgrade, where browsers
+// can't send an Authorization header. It is opaque, short lived and can be
+// redeemed exactly once, from the origin it was issued for.
+type Ticket struct {
+	Ticket    string
+	UserID    uint
+	Username  string
+	SessionID string
+	GuestID   string
+	RoomID    string
+	Origin    string
+	ExpiresAt time.Time
+}
@@ -3,10 +3,22 @@ package model
 import "time"
 
 type Session struct {
+	ID               string    `json:"id"`
+	FamilyID         string    `json:"family_id"`
+	AccessJTI        string    `json:"access_jti"`
+	RefreshJTI       string    `json:"refresh_jti"`
 	JWTAccess        string    `json:"access_token"`
 	JWTRefresh       string    `json:"refresh_token"`
 	UserID           uint      `json:"user_id"`
 	Username         string    `json:"username"`
+	ClientID         string    `json:"client_id,omitempty"`
+	Scope            string    `json:"scope,omitempty"`
+	AuthTime         time.Time `json:"auth_time"`
+	AMR              []string  `json:"amr,omitempty"`
+	Roles            []string  `json:"roles,omitempty"`
+	Restricted       bool      `json:"restricted,omitempty"`
+	GuestID          string    `json:"guest_id,omitempty"`
+	RoomID           string    `json:"room_id,omitempty"`
 	AccessExpiresAt  time.Time `json:"access_expires_at"`
 	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type Ticket struct {
+	UserID    uint      `json:"user_id,omitempty"`
+	Username  string    `json:"username"`
+	SessionID string    `json:"session_id"`
+	GuestID   string    `json:"guest_id,omitempty"`
+	RoomID    string    `json:"room_id,omitempty"`
+	Origin    string    `json:"origin"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
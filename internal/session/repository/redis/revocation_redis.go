@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+type RevocationRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewRevocationRedisRepository(conn redis.Conn) *RevocationRedisRepository {
+	return &RevocationRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+func revokedTokenKey(jti string) string {
+	return "revoked_tokens:" + jti
+}
+
+func revokedBeforeKey(userID uint) string {
+	return "revoked_before:" + strconv.Itoa(int(userID))
+}
+
+func (repo *RevocationRedisRepository) RevokeToken(jti string, expiresAt time.Time) error {
+	ttl := int(time.Until(expiresAt).Seconds())
+	if ttl <= 0 {
+		return nil
+	}
+
+	repo.mu.Lock()
+	_, err := repo.redisConn.Do("SET", revokedTokenKey(jti), 1, "EX", ttl)
+	repo.mu.Unlock()
+
+	return err
+}
+
+// ClaimToken revokes a single-use token and reports whether this call was the
+// one that did, so two concurrent redemptions can't both succeed.
+func (repo *RevocationRedisRepository) ClaimToken(jti string, expiresAt time.Time) (bool, error) {
+	ttl := int(time.Until(expiresAt).Seconds())
+	if ttl <= 0 {
+		return false, nil
+	}
+
+	repo.mu.Lock()
+	_, err := redis.String(repo.redisConn.Do("SET", revokedTokenKey(jti), 1, "NX", "EX", ttl))
+	repo.mu.Unlock()
+
+	if err == redis.ErrNil {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ReleaseToken hands a claimed token back, for when the redemption it was
+// claimed for didn't go through.
+func (repo *RevocationRedisRepository) ReleaseToken(jti string) error {
+	repo.mu.Lock()
+	_, err := repo.redisConn.Do("DEL", revokedTokenKey(jti))
+	repo.mu.Unlock()
+
+	return err
+}
+
+func (repo *RevocationRedisRepository) IsTokenRevoked(jti string) (bool, error) {
+	repo.mu.Lock()
+	revoked, err := redis.Bool(repo.redisConn.Do("EXISTS", revokedTokenKey(jti)))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return false, err
+	}
+
+	return revoked, nil
+}
+
+func (repo *RevocationRedisRepository) SetRevokedBefore(userID uint, revokedBefore time.Time, ttl time.Duration) error {
+	repo.mu.Lock()
+	_, err := repo.redisConn.Do("SET", revokedBeforeKey(userID), revokedBefore.UnixMicro(), "EX", int(ttl.Seconds()))
+	repo.mu.Unlock()
+
+	return err
+}
+
+func (repo *RevocationRedisRepository) GetRevokedBefore(userID uint) (time.Time, error) {
+	repo.mu.Lock()
+	unixMicros, err := redis.Int64(repo.redisConn.Do("GET", revokedBeforeKey(userID)))
+	repo.mu.Unlock()
+
+	if err == redis.ErrNil {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.UnixMicro(unixMicros), nil
+}
@@ -25,8 +25,50 @@ func NewSessionRedisRepository(conn redis.Conn) *SessionRedisRepository {
 	}
 }
 
+var rotateScript = redis.NewScript(2, `
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return 0
+end
+local session = cjson.decode(current)
+if session['family_id'] ~= ARGV[1] or session['refresh_jti'] ~= ARGV[2] then
+	return -1
+end
+redis.call('SET', KEYS[1], ARGV[3], 'EX', ARGV[4])
+if redis.call('TTL', KEYS[2]) < tonumber(ARGV[4]) then
+	redis.call('EXPIRE', KEYS[2], ARGV[4])
+end
+return 1
+`)
+
+// The index outlives its longest session, so a short-lived session must not
+// pull its expiry in.
+var setScript = redis.NewScript(2, `
+redis.call('SET', KEYS[1], ARGV[1], 'EX', ARGV[2])
+redis.call('SADD', KEYS[2], ARGV[3])
+if redis.call('TTL', KEYS[2]) < tonumber(ARGV[2]) then
+	redis.call('EXPIRE', KEYS[2], ARGV[2])
+end
+return 1
+`)
+
+func sessionKey(userID uint, sessionID string) string {
+	return "sessions:" + strconv.Itoa(int(userID)) + ":" + sessionID
+}
+
+func userSessionsKey(userID uint) string {
+	return "user_sessions:" + strconv.Itoa(int(userID))
+}
+
+// Guest sessions have no user behind them, so they live in their own
+// namespace and are never listed or revoked together with user sessions.
+func guestSessionKey(guestID string, sessionID string) string {
+	return "guest_sessions:" + guestID + ":" + sessionID
+}
+
 func (repo *SessionRedisRepository) Set(sessionEntity *entity.Session) (*model.Session, error) {
-	mkey := "sessions:" + strconv.Itoa(int(sessionEntity.UserID))
+	mkey := sessionKey(sessionEntity.UserID, sessionEntity.ID)
+	indexKey := userSessionsKey(sessionEntity.UserID)
 
 	sessionModel := dto.SessionEntityToModel(sessionEntity)
 	sessionSerialized, err := json.Marshal(sessionModel)
@@ -34,26 +76,61 @@ func (repo *SessionRedisRepository) Set(sessionEntity *entity.Session) (*model.S
 		return nil, err
 	}
 
-	ttl := int(time.Until(sessionModel.RefreshExpiresAt))
+	ttl := int(time.Until(sessionModel.RefreshExpiresAt).Seconds())
+	if ttl <= 0 {
+		return nil, fmt.Errorf("session %s is already expired", sessionModel.ID)
+	}
 
 	repo.mu.Lock()
-	result, err := redis.String(repo.redisConn.Do("SET", mkey, sessionSerialized, "EX", ttl))
+	_, err = setScript.Do(repo.redisConn, mkey, indexKey, sessionSerialized, ttl, sessionModel.ID)
 	repo.mu.Unlock()
 
 	if err != nil {
-		fmt.Println("here 2")
 		return nil, err
 	}
 
-	if result != "OK" {
-		return nil, fmt.Errorf("unexpected Redis response: %v", result)
+	return sessionModel, nil
+}
+
+func (repo *SessionRedisRepository) Rotate(sessionEntity *entity.Session, presentedRefreshJTI string) (*model.Session, error) {
+	mkey := sessionKey(sessionEntity.UserID, sessionEntity.ID)
+	indexKey := userSessionsKey(sessionEntity.UserID)
+
+	sessionModel := dto.SessionEntityToModel(sessionEntity)
+	sessionSerialized, err := json.Marshal(sessionModel)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := int(time.Until(sessionModel.RefreshExpiresAt).Seconds())
+	if ttl <= 0 {
+		return nil, fmt.Errorf("session %s is already expired", sessionModel.ID)
+	}
+
+	repo.mu.Lock()
+	result, err := redis.Int(rotateScript.Do(
+		repo.redisConn,
+		mkey, indexKey,
+		sessionModel.FamilyID, presentedRefreshJTI, sessionSerialized, ttl,
+	))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch result {
+	case 0:
+		return nil, entity.ErrNoSession
+	case -1:
+		return nil, entity.ErrRefreshTokenReused
 	}
 
 	return sessionModel, nil
 }
 
-func (repo *SessionRedisRepository) Check(userID uint) (*model.Session, error) {
-	mkey := "sessions:" + strconv.Itoa(int(userID))
+func (repo *SessionRedisRepository) Get(userID uint, sessionID string) (*model.Session, error) {
+	mkey := sessionKey(userID, sessionID)
 
 	repo.mu.Lock()
 	bytes, err := redis.Bytes(repo.redisConn.Do("GET", mkey))
@@ -76,20 +153,154 @@ func (repo *SessionRedisRepository) Check(userID uint) (*model.Session, error) {
 	return session, nil
 }
 
-func (sm *SessionRedisRepository) Delete(userID uint) error {
-	mkey := "sessions:" + strconv.Itoa(int(userID))
+func (repo *SessionRedisRepository) List(userID uint) ([]*model.Session, error) {
+	indexKey := userSessionsKey(userID)
 
-	sm.mu.Lock()
-	_, err := redis.Int(sm.redisConn.Do("DEL", mkey))
-	sm.mu.Unlock()
+	repo.mu.Lock()
+	sessionIDs, err := redis.Strings(repo.redisConn.Do("SMEMBERS", indexKey))
+	repo.mu.Unlock()
 
-	if err == redis.ErrNil {
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*model.Session, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		session, err := repo.Get(userID, sessionID)
+		if err == entity.ErrNoSession {
+			repo.mu.Lock()
+			_, err = repo.redisConn.Do("SREM", indexKey, sessionID)
+			repo.mu.Unlock()
+
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+func (repo *SessionRedisRepository) DeleteAll(userID uint) error {
+	indexKey := userSessionsKey(userID)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	sessionIDs, err := redis.Strings(repo.redisConn.Do("SMEMBERS", indexKey))
+	if err != nil {
+		return err
+	}
+
+	keys := []interface{}{indexKey}
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, sessionKey(userID, sessionID))
+	}
+
+	_, err = repo.redisConn.Do("DEL", keys...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *SessionRedisRepository) Delete(userID uint, sessionID string) error {
+	mkey := sessionKey(userID, sessionID)
+
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("DEL", mkey)
+	repo.redisConn.Send("SREM", userSessionsKey(userID), sessionID)
+	result, err := redis.Ints(repo.redisConn.Do("EXEC"))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if len(result) == 0 || result[0] == 0 {
 		return entity.ErrNoSession
 	}
 
+	return nil
+}
+
+func (repo *SessionRedisRepository) SetGuest(sessionEntity *entity.Session) (*model.Session, error) {
+	mkey := guestSessionKey(sessionEntity.GuestID, sessionEntity.ID)
+
+	sessionModel := dto.SessionEntityToModel(sessionEntity)
+	sessionSerialized, err := json.Marshal(sessionModel)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := int(time.Until(sessionModel.AccessExpiresAt).Seconds())
+	if ttl <= 0 {
+		return nil, fmt.Errorf("session %s is already expired", sessionModel.ID)
+	}
+
+	repo.mu.Lock()
+	result, err := redis.String(repo.redisConn.Do("SET", mkey, sessionSerialized, "EX", ttl))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if result != "OK" {
+		return nil, fmt.Errorf("unexpected Redis response: %v", result)
+	}
+
+	return sessionModel, nil
+}
+
+func (repo *SessionRedisRepository) GetGuest(guestID string, sessionID string) (*model.Session, error) {
+	mkey := guestSessionKey(guestID, sessionID)
+
+	repo.mu.Lock()
+	bytes, err := redis.Bytes(repo.redisConn.Do("GET", mkey))
+	repo.mu.Unlock()
+
+	if err == redis.ErrNil {
+		return nil, entity.ErrNoSession
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	session := &model.Session{}
+	err = json.Unmarshal(bytes, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (repo *SessionRedisRepository) DeleteGuest(guestID string, sessionID string) error {
+	mkey := guestSessionKey(guestID, sessionID)
+
+	repo.mu.Lock()
+	deleted, err := redis.Int(repo.redisConn.Do("DEL", mkey))
+	repo.mu.Unlock()
+
 	if err != nil {
 		return err
 	}
 
+	if deleted == 0 {
+		return entity.ErrNoSession
+	}
+
 	return nil
 }
@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/session/domain/dto"
+	"github.com/lightlink/auth-service/internal/session/domain/entity"
+	"github.com/lightlink/auth-service/internal/session/domain/model"
+)
+
+type TicketRedisRepository struct {
+	redisConn redis.Conn
+	mu        *sync.Mutex
+}
+
+func NewTicketRedisRepository(conn redis.Conn) *TicketRedisRepository {
+	return &TicketRedisRepository{
+		redisConn: conn,
+		mu:        &sync.Mutex{},
+	}
+}
+
+func ticketKey(ticket string) string {
+	return "ws_tickets:" + ticket
+}
+
+func (repo *TicketRedisRepository) Set(ticketEntity *entity.Ticket) error {
+	ticketSerialized, err := json.Marshal(dto.TicketEntityToModel(ticketEntity))
+	if err != nil {
+		return err
+	}
+
+	ttl := int(time.Until(ticketEntity.ExpiresAt).Seconds())
+	if ttl <= 0 {
+		return entity.ErrInvalidTicket
+	}
+
+	repo.mu.Lock()
+	_, err = repo.redisConn.Do("SET", ticketKey(ticketEntity.Ticket), ticketSerialized, "EX", ttl)
+	repo.mu.Unlock()
+
+	return err
+}
+
+func (repo *TicketRedisRepository) Pop(ticket string) (*model.Ticket, error) {
+	repo.mu.Lock()
+	repo.redisConn.Send("MULTI")
+	repo.redisConn.Send("GET", ticketKey(ticket))
+	repo.redisConn.Send("DEL", ticketKey(ticket))
+	result, err := redis.Values(repo.redisConn.Do("EXEC"))
+	repo.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := redis.Bytes(result[0], nil)
+	if err == redis.ErrNil {
+		return nil, entity.ErrNoTicket
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	ticketModel := &model.Ticket{}
+	err = json.Unmarshal(bytes, ticketModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return ticketModel, nil
+}
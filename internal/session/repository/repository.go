@@ -1,12 +1,34 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/lightlink/auth-service/internal/session/domain/entity"
 	"github.com/lightlink/auth-service/internal/session/domain/model"
 )
 
 type SessionRepositoryI interface {
 	Set(sessionEntity *entity.Session) (*model.Session, error)
-	Check(userID uint) (*model.Session, error)
-	Delete(userID uint) error
+	Rotate(sessionEntity *entity.Session, presentedRefreshJTI string) (*model.Session, error)
+	Get(userID uint, sessionID string) (*model.Session, error)
+	List(userID uint) ([]*model.Session, error)
+	Delete(userID uint, sessionID string) error
+	DeleteAll(userID uint) error
+	SetGuest(sessionEntity *entity.Session) (*model.Session, error)
+	GetGuest(guestID string, sessionID string) (*model.Session, error)
+	DeleteGuest(guestID string, sessionID string) error
+}
+
+type RevocationRepositoryI interface {
+	RevokeToken(jti string, expiresAt time.Time) error
+	ClaimToken(jti string, expiresAt time.Time) (bool, error)
+	ReleaseToken(jti string) error
+	IsTokenRevoked(jti string) (bool, error)
+	SetRevokedBefore(userID uint, revokedBefore time.Time, ttl time.Duration) error
+	GetRevokedBefore(userID uint) (time.Time, error)
+}
+
+type TicketRepositoryI interface {
+	Set(ticketEntity *entity.Ticket) error
+	Pop(ticket string) (*model.Ticket, error)
 }
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dgrijalva/jwt-go"
+	sessionDTO "github.com/lightlink/auth-service/internal/session/domain/dto"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+)
+
+const (
+	defaultGuestSessionTTL = time.Hour
+
+	maxDisplayNameLength = 64
+)
+
+var roomIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// CreateGuestSession lets someone without an account into a single meeting.
+// The guest exists only as long as its session: nothing is written to the user
+// service and there is no refresh token, so the session simply runs out.
+// It is not exposed on its own; guests get in by redeeming an invite to the
+// room, which is where the caller is checked.
+func (uc *SessionUsecase) CreateGuestSession(displayName string, roomID string) (*sessionEntity.Session, error) {
+	displayName = strings.TrimSpace(displayName)
+	if !validDisplayName(displayName) {
+		return nil, sessionEntity.ErrInvalidDisplayName
+	}
+
+	if !roomIDPattern.MatchString(roomID) {
+		return nil, sessionEntity.ErrInvalidRoomID
+	}
+
+	guestID, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	jti, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(uc.guestSessionTTL)
+	tokenString, err := uc.tokenSigner.Sign(jwt.MapClaims{
+		"typ": tokenTypeGuest,
+		"guest": map[string]string{
+			"id":   guestID,
+			"name": displayName,
+		},
+		"room": roomID,
+		"sid":  sessionID,
+		"jti":  jti,
+		"iat":  now.UTC().Unix(),
+		"exp":  expiresAt.UTC().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	createdSessionModel, err := uc.sessionRepo.SetGuest(&sessionEntity.Session{
+		ID:               sessionID,
+		AccessJTI:        jti,
+		JWTAccess:        tokenString,
+		Username:         displayName,
+		GuestID:          guestID,
+		RoomID:           roomID,
+		AuthTime:         now,
+		AccessExpiresAt:  expiresAt,
+		RefreshExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionDTO.SessionModelToEntity(createdSessionModel), nil
+}
+
+func (uc *SessionUsecase) DeleteGuestSession(guestID string, sessionID string) error {
+	sessionModel, err := uc.sessionRepo.GetGuest(guestID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.revocationRepo.RevokeToken(sessionModel.AccessJTI, sessionModel.AccessExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	return uc.sessionRepo.DeleteGuest(guestID, sessionID)
+}
+
+// validateGuestClaims also requires the stored session, so a guest that was
+// removed from the meeting loses access before its token expires.
+func (uc *SessionUsecase) validateGuestClaims(claims jwt.MapClaims) (*sessionEntity.Identity, error) {
+	guestID, displayName, ok := guestFromClaims(claims)
+	if !ok {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	sessionID, _ := claims["sid"].(string)
+	roomID, _ := claims["room"].(string)
+	if sessionID == "" || roomID == "" {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti != "" {
+		revoked, err := uc.revocationRepo.IsTokenRevoked(jti)
+		if err != nil {
+			return nil, err
+		}
+
+		if revoked {
+			return nil, sessionEntity.ErrTokenRevoked
+		}
+	}
+
+	sessionModel, err := uc.sessionRepo.GetGuest(guestID, sessionID)
+	if err == sessionEntity.ErrNoSession {
+		return nil, sessionEntity.ErrTokenRevoked
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if sessionModel.RoomID != roomID {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	expiresAt, _ := claims["exp"].(float64)
+
+	return &sessionEntity.Identity{
+		Username:  displayName,
+		SessionID: sessionID,
+		Roles:     []string{},
+		AuthTime:  sessionModel.AuthTime,
+		Guest:     true,
+		GuestID:   guestID,
+		RoomID:    roomID,
+		ExpiresAt: time.Unix(int64(expiresAt), 0),
+	}, nil
+}
+
+func (uc *SessionUsecase) revokeGuestClaims(claims jwt.MapClaims) error {
+	guestID, _, ok := guestFromClaims(claims)
+	sessionID, _ := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		return nil
+	}
+
+	err := uc.sessionRepo.DeleteGuest(guestID, sessionID)
+	if err == sessionEntity.ErrNoSession {
+		return nil
+	}
+
+	return err
+}
+
+func guestFromClaims(claims jwt.MapClaims) (string, string, bool) {
+	claimsGuest, ok := claims["guest"].(map[string]interface{})
+	if !ok {
+		return "", "", false
+	}
+
+	guestID, _ := claimsGuest["id"].(string)
+	if guestID == "" {
+		return "", "", false
+	}
+
+	displayName, _ := claimsGuest["name"].(string)
+
+	return guestID, displayName, true
+}
+
+func validDisplayName(displayName string) bool {
+	if displayName == "" || utf8.RuneCountInString(displayName) > maxDisplayNameLength {
+		return false
+	}
+
+	for _, r := range displayName {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCreateGuestSessionTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		wantTTL time.Duration
+	}{
+		{"default", 0, defaultGuestSessionTTL},
+		{"configured", 10 * time.Minute, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			uc := NewSessionUsecase(env.uc.sessionRepo, env.uc.revocationRepo, env.uc.ticketRepo, env.uc.userRepo, env.uc.mfaUC, env.uc.tokenSigner, env.uc.authenticator, tt.ttl)
+
+			before := time.Now()
+			session, err := uc.CreateGuestSession("Guest", "room-1")
+			if err != nil {
+				t.Fatalf("CreateGuestSession: %v", err)
+			}
+
+			ttl := session.AccessExpiresAt.Sub(before)
+			if ttl < tt.wantTTL-time.Second || ttl > tt.wantTTL+time.Second {
+				t.Errorf("guest session lives %v, want %v", ttl, tt.wantTTL)
+			}
+
+			identity, err := uc.ValidateAccessToken(session.JWTAccess)
+			if err != nil {
+				t.Fatalf("ValidateAccessToken: %v", err)
+			}
+
+			if !identity.Guest || identity.RoomID != "room-1" {
+				t.Errorf("identity guest = %v room %q, want a guest in room-1", identity.Guest, identity.RoomID)
+			}
+		})
+	}
+}
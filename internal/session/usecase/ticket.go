@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"time"
+
+	sessionDTO "github.com/lightlink/auth-service/internal/session/domain/dto"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	"github.com/lightlink/auth-service/internal/session/domain/model"
+)
+
+const ticketTTL = 30 * time.Second
+
+// IssueTicket hands out a ticket for the session behind identity. The ticket
+// is only worth anything to a page served from origin.
+func (uc *SessionUsecase) IssueTicket(identity *sessionEntity.Identity, origin string) (*sessionEntity.Ticket, error) {
+	origin, err := normalizeOrigin(origin)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionModel, err := uc.ticketSession(identity.UserID, identity.GuestID, identity.SessionID)
+	if err == sessionEntity.ErrNoSession {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 32)
+	_, err = rand.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket := &sessionEntity.Ticket{
+		Ticket:    base64.RawURLEncoding.EncodeToString(buf),
+		UserID:    sessionModel.UserID,
+		Username:  sessionModel.Username,
+		SessionID: sessionModel.ID,
+		GuestID:   sessionModel.GuestID,
+		RoomID:    sessionModel.RoomID,
+		Origin:    origin,
+		ExpiresAt: time.Now().Add(ticketTTL),
+	}
+
+	err = uc.ticketRepo.Set(ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	return ticket, nil
+}
+
+// RedeemTicket consumes the ticket whether or not the origin matches, so a
+// leaked ticket can't be retried from elsewhere.
+func (uc *SessionUsecase) RedeemTicket(ticket string, origin string) (*sessionEntity.Identity, error) {
+	origin, err := normalizeOrigin(origin)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketModel, err := uc.ticketRepo.Pop(ticket)
+	if err == sessionEntity.ErrNoTicket {
+		return nil, sessionEntity.ErrInvalidTicket
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	ticketEntity := sessionDTO.TicketModelToEntity(ticket, ticketModel)
+	if ticketEntity.Origin != origin || time.Now().After(ticketEntity.ExpiresAt) {
+		return nil, sessionEntity.ErrInvalidTicket
+	}
+
+	sessionModel, err := uc.ticketSession(ticketEntity.UserID, ticketEntity.GuestID, ticketEntity.SessionID)
+	if err == sessionEntity.ErrNoSession {
+		return nil, sessionEntity.ErrInvalidTicket
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	roles := sessionModel.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+
+	return &sessionEntity.Identity{
+		UserID:     ticketEntity.UserID,
+		Username:   ticketEntity.Username,
+		SessionID:  ticketEntity.SessionID,
+		Roles:      roles,
+		AuthTime:   sessionModel.AuthTime,
+		AMR:        sessionModel.AMR,
+		Restricted: sessionModel.Restricted,
+		Guest:      ticketEntity.GuestID != "",
+		GuestID:    ticketEntity.GuestID,
+		RoomID:     ticketEntity.RoomID,
+		ExpiresAt:  sessionModel.AccessExpiresAt,
+	}, nil
+}
+
+func (uc *SessionUsecase) ticketSession(userID uint, guestID string, sessionID string) (*model.Session, error) {
+	if guestID != "" {
+		return uc.sessionRepo.GetGuest(guestID, sessionID)
+	}
+
+	if userID == 0 || sessionID == "" {
+		return nil, sessionEntity.ErrNoSession
+	}
+
+	return uc.sessionRepo.Get(userID, sessionID)
+}
+
+// normalizeOrigin accepts a serialized web origin and returns it in the form
+// browsers send in the Origin header.
+func normalizeOrigin(origin string) (string, error) {
+	parsedOrigin, err := url.Parse(strings.TrimSpace(origin))
+	if err != nil {
+		return "", sessionEntity.ErrInvalidOrigin
+	}
+
+	if parsedOrigin.Scheme != "http" && parsedOrigin.Scheme != "https" {
+		return "", sessionEntity.ErrInvalidOrigin
+	}
+
+	if parsedOrigin.Host == "" || parsedOrigin.User != nil || (parsedOrigin.Path != "" && parsedOrigin.Path != "/") ||
+		parsedOrigin.RawQuery != "" || parsedOrigin.Fragment != "" {
+		return "", sessionEntity.ErrInvalidOrigin
+	}
+
+	return parsedOrigin.Scheme + "://" + strings.ToLower(parsedOrigin.Host), nil
+}
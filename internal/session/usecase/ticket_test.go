@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+)
+
+const testOrigin = "https://app.example.com"
+
+func (env *testEnv) issueTicket(t *testing.T, username string) string {
+	t.Helper()
+
+	session := env.login(t, username)
+	ticket, err := env.uc.IssueTicket(&sessionEntity.Identity{UserID: session.UserID, SessionID: session.ID}, testOrigin)
+	if err != nil {
+		t.Fatalf("IssueTicket(%s): %v", username, err)
+	}
+
+	return ticket.Ticket
+}
+
+func TestRedeemTicket(t *testing.T) {
+	tests := []struct {
+		name           string
+		username       string
+		wantRoles      []string
+		wantRestricted bool
+	}{
+		{"roles", "alice", []string{"admin"}, false},
+		{"restricted", "bob", []string{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			ticket := env.issueTicket(t, tt.username)
+
+			identity, err := env.uc.RedeemTicket(ticket, "https://APP.example.com/")
+			if err != nil {
+				t.Fatalf("RedeemTicket: %v", err)
+			}
+
+			if identity.Username != tt.username || identity.Roles == nil || strings.Join(identity.Roles, ",") != strings.Join(tt.wantRoles, ",") {
+				t.Errorf("identity = %q roles %#v, want %q roles %v", identity.Username, identity.Roles, tt.username, tt.wantRoles)
+			}
+
+			if identity.Restricted != tt.wantRestricted {
+				t.Errorf("identity restricted = %v, want %v", identity.Restricted, tt.wantRestricted)
+			}
+
+			_, err = env.uc.RedeemTicket(ticket, testOrigin)
+			if !errors.Is(err, sessionEntity.ErrInvalidTicket) {
+				t.Errorf("second RedeemTicket err = %v, want %v", err, sessionEntity.ErrInvalidTicket)
+			}
+		})
+	}
+}
+
+func TestRedeemTicketWrongOriginConsumes(t *testing.T) {
+	env := newTestEnv(t)
+	ticket := env.issueTicket(t, "alice")
+
+	_, err := env.uc.RedeemTicket(ticket, "https://evil.example.com")
+	if !errors.Is(err, sessionEntity.ErrInvalidTicket) {
+		t.Fatalf("RedeemTicket from another origin err = %v, want %v", err, sessionEntity.ErrInvalidTicket)
+	}
+
+	_, err = env.uc.RedeemTicket(ticket, testOrigin)
+	if !errors.Is(err, sessionEntity.ErrInvalidTicket) {
+		t.Fatalf("RedeemTicket after a wrong origin err = %v, want %v", err, sessionEntity.ErrInvalidTicket)
+	}
+}
+
+func TestRedeemTicketGuest(t *testing.T) {
+	env := newTestEnv(t)
+
+	guestSession, err := env.uc.CreateGuestSession("Visitor", "room-1")
+	if err != nil {
+		t.Fatalf("CreateGuestSession: %v", err)
+	}
+
+	ticket, err := env.uc.IssueTicket(&sessionEntity.Identity{GuestID: guestSession.GuestID, SessionID: guestSession.ID}, testOrigin)
+	if err != nil {
+		t.Fatalf("IssueTicket: %v", err)
+	}
+
+	identity, err := env.uc.RedeemTicket(ticket.Ticket, testOrigin)
+	if err != nil {
+		t.Fatalf("RedeemTicket: %v", err)
+	}
+
+	if !identity.Guest || identity.GuestID != guestSession.GuestID || identity.RoomID != "room-1" || len(identity.Roles) != 0 {
+		t.Errorf("identity guest %v id %q room %q roles %v, want guest %s of room-1 without roles", identity.Guest, identity.GuestID, identity.RoomID, identity.Roles, guestSession.GuestID)
+	}
+}
+
+func TestRedeemTicketEndedSession(t *testing.T) {
+	env := newTestEnv(t)
+
+	session := env.login(t, "alice")
+	ticket, err := env.uc.IssueTicket(&sessionEntity.Identity{UserID: session.UserID, SessionID: session.ID}, testOrigin)
+	if err != nil {
+		t.Fatalf("IssueTicket: %v", err)
+	}
+
+	err = env.uc.Delete(session.UserID, session.ID)
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	_, err = env.uc.RedeemTicket(ticket.Ticket, testOrigin)
+	if !errors.Is(err, sessionEntity.ErrInvalidTicket) {
+		t.Fatalf("RedeemTicket after logout err = %v, want %v", err, sessionEntity.ErrInvalidTicket)
+	}
+}
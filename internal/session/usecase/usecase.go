@@ -1,16 +1,23 @@
 package usecase
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"os"
+	"log"
+	"net/mail"
 	"strconv"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	mfaEntity "github.com/lightlink/auth-service/internal/mfa/domain/entity"
+	mfaUsecase "github.com/lightlink/auth-service/internal/mfa/usecase"
+	"github.com/lightlink/auth-service/internal/session/authenticator"
 	sessionDTO "github.com/lightlink/auth-service/internal/session/domain/dto"
 	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
 	sessionRepo "github.com/lightlink/auth-service/internal/session/repository"
+	"github.com/lightlink/auth-service/internal/token/signer"
 	userDTO "github.com/lightlink/auth-service/internal/user/domain/dto"
 	userEntity "github.com/lightlink/auth-service/internal/user/domain/entity"
 	userRepo "github.com/lightlink/auth-service/internal/user/repository"
@@ -21,27 +28,91 @@ import (
 type SessionUsecaseI interface {
 	Signup(signupRequest *sessionDTO.SignupRequest) (*sessionEntity.Session, error)
 	Login(loginRequest *sessionDTO.LoginRequest) (*sessionEntity.Session, error)
+	LoginMFA(loginMFARequest *sessionDTO.LoginMFARequest) (*sessionEntity.Session, error)
+	CreateSession(username string, userID uint, amr []string) (*sessionEntity.Session, error)
+	CreateDelegatedSession(username string, userID uint, authContext *sessionEntity.AuthContext) (*sessionEntity.Session, error)
+	CreateIDToken(session *sessionEntity.Session, extraClaims jwt.MapClaims) (string, error)
+	CreateServiceToken(clientID string, scope string, ttl time.Duration) (*sessionEntity.Session, error)
+	CreateGuestSession(displayName string, roomID string) (*sessionEntity.Session, error)
+	DeleteGuestSession(guestID string, sessionID string) error
+	IssueTicket(identity *sessionEntity.Identity, origin string) (*sessionEntity.Ticket, error)
+	RedeemTicket(ticket string, origin string) (*sessionEntity.Identity, error)
 	RefreshSession(refreshToken *jwt.Token) (*sessionEntity.Session, error)
-	Delete(userID uint) error
+	ParseToken(tokenString string) (*jwt.Token, error)
+	ValidateAccessToken(tokenString string) (*sessionEntity.Identity, error)
+	ValidateRefreshToken(tokenString string) (*sessionEntity.Identity, error)
+	RevokeToken(token *jwt.Token) error
+	ListSessions(userID uint) ([]*sessionEntity.Session, error)
+	GetSession(userID uint, sessionID string) (*sessionEntity.Session, error)
+	Delete(userID uint, sessionID string) error
+	RevokeAllSessions(userID uint) error
 	/*TODO*/
-	// Create(signupRequest *sessionDTO.SignupRequest) (*sessionEntity.Session, error)
 	// Check(userID uint) (*sessionEntity.Session, error)
 }
 
+// Every token a user holds is younger than the refresh lifetime, so a
+// revocation watermark can be dropped once that much time has passed.
+const revokedBeforeTTL = 24 * time.Hour
+
+const (
+	tokenTypeAccess     = "access"
+	tokenTypeRefresh    = "refresh"
+	tokenTypeMFAPending = "mfa_pending"
+	tokenTypeID         = "id"
+	tokenTypeService    = "service"
+	tokenTypeGuest      = "guest"
+)
+
+// Authentication method references, RFC 8176.
+const (
+	AMRPassword     = "pwd"
+	AMROneTimeCode  = "otp"
+	AMRMultiFactor  = "mfa"
+	AMRHardwareKey  = "hwk"
+	AMRUserPresence = "user"
+)
+
 type SessionUsecase struct {
-	sessionRepo sessionRepo.SessionRepositoryI
-	userRepo    userRepo.UserRepositoryI
+	sessionRepo     sessionRepo.SessionRepositoryI
+	revocationRepo  sessionRepo.RevocationRepositoryI
+	ticketRepo      sessionRepo.TicketRepositoryI
+	userRepo        userRepo.UserRepositoryI
+	mfaUC           mfaUsecase.MFAUsecaseI
+	tokenSigner     signer.Signer
+	authenticator   authenticator.Authenticator
+	guestSessionTTL time.Duration
 }
 
-func NewSessionUsecase(sessionRepository sessionRepo.SessionRepositoryI, userRepository userRepo.UserRepositoryI) *SessionUsecase {
+func NewSessionUsecase(sessionRepository sessionRepo.SessionRepositoryI, revocationRepository sessionRepo.RevocationRepositoryI, ticketRepository sessionRepo.TicketRepositoryI, userRepository userRepo.UserRepositoryI, mfaUsecase mfaUsecase.MFAUsecaseI, tokenSigner signer.Signer, passwordAuthenticator authenticator.Authenticator, guestSessionTTL time.Duration) *SessionUsecase {
+	if guestSessionTTL <= 0 {
+		guestSessionTTL = defaultGuestSessionTTL
+	}
+
 	return &SessionUsecase{
-		sessionRepo: sessionRepository,
-		userRepo:    userRepository,
+		sessionRepo:     sessionRepository,
+		revocationRepo:  revocationRepository,
+		ticketRepo:      ticketRepository,
+		userRepo:        userRepository,
+		mfaUC:           mfaUsecase,
+		tokenSigner:     tokenSigner,
+		authenticator:   passwordAuthenticator,
+		guestSessionTTL: guestSessionTTL,
 	}
 }
 
+// Signup leaves the new account restricted until the address it signed up
+// with is confirmed.
 func (uc *SessionUsecase) Signup(signupRequest *sessionDTO.SignupRequest) (*sessionEntity.Session, error) {
-	_, err := uc.userRepo.GetByUsername(signupRequest.Username)
+	address, err := mail.ParseAddress(signupRequest.Email)
+	if err != nil || address.Name != "" {
+		return nil, sessionEntity.ErrInvalidEmail
+	}
+
+	if uc.authenticator.IsExternal(signupRequest.Username) {
+		return nil, sessionEntity.ErrExternalAccount
+	}
+
+	_, err = uc.userRepo.GetByUsername(signupRequest.Username)
 	if err == nil {
 		return nil, userEntity.ErrAlreadyCreated
 	}
@@ -60,59 +131,186 @@ func (uc *SessionUsecase) Signup(signupRequest *sessionDTO.SignupRequest) (*sess
 		return nil, err
 	}
 
-	session, err := formSignedSession(
+	authContext := newAuthContext(AMRPassword)
+	authContext.Restricted = true
+
+	return uc.createSession(
 		signupRequest.Username,
 		createdUser.Id,
+		authContext,
 		time.Now().Add(15*time.Minute), /*TODO*/
 		time.Now().Add(24*time.Hour),   /*TODO*/
 	)
+}
+
+func (uc *SessionUsecase) Login(loginRequest *sessionDTO.LoginRequest) (*sessionEntity.Session, error) {
+	user, err := uc.authenticator.Authenticate(loginRequest.Username, loginRequest.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	mfaEnabled, err := uc.mfaUC.IsEnabled(user.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	sessionCheck, err := uc.sessionRepo.Check(uint(session.UserID))
-	if err == sessionEntity.ErrAlreadyCreated {
-		return sessionDTO.SessionModelToEntity(sessionCheck), nil
+	authContext := newAuthContext(AMRPassword)
+	authContext.Roles = user.Roles
+	authContext.Restricted = user.Restricted
+
+	if mfaEnabled {
+		return nil, uc.requireMFA(user.Username, user.UserID, authContext)
+	}
+
+	return uc.createSession(
+		user.Username,
+		user.UserID,
+		authContext,
+		time.Now().Add(1*time.Minute), /*TODO*/
+		time.Now().Add(24*time.Hour),  /*TODO*/
+	)
+}
+
+func (uc *SessionUsecase) LoginMFA(loginMFARequest *sessionDTO.LoginMFARequest) (*sessionEntity.Session, error) {
+	token, err := uc.ParseToken(loginMFARequest.MFAToken)
+	if err != nil || !token.Valid {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != tokenTypeMFAPending {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	claimsUser, ok := claims["user"].(map[string]interface{})
+	if !ok {
+		return nil, sessionEntity.ErrInvalidToken
 	}
 
-	if err != sessionEntity.ErrNoSession {
+	userIDString, _ := claimsUser["id"].(string)
+	userID64, err := strconv.ParseUint(userIDString, 10, 32)
+	if err != nil || userID64 == 0 {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	username, _ := claimsUser["username"].(string)
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	// A pending token is good for a single login, so it's claimed before the
+	// code is checked and handed back only if the code is wrong.
+	expiresAt, _ := claims["exp"].(float64)
+	claimed, err := uc.revocationRepo.ClaimToken(jti, time.Unix(int64(expiresAt), 0))
+	if err != nil {
+		return nil, err
+	}
+
+	if !claimed {
+		return nil, sessionEntity.ErrTokenRevoked
+	}
+
+	// Once the attempts run out the pending token stays burnt, so getting
+	// another go means proving the password again.
+	err = uc.mfaUC.Verify(uint(userID64), loginMFARequest.Code)
+	if errors.Is(err, mfaEntity.ErrTooManyAttempts) {
 		return nil, err
 	}
 
-	createdSessionModel, err := uc.sessionRepo.Set(session)
 	if err != nil {
+		releaseErr := uc.revocationRepo.ReleaseToken(jti)
+		if releaseErr != nil {
+			return nil, releaseErr
+		}
+
 		return nil, err
 	}
 
-	createdSessionEntity := sessionDTO.SessionModelToEntity(createdSessionModel)
+	// The first factor is whatever the pending token was issued for: a
+	// password, or a federated login.
+	pendingContext := authContextFromClaims(claims)
+	firstFactor := pendingContext.AMR
+	if len(firstFactor) == 0 {
+		firstFactor = []string{AMRPassword}
+	}
 
-	return createdSessionEntity, nil
+	authContext := newAuthContext(append(firstFactor, AMROneTimeCode, AMRMultiFactor)...)
+	authContext.Roles = pendingContext.Roles
+	authContext.Restricted = pendingContext.Restricted
+
+	return uc.createSession(
+		username,
+		uint(userID64),
+		authContext,
+		time.Now().Add(1*time.Minute), /*TODO*/
+		time.Now().Add(24*time.Hour),  /*TODO*/
+	)
 }
 
-func (uc *SessionUsecase) Login(loginRequest *sessionDTO.LoginRequest) (*sessionEntity.Session, error) {
-	user, err := uc.userRepo.GetByUsername(loginRequest.Username)
+// CreateSession is used by logins that don't go through a password, so the
+// verification state comes from the user service rather than an
+// authenticator. Users with a second factor enrolled still have to present
+// it unless amr already shows one.
+func (uc *SessionUsecase) CreateSession(username string, userID uint, amr []string) (*sessionEntity.Session, error) {
+	restricted, err := uc.isEmailUnverified(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	authContext := newAuthContext(amr...)
+	authContext.Restricted = restricted
+
+	if !isMultiFactor(amr) {
+		mfaEnabled, err := uc.mfaUC.IsEnabled(userID)
+		if err != nil {
+			return nil, err
+		}
 
-	if st, ok := status.FromError(err); !ok || st.Code() == codes.NotFound {
-		return nil, errors.New("should signup first")
+		if mfaEnabled {
+			return nil, uc.requireMFA(username, userID, authContext)
+		}
 	}
 
+	return uc.createSession(
+		username,
+		userID,
+		authContext,
+		time.Now().Add(15*time.Minute), /*TODO*/
+		time.Now().Add(24*time.Hour),   /*TODO*/
+	)
+}
+
+func (uc *SessionUsecase) CreateDelegatedSession(username string, userID uint, authContext *sessionEntity.AuthContext) (*sessionEntity.Session, error) {
+	return uc.createSession(
+		username,
+		userID,
+		authContext,
+		time.Now().Add(15*time.Minute), /*TODO*/
+		time.Now().Add(24*time.Hour),   /*TODO*/
+	)
+}
+
+func (uc *SessionUsecase) createSession(username string, userID uint, authContext *sessionEntity.AuthContext, accessTokenTTL time.Time, refreshTokenTTL time.Time) (*sessionEntity.Session, error) {
+	sessionID, err := generateID()
 	if err != nil {
 		return nil, err
 	}
 
-	checkedSessionModel, err := uc.sessionRepo.Check(user.Id)
-	if err == nil {
-		return sessionDTO.SessionModelToEntity(checkedSessionModel), nil
-	}
-	if err != sessionEntity.ErrNoSession {
+	familyID, err := generateID()
+	if err != nil {
 		return nil, err
 	}
 
-	session, err := formSignedSession(
-		loginRequest.Username,
-		user.Id,
-		time.Now().Add(1*time.Minute), /*TODO*/
-		time.Now().Add(24*time.Hour),  /*TODO*/
+	session, err := uc.formSignedSession(
+		sessionID,
+		familyID,
+		username,
+		userID,
+		authContext,
+		accessTokenTTL,
+		refreshTokenTTL,
 	)
 	if err != nil {
 		return nil, err
@@ -128,8 +326,18 @@ func (uc *SessionUsecase) Login(loginRequest *sessionDTO.LoginRequest) (*session
 	return createdSessionEntity, nil
 }
 
-func (uc *SessionUsecase) Delete(userID uint) error {
-	err := uc.sessionRepo.Delete(userID)
+func (uc *SessionUsecase) Delete(userID uint, sessionID string) error {
+	sessionModel, err := uc.sessionRepo.Get(userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.revocationRepo.RevokeToken(sessionModel.AccessJTI, sessionModel.AccessExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	err = uc.sessionRepo.Delete(userID, sessionID)
 	if err != nil {
 		return err
 	}
@@ -137,10 +345,53 @@ func (uc *SessionUsecase) Delete(userID uint) error {
 	return nil
 }
 
+func (uc *SessionUsecase) RevokeAllSessions(userID uint) error {
+	err := uc.revocationRepo.SetRevokedBefore(userID, time.Now(), revokedBeforeTTL)
+	if err != nil {
+		return err
+	}
+
+	err = uc.sessionRepo.DeleteAll(userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (uc *SessionUsecase) ListSessions(userID uint) ([]*sessionEntity.Session, error) {
+	sessionModels, err := uc.sessionRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*sessionEntity.Session, 0, len(sessionModels))
+	for _, sessionModel := range sessionModels {
+		sessions = append(sessions, sessionDTO.SessionModelToEntity(sessionModel))
+	}
+
+	return sessions, nil
+}
+
+func (uc *SessionUsecase) GetSession(userID uint, sessionID string) (*sessionEntity.Session, error) {
+	sessionModel, err := uc.sessionRepo.Get(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionDTO.SessionModelToEntity(sessionModel), nil
+}
+
 func (uc *SessionUsecase) RefreshSession(refreshToken *jwt.Token) (*sessionEntity.Session, error) {
 	claims, ok := refreshToken.Claims.(jwt.MapClaims)
 	if !ok || !refreshToken.Valid {
-		return nil, errors.New("invalid refresh token")
+		return nil, sessionEntity.ErrInvalidRefreshToken
+	}
+
+	// Tokens minted before typ existed carry none, so a missing typ has to
+	// be refused as well or an old refresh token passes for the other kind.
+	if claims["typ"] != tokenTypeRefresh {
+		return nil, sessionEntity.ErrInvalidRefreshToken
 	}
 
 	claimsUser, ok := claims["user"].(map[string]interface{})
@@ -172,14 +423,51 @@ func (uc *SessionUsecase) RefreshSession(refreshToken *jwt.Token) (*sessionEntit
 		return nil, errors.New("Couldn't cast username to string")
 	}
 
-	_, err = uc.sessionRepo.Check(userID)
-	if err != nil {
-		return nil, err
+	sessionID, ok := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		/*Handle*/
+		fmt.Println("Session id is missing")
+		return nil, errors.New("Session id is missing")
+	}
+
+	refreshJTI, ok := claims["jti"].(string)
+	if !ok || refreshJTI == "" {
+		return nil, sessionEntity.ErrInvalidRefreshToken
+	}
+
+	familyID, ok := claims["fam"].(string)
+	if !ok || familyID == "" {
+		return nil, sessionEntity.ErrInvalidRefreshToken
 	}
 
-	updatedSessionEntity, err := formSignedSession(
+	authContext := authContextFromClaims(claims)
+	if authContext.Restricted {
+		authContext.Restricted, err = uc.isEmailUnverified(userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A directory account's roles come from its groups, which may have
+	// changed since login, so they're looked up again instead of carried
+	// forward, and an account the directory dropped can't refresh.
+	if uc.authenticator.IsExternal(username) {
+		authContext.Roles, err = uc.authenticator.Roles(username)
+		if errors.Is(err, sessionEntity.ErrInvalidCredentials) {
+			return nil, sessionEntity.ErrInvalidRefreshToken
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	updatedSessionEntity, err := uc.formSignedSession(
+		sessionID,
+		familyID,
 		username,
 		userID,
+		authContext,
 		time.Now().Add(15*time.Minute), /*TODO*/
 		time.Now().Add(24*time.Hour),   /*TODO*/
 	)
@@ -187,7 +475,19 @@ func (uc *SessionUsecase) RefreshSession(refreshToken *jwt.Token) (*sessionEntit
 		return nil, err
 	}
 
-	_, err = uc.sessionRepo.Set(updatedSessionEntity)
+	_, err = uc.sessionRepo.Rotate(updatedSessionEntity, refreshJTI)
+	if err == sessionEntity.ErrRefreshTokenReused {
+		log.Printf("refresh token reuse detected: user %d, session %s, family %s, jti %s",
+			userID, sessionID, familyID, refreshJTI)
+
+		revokeErr := uc.revokeFamily(userID, familyID)
+		if revokeErr != nil {
+			log.Printf("couldn't revoke token family %s: %v", familyID, revokeErr)
+		}
+
+		return nil, err
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -195,17 +495,453 @@ func (uc *SessionUsecase) RefreshSession(refreshToken *jwt.Token) (*sessionEntit
 	return updatedSessionEntity, nil
 }
 
-func createJWT(username string, ttl time.Time, userID uint) (string, error) {
-	jwtTokenKey := []byte(os.Getenv("TOKEN_KEY"))
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+func (uc *SessionUsecase) revokeFamily(userID uint, familyID string) error {
+	sessions, err := uc.sessionRepo.List(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.FamilyID != familyID {
+			continue
+		}
+
+		err = uc.Delete(userID, session.ID)
+		if err != nil && err != sessionEntity.ErrNoSession {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateIDToken carries no username of its own: the caller adds one among
+// extraClaims only when the profile scope was granted.
+func (uc *SessionUsecase) CreateIDToken(session *sessionEntity.Session, extraClaims jwt.MapClaims) (string, error) {
+	now := time.Now().UTC()
+	claims := jwt.MapClaims{
+		"typ":       tokenTypeID,
+		"sub":       strconv.Itoa(int(session.UserID)),
+		"aud":       session.ClientID,
+		"sid":       session.ID,
+		"auth_time": session.AuthTime.UTC().Unix(),
+		"amr":       session.AMR,
+		"iat":       now.Unix(),
+		"exp":       session.AccessExpiresAt.UTC().Unix(),
+	}
+	for name, value := range extraClaims {
+		claims[name] = value
+	}
+
+	return uc.tokenSigner.Sign(claims)
+}
+
+// requireMFA returns the error carrying the pending token that LoginMFA
+// exchanges for a session once the second factor checks out.
+func (uc *SessionUsecase) requireMFA(username string, userID uint, authContext *sessionEntity.AuthContext) error {
+	mfaJTI, err := generateID()
+	if err != nil {
+		return err
+	}
+
+	mfaToken, err := uc.createJWT(username, userID, time.Now().Add(5*time.Minute), jwt.MapClaims{
+		"typ":        tokenTypeMFAPending,
+		"jti":        mfaJTI,
+		"amr":        authContext.AMR,
+		"roles":      authContext.Roles,
+		"restricted": authContext.Restricted,
+	})
+	if err != nil {
+		return err
+	}
+
+	return &sessionEntity.MFARequiredError{
+		MFAToken: mfaToken,
+	}
+}
+
+func isMultiFactor(amr []string) bool {
+	for _, method := range amr {
+		if method == AMRMultiFactor || method == AMROneTimeCode || method == AMRHardwareKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newAuthContext(amr ...string) *sessionEntity.AuthContext {
+	return &sessionEntity.AuthContext{
+		AuthTime: time.Now(),
+		AMR:      amr,
+	}
+}
+
+func authContextClaims(authContext *sessionEntity.AuthContext) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"auth_time": authContext.AuthTime.UTC().Unix(),
+		"amr":       authContext.AMR,
+	}
+	if len(authContext.Roles) > 0 {
+		claims["roles"] = authContext.Roles
+	}
+	if authContext.ClientID != "" {
+		claims["client_id"] = authContext.ClientID
+		claims["scope"] = authContext.Scope
+	}
+	if authContext.Restricted {
+		claims["restricted"] = true
+	}
+
+	return claims
+}
+
+func authContextFromClaims(claims jwt.MapClaims) *sessionEntity.AuthContext {
+	authContext := &sessionEntity.AuthContext{
+		AMR:   []string{},
+		Roles: []string{},
+	}
+	authContext.ClientID, _ = claims["client_id"].(string)
+	authContext.Scope, _ = claims["scope"].(string)
+	authContext.Restricted, _ = claims["restricted"].(bool)
+
+	if authTime, ok := claims["auth_time"].(float64); ok && authTime > 0 {
+		authContext.AuthTime = time.Unix(int64(authTime), 0)
+	}
+
+	claimsAMR, _ := claims["amr"].([]interface{})
+	for _, claimsMethod := range claimsAMR {
+		if method, ok := claimsMethod.(string); ok {
+			authContext.AMR = append(authContext.AMR, method)
+		}
+	}
+
+	claimsRoles, _ := claims["roles"].([]interface{})
+	for _, claimsRole := range claimsRoles {
+		if role, ok := claimsRole.(string); ok {
+			authContext.Roles = append(authContext.Roles, role)
+		}
+	}
+
+	return authContext
+}
+
+// isEmailUnverified lets a restricted session pick up a verification that
+// happened elsewhere, e.g. from a link opened on another device.
+func (uc *SessionUsecase) isEmailUnverified(userID uint) (bool, error) {
+	user, err := uc.userRepo.GetById(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return user.Email != "" && !user.EmailVerified, nil
+}
+
+func generateID() (string, error) {
+	buf := make([]byte, 16)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+func (uc *SessionUsecase) ParseToken(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, uc.tokenSigner.Keyfunc)
+}
+
+func (uc *SessionUsecase) ValidateAccessToken(tokenString string) (*sessionEntity.Identity, error) {
+	token, err := uc.ParseToken(tokenString)
+	if err != nil || !token.Valid {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	switch claims["typ"] {
+	case tokenTypeService:
+		return uc.validateServiceClaims(claims)
+	case tokenTypeGuest:
+		return uc.validateGuestClaims(claims)
+	}
+
+	if claims["typ"] != tokenTypeAccess {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	claimsUser, ok := claims["user"].(map[string]interface{})
+	if !ok {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	userIDString, ok := claimsUser["id"].(string)
+	if !ok {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	userID64, err := strconv.ParseUint(userIDString, 10, 32)
+	if err != nil || userID64 == 0 {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	username, _ := claimsUser["username"].(string)
+
+	sessionID, ok := claims["sid"].(string)
+	if !ok {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	err = uc.checkRevocation(uint(userID64), claims)
+	if err != nil {
+		return nil, err
+	}
+
+	authContext := authContextFromClaims(claims)
+	expiresAt, _ := claims["exp"].(float64)
+
+	return &sessionEntity.Identity{
+		UserID:     uint(userID64),
+		Username:   username,
+		SessionID:  sessionID,
+		Roles:      authContext.Roles,
+		ClientID:   authContext.ClientID,
+		Scope:      authContext.Scope,
+		AuthTime:   authContext.AuthTime,
+		AMR:        authContext.AMR,
+		Restricted: authContext.Restricted,
+		ExpiresAt:  time.Unix(int64(expiresAt), 0),
+	}, nil
+}
+
+// ValidateRefreshToken accepts only the newest refresh token of a live
+// session, so a token that has already been rotated reports as invalid.
+func (uc *SessionUsecase) ValidateRefreshToken(tokenString string) (*sessionEntity.Identity, error) {
+	token, err := uc.ParseToken(tokenString)
+	if err != nil || !token.Valid {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != tokenTypeRefresh {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	userID, username, ok := userFromClaims(claims)
+	if !ok {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	sessionID, _ := claims["sid"].(string)
+	refreshJTI, _ := claims["jti"].(string)
+	sessionModel, err := uc.sessionRepo.Get(userID, sessionID)
+	if err == sessionEntity.ErrNoSession {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if refreshJTI == "" || sessionModel.RefreshJTI != refreshJTI {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	err = uc.checkRevocation(userID, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	authContext := authContextFromClaims(claims)
+	expiresAt, _ := claims["exp"].(float64)
+
+	return &sessionEntity.Identity{
+		UserID:     userID,
+		Username:   username,
+		SessionID:  sessionID,
+		Roles:      authContext.Roles,
+		ClientID:   authContext.ClientID,
+		Scope:      authContext.Scope,
+		AuthTime:   authContext.AuthTime,
+		AMR:        authContext.AMR,
+		Restricted: authContext.Restricted,
+		ExpiresAt:  time.Unix(int64(expiresAt), 0),
+	}, nil
+}
+
+// RevokeToken blocks the presented token itself and, for user tokens, ends the
+// session it belongs to.
+func (uc *SessionUsecase) RevokeToken(token *jwt.Token) error {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return sessionEntity.ErrInvalidToken
+	}
+
+	jti, _ := claims["jti"].(string)
+	expiresAt, _ := claims["exp"].(float64)
+	if jti != "" {
+		err := uc.revocationRepo.RevokeToken(jti, time.Unix(int64(expiresAt), 0))
+		if err != nil {
+			return err
+		}
+	}
+
+	switch claims["typ"] {
+	case tokenTypeService:
+		return nil
+	case tokenTypeGuest:
+		return uc.revokeGuestClaims(claims)
+	}
+
+	userID, _, ok := userFromClaims(claims)
+	sessionID, _ := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		return nil
+	}
+
+	err := uc.Delete(userID, sessionID)
+	if err == sessionEntity.ErrNoSession {
+		return nil
+	}
+
+	return err
+}
+
+func userFromClaims(claims jwt.MapClaims) (uint, string, bool) {
+	claimsUser, ok := claims["user"].(map[string]interface{})
+	if !ok {
+		return 0, "", false
+	}
+
+	userIDString, _ := claimsUser["id"].(string)
+	userID64, err := strconv.ParseUint(userIDString, 10, 32)
+	if err != nil || userID64 == 0 {
+		return 0, "", false
+	}
+
+	username, _ := claimsUser["username"].(string)
+
+	return uint(userID64), username, true
+}
+
+// Service tokens belong to an OAuth client rather than a user, so they carry
+// no session and can only be revoked one by one.
+func (uc *SessionUsecase) CreateServiceToken(clientID string, scope string, ttl time.Duration) (*sessionEntity.Session, error) {
+	jti, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	tokenString, err := uc.tokenSigner.Sign(jwt.MapClaims{
+		"typ":       tokenTypeService,
+		"sub":       clientID,
+		"client_id": clientID,
+		"scope":     scope,
+		"jti":       jti,
+		"iat":       time.Now().UTC().Unix(),
+		"exp":       expiresAt.UTC().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sessionEntity.Session{
+		AccessJTI:       jti,
+		JWTAccess:       tokenString,
+		ClientID:        clientID,
+		Scope:           scope,
+		AccessExpiresAt: expiresAt,
+	}, nil
+}
+
+func (uc *SessionUsecase) validateServiceClaims(claims jwt.MapClaims) (*sessionEntity.Identity, error) {
+	clientID, _ := claims["client_id"].(string)
+	if clientID == "" || claims["sub"] != clientID {
+		return nil, sessionEntity.ErrInvalidToken
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti != "" {
+		revoked, err := uc.revocationRepo.IsTokenRevoked(jti)
+		if err != nil {
+			return nil, err
+		}
+
+		if revoked {
+			return nil, sessionEntity.ErrTokenRevoked
+		}
+	}
+
+	scope, _ := claims["scope"].(string)
+	expiresAt, _ := claims["exp"].(float64)
+
+	return &sessionEntity.Identity{
+		Roles:     []string{},
+		ClientID:  clientID,
+		Scope:     scope,
+		Service:   true,
+		ExpiresAt: time.Unix(int64(expiresAt), 0),
+	}, nil
+}
+
+func (uc *SessionUsecase) checkRevocation(userID uint, claims jwt.MapClaims) error {
+	jti, _ := claims["jti"].(string)
+	if jti != "" {
+		revoked, err := uc.revocationRepo.IsTokenRevoked(jti)
+		if err != nil {
+			return err
+		}
+
+		if revoked {
+			return sessionEntity.ErrTokenRevoked
+		}
+	}
+
+	revokedBefore, err := uc.revocationRepo.GetRevokedBefore(userID)
+	if err != nil {
+		return err
+	}
+
+	if !revokedBefore.IsZero() && issuedAtMicros(claims) < revokedBefore.UnixMicro() {
+		return sessionEntity.ErrTokenRevoked
+	}
+
+	return nil
+}
+
+// issuedAtMicros reads the microsecond issue time, so a login right after a
+// revocation isn't caught by it. Tokens without one are treated as issued at
+// the end of their iat second.
+func issuedAtMicros(claims jwt.MapClaims) int64 {
+	if issuedAtUs, ok := claims["iat_us"].(float64); ok {
+		return int64(issuedAtUs)
+	}
+
+	issuedAt, _ := claims["iat"].(float64)
+
+	return int64(issuedAt)*1_000_000 + 999_999
+}
+
+func (uc *SessionUsecase) createJWT(username string, userID uint, ttl time.Time, extraClaims jwt.MapClaims) (string, error) {
+	now := time.Now().UTC()
+	claims := jwt.MapClaims{
 		"user": map[string]string{
 			"username": username,
 			"id":       strconv.Itoa(int(userID)),
 		},
-		"iat": time.Now().UTC().Unix(),
-		"exp": ttl.UTC().Unix(),
-	})
-	tokenString, err := token.SignedString(jwtTokenKey)
+		"iat":    now.Unix(),
+		"iat_us": now.UnixMicro(),
+		"exp":    ttl.UTC().Unix(),
+	}
+	for name, value := range extraClaims {
+		claims[name] = value
+	}
+
+	tokenString, err := uc.tokenSigner.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -213,22 +949,58 @@ func createJWT(username string, ttl time.Time, userID uint) (string, error) {
 	return tokenString, nil
 }
 
-func formSignedSession(username string, userID uint, accessTokenTTL time.Time, refreshTokenTTL time.Time) (*sessionEntity.Session, error) {
-	accessToken, err := createJWT(username, accessTokenTTL, userID)
+func (uc *SessionUsecase) formSignedSession(sessionID string, familyID string, username string, userID uint, authContext *sessionEntity.AuthContext, accessTokenTTL time.Time, refreshTokenTTL time.Time) (*sessionEntity.Session, error) {
+	accessJTI, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshJTI, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	accessClaims := jwt.MapClaims{
+		"typ": tokenTypeAccess,
+		"sid": sessionID,
+		"jti": accessJTI,
+	}
+	refreshClaims := jwt.MapClaims{
+		"typ": tokenTypeRefresh,
+		"sid": sessionID,
+		"jti": refreshJTI,
+		"fam": familyID,
+	}
+	for name, value := range authContextClaims(authContext) {
+		accessClaims[name] = value
+		refreshClaims[name] = value
+	}
+
+	accessToken, err := uc.createJWT(username, userID, accessTokenTTL, accessClaims)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := createJWT(username, refreshTokenTTL, userID)
+	refreshToken, err := uc.createJWT(username, userID, refreshTokenTTL, refreshClaims)
 	if err != nil {
 		return nil, err
 	}
 
 	return &sessionEntity.Session{
+		ID:               sessionID,
+		FamilyID:         familyID,
+		AccessJTI:        accessJTI,
+		RefreshJTI:       refreshJTI,
 		JWTAccess:        accessToken,
 		JWTRefresh:       refreshToken,
 		UserID:           userID,
 		Username:         username,
+		ClientID:         authContext.ClientID,
+		Scope:            authContext.Scope,
+		AuthTime:         authContext.AuthTime,
+		AMR:              authContext.AMR,
+		Roles:            authContext.Roles,
+		Restricted:       authContext.Restricted,
 		AccessExpiresAt:  accessTokenTTL,
 		RefreshExpiresAt: refreshTokenTTL,
 	}, nil
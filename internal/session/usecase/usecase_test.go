@@ -0,0 +1,576 @@
+package usecase
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	mfaEntity "github.com/lightlink/auth-service/internal/mfa/domain/entity"
+	"github.com/lightlink/auth-service/internal/session/authenticator"
+	sessionDTO "github.com/lightlink/auth-service/internal/session/domain/dto"
+	sessionEntity "github.com/lightlink/auth-service/internal/session/domain/entity"
+	sessionRepo "github.com/lightlink/auth-service/internal/session/repository/redis"
+	"github.com/lightlink/auth-service/internal/testutil"
+	userDTO "github.com/lightlink/auth-service/internal/user/domain/dto"
+	userEntity "github.com/lightlink/auth-service/internal/user/domain/entity"
+)
+
+const testPassword = "correct horse"
+
+type fakeAuthenticator struct {
+	users map[string]*sessionEntity.AuthenticatedUser
+}
+
+func (f *fakeAuthenticator) Authenticate(username string, password string) (*sessionEntity.AuthenticatedUser, error) {
+	user, ok := f.users[username]
+	if !ok || password != testPassword {
+		return nil, sessionEntity.ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+func (f *fakeAuthenticator) Roles(username string) ([]string, error) {
+	user, ok := f.users[username]
+	if !ok {
+		return nil, sessionEntity.ErrInvalidCredentials
+	}
+
+	return user.Roles, nil
+}
+
+// IsExternal hands corp.example.com to a directory, like an LDAP backend
+// registered for that domain.
+func (f *fakeAuthenticator) IsExternal(username string) bool {
+	_, domain := authenticator.SplitUsername(username)
+
+	return domain == "corp.example.com"
+}
+
+type testEnv struct {
+	uc            *SessionUsecase
+	mfa           *testutil.MFAUsecase
+	users         *testutil.UserRepository
+	authenticator *fakeAuthenticator
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	base := testutil.NewEnv(t)
+	mfa := &testutil.MFAUsecase{Enabled: map[uint]bool{}, Code: "123456"}
+	users := testutil.NewUserRepository(
+		&userDTO.UserTransfer{Id: 1, Username: "alice", Email: "alice@example.com", EmailVerified: true},
+		&userDTO.UserTransfer{Id: 2, Username: "bob", Email: "bob@example.com"},
+	)
+	passwordAuthenticator := &fakeAuthenticator{
+		users: map[string]*sessionEntity.AuthenticatedUser{
+			"alice":                 {UserID: 1, Username: "alice", Roles: []string{"admin"}},
+			"bob":                   {UserID: 2, Username: "bob", Roles: []string{}, Restricted: true},
+			"dave@corp.example.com": {UserID: 3, Username: "dave@corp.example.com", Roles: []string{"admin"}},
+			"erin@corp.example.com": {UserID: 4, Username: "erin@corp.example.com", Roles: []string{}},
+		},
+	}
+
+	uc := NewSessionUsecase(
+		sessionRepo.NewSessionRedisRepository(base.Dial()),
+		sessionRepo.NewRevocationRedisRepository(base.Dial()),
+		sessionRepo.NewTicketRedisRepository(base.Dial()),
+		users,
+		mfa,
+		base.Signer,
+		passwordAuthenticator,
+		0,
+	)
+
+	return &testEnv{
+		uc:            uc,
+		mfa:           mfa,
+		users:         users,
+		authenticator: passwordAuthenticator,
+	}
+}
+
+func (env *testEnv) login(t *testing.T, username string) *sessionEntity.Session {
+	t.Helper()
+
+	session, err := env.uc.Login(&sessionDTO.LoginRequest{Username: username, Password: testPassword})
+	if err != nil {
+		t.Fatalf("Login(%s): %v", username, err)
+	}
+
+	return session
+}
+
+func TestSignup(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantErr  error
+	}{
+		{"new local account", "carol", nil},
+		{"taken username", "alice", userEntity.ErrAlreadyCreated},
+		{"directory domain", "carol@corp.example.com", sessionEntity.ErrExternalAccount},
+		{"directory domain in another case", "carol@CORP.example.com", sessionEntity.ErrExternalAccount},
+		{"directory down-level name", `CORP.EXAMPLE.COM\carol`, sessionEntity.ErrExternalAccount},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			session, err := env.uc.Signup(&sessionDTO.SignupRequest{
+				Username: test.username,
+				Password: testPassword,
+				Email:    "carol@example.com",
+			})
+			if err != test.wantErr {
+				t.Fatalf("Signup(%s) err = %v, want %v", test.username, err, test.wantErr)
+			}
+
+			if test.wantErr != nil {
+				if len(env.users.Users) != 2 {
+					t.Errorf("rejected signup left %d users, want 2", len(env.users.Users))
+				}
+				return
+			}
+
+			if session.UserID != 3 || !session.Restricted {
+				t.Errorf("Signup session = user %d restricted %v, want a restricted session of user 3", session.UserID, session.Restricted)
+			}
+		})
+	}
+}
+
+// TestSignupRestrictedUntilVerified follows a new account from signup until the
+// verified address reaches its tokens on the next refresh.
+func TestSignupRestrictedUntilVerified(t *testing.T) {
+	env := newTestEnv(t)
+
+	session, err := env.uc.Signup(&sessionDTO.SignupRequest{
+		Username: "carol",
+		Password: testPassword,
+		Email:    "carol@example.com",
+	})
+	if err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+
+	identity, err := env.uc.ValidateAccessToken(session.JWTAccess)
+	if err != nil || !identity.Restricted {
+		t.Fatalf("access token after signup = %+v, %v, want a restricted identity", identity, err)
+	}
+
+	refreshed, err := env.refresh(t, session.JWTRefresh)
+	if err != nil {
+		t.Fatalf("RefreshSession before verification: %v", err)
+	}
+
+	identity, err = env.uc.ValidateAccessToken(refreshed.JWTAccess)
+	if err != nil || !identity.Restricted {
+		t.Fatalf("refreshed token before verification = %+v, %v, want a restricted identity", identity, err)
+	}
+
+	env.users.Users[session.UserID].EmailVerified = true
+
+	verified, err := env.refresh(t, refreshed.JWTRefresh)
+	if err != nil {
+		t.Fatalf("RefreshSession after verification: %v", err)
+	}
+
+	identity, err = env.uc.ValidateAccessToken(verified.JWTAccess)
+	if err != nil || identity.Restricted {
+		t.Fatalf("refreshed token after verification = %+v, %v, want an unrestricted identity", identity, err)
+	}
+
+	if verified.Restricted {
+		t.Errorf("session after verification is still restricted")
+	}
+}
+
+func TestCheckRevocationWatermark(t *testing.T) {
+	env := newTestEnv(t)
+
+	watermark := time.UnixMicro(1_700_000_000_500_000)
+	err := env.uc.revocationRepo.SetRevokedBefore(1, watermark, time.Hour)
+	if err != nil {
+		t.Fatalf("SetRevokedBefore: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  uint
+		claims  jwt.MapClaims
+		wantErr error
+	}{
+		{
+			name:    "issued before watermark",
+			userID:  1,
+			claims:  jwt.MapClaims{"iat": float64(1_700_000_000), "iat_us": float64(1_700_000_000_499_999)},
+			wantErr: sessionEntity.ErrTokenRevoked,
+		},
+		{
+			name:   "issued at watermark",
+			userID: 1,
+			claims: jwt.MapClaims{"iat": float64(1_700_000_000), "iat_us": float64(1_700_000_000_500_000)},
+		},
+		{
+			name:   "issued later in the same second",
+			userID: 1,
+			claims: jwt.MapClaims{"iat": float64(1_700_000_000), "iat_us": float64(1_700_000_000_900_000)},
+		},
+		{
+			name:    "seconds only in the same second",
+			userID:  1,
+			claims:  jwt.MapClaims{"iat": float64(1_699_999_999)},
+			wantErr: sessionEntity.ErrTokenRevoked,
+		},
+		{
+			name:   "seconds only after watermark",
+			userID: 1,
+			claims: jwt.MapClaims{"iat": float64(1_700_000_001)},
+		},
+		{
+			name:   "other user",
+			userID: 2,
+			claims: jwt.MapClaims{"iat": float64(1), "iat_us": float64(1_000_000)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := env.uc.checkRevocation(test.userID, test.claims)
+			if err != test.wantErr {
+				t.Errorf("checkRevocation() = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRevokeAllSessionsAllowsImmediateLogin(t *testing.T) {
+	env := newTestEnv(t)
+
+	before := env.login(t, "alice")
+	err := env.uc.RevokeAllSessions(1)
+	if err != nil {
+		t.Fatalf("RevokeAllSessions: %v", err)
+	}
+	after := env.login(t, "alice")
+
+	_, err = env.uc.ValidateAccessToken(before.JWTAccess)
+	if err != sessionEntity.ErrTokenRevoked {
+		t.Errorf("old access token err = %v, want %v", err, sessionEntity.ErrTokenRevoked)
+	}
+
+	_, err = env.uc.ValidateAccessToken(after.JWTAccess)
+	if err != nil {
+		t.Errorf("new access token err = %v, want nil", err)
+	}
+}
+
+func (env *testEnv) beginMFA(t *testing.T, username string) string {
+	t.Helper()
+
+	_, err := env.uc.Login(&sessionDTO.LoginRequest{Username: username, Password: testPassword})
+	mfaRequired := &sessionEntity.MFARequiredError{}
+	if !errors.As(err, &mfaRequired) {
+		t.Fatalf("Login(%s) = %v, want MFA required", username, err)
+	}
+
+	return mfaRequired.MFAToken
+}
+
+func TestLoginMFA(t *testing.T) {
+	tests := []struct {
+		name    string
+		codes   []string
+		lockAt  int
+		wantErr []error
+	}{
+		{
+			name:    "right code",
+			codes:   []string{"123456"},
+			wantErr: []error{nil},
+		},
+		{
+			name:    "pending token is single use",
+			codes:   []string{"123456", "123456"},
+			wantErr: []error{nil, sessionEntity.ErrTokenRevoked},
+		},
+		{
+			name:    "wrong code keeps the token",
+			codes:   []string{"000000", "123456"},
+			wantErr: []error{mfaEntity.ErrInvalidCode, nil},
+		},
+		{
+			name:    "lockout burns the token",
+			codes:   []string{"000000", "000000", "123456"},
+			lockAt:  2,
+			wantErr: []error{mfaEntity.ErrInvalidCode, mfaEntity.ErrTooManyAttempts, sessionEntity.ErrTokenRevoked},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.mfa.Enabled[1] = true
+			mfaToken := env.beginMFA(t, "alice")
+
+			for i, code := range test.codes {
+				env.mfa.Locked = test.lockAt == i+1
+				session, err := env.uc.LoginMFA(&sessionDTO.LoginMFARequest{MFAToken: mfaToken, Code: code})
+				if err != test.wantErr[i] {
+					t.Fatalf("attempt %d: LoginMFA() = %v, want %v", i+1, err, test.wantErr[i])
+				}
+
+				if err == nil && session.JWTAccess == "" {
+					t.Fatalf("attempt %d: LoginMFA() returned no access token", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestLoginMFAConcurrentSubmissions(t *testing.T) {
+	env := newTestEnv(t)
+	env.mfa.Enabled[1] = true
+	mfaToken := env.beginMFA(t, "alice")
+
+	const submissions = 8
+	errs := make(chan error, submissions)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < submissions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := env.uc.LoginMFA(&sessionDTO.LoginMFARequest{MFAToken: mfaToken, Code: "123456"})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	sessions := 0
+	for err := range errs {
+		if err == nil {
+			sessions++
+			continue
+		}
+
+		if err != sessionEntity.ErrTokenRevoked {
+			t.Errorf("LoginMFA() = %v, want %v", err, sessionEntity.ErrTokenRevoked)
+		}
+	}
+
+	if sessions != 1 {
+		t.Errorf("%d submissions of one pending token got %d sessions, want 1", submissions, sessions)
+	}
+}
+
+// A passkey assertion without user verification is one factor, so accounts
+// with TOTP on still have to enter a code.
+func TestCreateSessionStepUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		amr     []string
+		wantMFA bool
+	}{
+		{"hardware key", []string{AMRHardwareKey}, false},
+		{"user presence", []string{AMRUserPresence}, true},
+		{"password", []string{AMRPassword}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.mfa.Enabled[1] = true
+
+			session, err := env.uc.CreateSession("alice", 1, test.amr)
+			mfaRequired := &sessionEntity.MFARequiredError{}
+			if errors.As(err, &mfaRequired) != test.wantMFA {
+				t.Fatalf("CreateSession(%v) = %v, %v, want mfa required %v", test.amr, session, err, test.wantMFA)
+			}
+		})
+	}
+}
+
+// The username is a profile claim, so it's only in the ID token when the
+// caller adds it for a granted profile scope.
+func TestCreateIDTokenOmitsUsername(t *testing.T) {
+	env := newTestEnv(t)
+	session, err := env.uc.CreateDelegatedSession("alice", 1, &sessionEntity.AuthContext{
+		ClientID: "partner",
+		Scope:    "openid",
+		AuthTime: time.Now(),
+		AMR:      []string{AMRPassword},
+	})
+	if err != nil {
+		t.Fatalf("CreateDelegatedSession: %v", err)
+	}
+
+	idToken, err := env.uc.CreateIDToken(session, jwt.MapClaims{"iss": "https://auth.example.com"})
+	if err != nil {
+		t.Fatalf("CreateIDToken: %v", err)
+	}
+
+	token, err := jwt.Parse(idToken, env.uc.tokenSigner.Keyfunc)
+	if err != nil || !token.Valid {
+		t.Fatalf("parse id token: %v", err)
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	if _, ok := claims["user"]; ok {
+		t.Errorf("id token carries the user claim: %v", claims["user"])
+	}
+
+	if claims["sub"] != "1" || claims["aud"] != "partner" || claims["typ"] != tokenTypeID || claims["exp"] == nil {
+		t.Errorf("id token claims = %v, want sub 1, aud partner and an expiry", claims)
+	}
+}
+
+func (env *testEnv) refresh(t *testing.T, refreshToken string) (*sessionEntity.Session, error) {
+	t.Helper()
+
+	token, err := jwt.Parse(refreshToken, env.uc.tokenSigner.Keyfunc)
+	if err != nil {
+		t.Fatalf("parse refresh token: %v", err)
+	}
+
+	return env.uc.RefreshSession(token)
+}
+
+func TestRefreshSessionRotates(t *testing.T) {
+	env := newTestEnv(t)
+	session := env.login(t, "alice")
+
+	rotated, err := env.refresh(t, session.JWTRefresh)
+	if err != nil {
+		t.Fatalf("RefreshSession: %v", err)
+	}
+
+	if rotated.ID != session.ID || rotated.FamilyID != session.FamilyID || rotated.RefreshJTI == session.RefreshJTI {
+		t.Fatalf("rotated session %s/%s jti %s, want %s/%s with a new jti", rotated.ID, rotated.FamilyID, rotated.RefreshJTI, session.ID, session.FamilyID)
+	}
+
+	again, err := env.refresh(t, rotated.JWTRefresh)
+	if err != nil {
+		t.Fatalf("RefreshSession with the rotated token: %v", err)
+	}
+
+	if again.RefreshJTI == rotated.RefreshJTI {
+		t.Errorf("second rotation kept jti %s", again.RefreshJTI)
+	}
+}
+
+// Presenting a refresh token that was already rotated means it leaked, so the
+// whole family goes, including the token the legitimate client holds now.
+// Roles the account store takes away must not outlive the next refresh.
+func TestRefreshSessionRechecksRoles(t *testing.T) {
+	env := newTestEnv(t)
+	session := env.login(t, "dave@corp.example.com")
+
+	refreshed, err := env.refresh(t, session.JWTRefresh)
+	if err != nil || len(refreshed.Roles) != 1 || refreshed.Roles[0] != "admin" {
+		t.Fatalf("refresh = %v, %v, want dave still admin", refreshed, err)
+	}
+
+	env.authenticator.users["dave@corp.example.com"].Roles = []string{}
+
+	refreshed, err = env.refresh(t, refreshed.JWTRefresh)
+	if err != nil || len(refreshed.Roles) != 0 {
+		t.Fatalf("refresh after leaving the group = %v, %v, want no roles", refreshed, err)
+	}
+
+	identity, err := env.uc.ValidateAccessToken(refreshed.JWTAccess)
+	if err != nil || len(identity.Roles) != 0 {
+		t.Errorf("access token after leaving the group = %v, %v, want no roles", identity, err)
+	}
+
+	delete(env.authenticator.users, "dave@corp.example.com")
+
+	_, err = env.refresh(t, refreshed.JWTRefresh)
+	if !errors.Is(err, sessionEntity.ErrInvalidRefreshToken) {
+		t.Errorf("refresh of a removed account err = %v, want %v", err, sessionEntity.ErrInvalidRefreshToken)
+	}
+}
+
+// A directory account without roles is still looked up on every refresh,
+// both to pick up roles granted later and to notice it being removed.
+func TestRefreshSessionRechecksEmptyRoles(t *testing.T) {
+	env := newTestEnv(t)
+	session := env.login(t, "erin@corp.example.com")
+
+	env.authenticator.users["erin@corp.example.com"].Roles = []string{"admin"}
+
+	refreshed, err := env.refresh(t, session.JWTRefresh)
+	if err != nil || len(refreshed.Roles) != 1 || refreshed.Roles[0] != "admin" {
+		t.Fatalf("refresh after joining the group = %v, %v, want erin admin", refreshed, err)
+	}
+
+	env.authenticator.users["erin@corp.example.com"].Roles = []string{}
+
+	refreshed, err = env.refresh(t, refreshed.JWTRefresh)
+	if err != nil || len(refreshed.Roles) != 0 {
+		t.Fatalf("refresh after leaving the group = %v, %v, want no roles", refreshed, err)
+	}
+
+	delete(env.authenticator.users, "erin@corp.example.com")
+
+	_, err = env.refresh(t, refreshed.JWTRefresh)
+	if !errors.Is(err, sessionEntity.ErrInvalidRefreshToken) {
+		t.Errorf("refresh of a removed account err = %v, want %v", err, sessionEntity.ErrInvalidRefreshToken)
+	}
+}
+
+func TestRefreshSessionReuseRevokesFamily(t *testing.T) {
+	env := newTestEnv(t)
+	session := env.login(t, "alice")
+	other := env.login(t, "alice")
+
+	rotated, err := env.refresh(t, session.JWTRefresh)
+	if err != nil {
+		t.Fatalf("RefreshSession: %v", err)
+	}
+
+	_, err = env.refresh(t, session.JWTRefresh)
+	if err != sessionEntity.ErrRefreshTokenReused {
+		t.Fatalf("replayed RefreshSession err = %v, want %v", err, sessionEntity.ErrRefreshTokenReused)
+	}
+
+	_, err = env.refresh(t, rotated.JWTRefresh)
+	if err != sessionEntity.ErrNoSession {
+		t.Errorf("RefreshSession after reuse err = %v, want %v", err, sessionEntity.ErrNoSession)
+	}
+
+	_, err = env.uc.GetSession(1, session.ID)
+	if err != sessionEntity.ErrNoSession {
+		t.Errorf("GetSession after reuse err = %v, want %v", err, sessionEntity.ErrNoSession)
+	}
+
+	_, err = env.uc.ValidateAccessToken(rotated.JWTAccess)
+	if err != sessionEntity.ErrTokenRevoked {
+		t.Errorf("access token after reuse err = %v, want %v", err, sessionEntity.ErrTokenRevoked)
+	}
+
+	_, err = env.uc.ValidateAccessToken(other.JWTAccess)
+	if err != nil {
+		t.Errorf("access token of another family: %v", err)
+	}
+
+	_, err = env.refresh(t, other.JWTRefresh)
+	if err != nil {
+		t.Errorf("RefreshSession of another family: %v", err)
+	}
+}
+
+func TestRefreshSessionRejectsAccessToken(t *testing.T) {
+	env := newTestEnv(t)
+	session := env.login(t, "alice")
+
+	_, err := env.refresh(t, session.JWTAccess)
+	if err != sessionEntity.ErrInvalidRefreshToken {
+		t.Fatalf("RefreshSession(access token) err = %v, want %v", err, sessionEntity.ErrInvalidRefreshToken)
+	}
+}
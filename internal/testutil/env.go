@@ -0,0 +1,43 @@
+// Package testutil holds the fixtures the tests share, so each
+// package's newTestEnv only wires up its own usecase and fakes.
+package testutil
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/lightlink/auth-service/internal/token/signer"
+)
+
+// Env is a private Redis and the signer tokens are issued with, both torn
+// down with the test.
+type Env struct {
+	Redis  *miniredis.Miniredis
+	Signer *signer.KeySigner
+	t      *testing.T
+}
+
+func NewEnv(t *testing.T) *Env {
+	t.Helper()
+
+	return &Env{
+		Redis:  miniredis.RunT(t),
+		Signer: signer.NewHMACSigner("test", []byte("test secret")),
+		t:      t,
+	}
+}
+
+// Dial opens a new connection. A redigo conn is not safe for concurrent use,
+// so cmd/main.go dials one per repository and tests should do the same.
+func (env *Env) Dial() redis.Conn {
+	env.t.Helper()
+
+	conn, err := redis.Dial("tcp", env.Redis.Addr())
+	if err != nil {
+		env.t.Fatalf("dial miniredis: %v", err)
+	}
+	env.t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
@@ -0,0 +1,31 @@
+package testutil
+
+import (
+	mfaEntity "github.com/lightlink/auth-service/internal/mfa/domain/entity"
+	mfaUsecase "github.com/lightlink/auth-service/internal/mfa/usecase"
+)
+
+// MFAUsecase has TOTP on for the users in Enabled and accepts Code as their
+// current one. Locked makes Verify report a lockout.
+type MFAUsecase struct {
+	mfaUsecase.MFAUsecaseI
+	Enabled map[uint]bool
+	Code    string
+	Locked  bool
+}
+
+func (f *MFAUsecase) IsEnabled(userID uint) (bool, error) {
+	return f.Enabled[userID], nil
+}
+
+func (f *MFAUsecase) Verify(userID uint, code string) error {
+	if f.Locked {
+		return mfaEntity.ErrTooManyAttempts
+	}
+
+	if code != f.Code {
+		return mfaEntity.ErrInvalidCode
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package testutil
+
+import (
+	userDTO "github.com/lightlink/auth-service/internal/user/domain/dto"
+	userEntity "github.com/lightlink/auth-service/internal/user/domain/entity"
+	userRepo "github.com/lightlink/auth-service/internal/user/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UserRepository stands in for the user service. Users are keyed by id,
+// Identities by "provider|subject", and lookups that miss answer NotFound
+// like the gRPC repository does.
+type UserRepository struct {
+	userRepo.UserRepositoryI
+	Users          map[uint]*userDTO.UserTransfer
+	Identities     map[string]uint
+	PasswordHashes map[uint]string
+}
+
+func NewUserRepository(users ...*userDTO.UserTransfer) *UserRepository {
+	repo := &UserRepository{
+		Users:          map[uint]*userDTO.UserTransfer{},
+		Identities:     map[string]uint{},
+		PasswordHashes: map[uint]string{},
+	}
+
+	for _, user := range users {
+		repo.Users[user.Id] = user
+	}
+
+	return repo
+}
+
+func (f *UserRepository) GetById(id uint) (*userDTO.UserTransfer, error) {
+	user, ok := f.Users[id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	return user, nil
+}
+
+func (f *UserRepository) GetByUsername(username string) (*userDTO.UserTransfer, error) {
+	for _, user := range f.Users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+
+	return nil, status.Error(codes.NotFound, "user not found")
+}
+
+func (f *UserRepository) GetCredentials(username string) (*userDTO.UserCredentialsTransfer, error) {
+	user, err := f.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userDTO.UserCredentialsTransfer{
+		Id:            user.Id,
+		Username:      user.Username,
+		PasswordHash:  f.PasswordHashes[user.Id],
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+	}, nil
+}
+
+func (f *UserRepository) Create(user *userEntity.User) (*userDTO.UserTransfer, error) {
+	var lastID uint
+	for id := range f.Users {
+		if id > lastID {
+			lastID = id
+		}
+	}
+
+	created := &userDTO.UserTransfer{Id: lastID + 1, Username: user.Username, Email: user.Email}
+	f.Users[created.Id] = created
+	f.PasswordHashes[created.Id] = user.PasswordHash
+
+	return created, nil
+}
+
+func (f *UserRepository) GetByExternalIdentity(provider string, subject string) (*userDTO.UserTransfer, error) {
+	userID, ok := f.Identities[provider+"|"+subject]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "identity not found")
+	}
+
+	return f.GetById(userID)
+}
+
+func (f *UserRepository) LinkIdentity(userID uint, provider string, subject string) error {
+	f.Identities[provider+"|"+subject] = userID
+
+	return nil
+}
+
+func (f *UserRepository) SetPasswordHash(userID uint, passwordHash string) error {
+	f.PasswordHashes[userID] = passwordHash
+
+	return nil
+}
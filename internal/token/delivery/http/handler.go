@@ -0,0 +1,92 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/lightlink/auth-service/internal/token/domain/entity"
+	"github.com/lightlink/auth-service/internal/token/keyring"
+)
+
+type TokenHandler struct {
+	keyRing *keyring.KeyRing
+}
+
+func NewTokenHandler(keyRing *keyring.KeyRing) *TokenHandler {
+	return &TokenHandler{
+		keyRing: keyRing,
+	}
+}
+
+type rotateKeysResponse struct {
+	Rotated bool   `json:"rotated"`
+	KeyID   string `json:"kid"`
+}
+
+func (h *TokenHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	body, err := json.Marshal(h.keyRing.JWKSet())
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("jwks marshal err", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func (h *TokenHandler) RotateKeys(w http.ResponseWriter, r *http.Request) {
+	if !isAdmin(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("Admin token is invalid")
+		return
+	}
+
+	err := h.keyRing.Reload()
+	if errors.Is(err, entity.ErrKeyUnchanged) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Println("key reload err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("key reload err", err)
+		return
+	}
+
+	body, err := json.Marshal(&rotateKeysResponse{
+		Rotated: true,
+		KeyID:   h.keyRing.KeyID(),
+	})
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("rotate keys marshal err", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func isAdmin(r *http.Request) bool {
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		return false
+	}
+
+	presented := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return subtle.ConstantTimeCompare([]byte(presented), []byte(adminToken)) == 1
+}
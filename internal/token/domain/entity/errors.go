@@ -0,0 +1,11 @@
+package entity
+
+import "errors"
+
+var (
+	ErrUnsupportedKey          = errors.New("unsupported signing key type")
+	ErrNoPEMBlock              = errors.New("couldn't find PEM block in key file")
+	ErrUnknownKeyID            = errors.New("unknown signing key id")
+	ErrUnexpectedSigningMethod = errors.New("unexpected signing method")
+	ErrKeyUnchanged            = errors.New("signing key is unchanged")
+)
@@ -0,0 +1,154 @@
+package keyring
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/token/domain/entity"
+	"github.com/lightlink/auth-service/internal/token/signer"
+)
+
+type retiredKey struct {
+	signer    signer.Signer
+	expiresAt time.Time
+}
+
+// KeyRing signs with a single active key and keeps retired keys around for
+// verification until every token they could have signed has expired.
+type KeyRing struct {
+	mu        *sync.RWMutex
+	active    signer.Signer
+	retired   []retiredKey
+	retention time.Duration
+	loader    func() (signer.Signer, error)
+}
+
+func NewKeyRing(loader func() (signer.Signer, error), retention time.Duration) (*KeyRing, error) {
+	active, err := loader()
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyRing{
+		mu:        &sync.RWMutex{},
+		active:    active,
+		retired:   []retiredKey{},
+		retention: retention,
+		loader:    loader,
+	}, nil
+}
+
+func (k *KeyRing) Active() signer.Signer {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.active
+}
+
+func (k *KeyRing) AddRetired(retiredSigner signer.Signer) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.pruneRetired(time.Now())
+	k.retired = append(k.retired, retiredKey{
+		signer:    retiredSigner,
+		expiresAt: time.Now().Add(k.retention),
+	})
+}
+
+func (k *KeyRing) Rotate(next signer.Signer) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if next.KeyID() == k.active.KeyID() {
+		return false
+	}
+
+	k.pruneRetired(time.Now())
+	k.retired = append(k.retired, retiredKey{
+		signer:    k.active,
+		expiresAt: time.Now().Add(k.retention),
+	})
+	k.active = next
+
+	log.Printf("signing key rotated, active kid %q", next.KeyID())
+
+	return true
+}
+
+// Reload reports ErrKeyUnchanged when the loader still returns the active
+// key, so a rotation that didn't take is never mistaken for one that did.
+func (k *KeyRing) Reload() error {
+	next, err := k.loader()
+	if err != nil {
+		return err
+	}
+
+	if !k.Rotate(next) {
+		return entity.ErrKeyUnchanged
+	}
+
+	return nil
+}
+
+func (k *KeyRing) verificationSigners() []signer.Signer {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now()
+	signers := []signer.Signer{k.active}
+	for _, key := range k.retired {
+		if now.After(key.expiresAt) {
+			continue
+		}
+		signers = append(signers, key.signer)
+	}
+
+	return signers
+}
+
+// pruneRetired drops keys past their retention. Callers hold the write lock,
+// verification only skips expired keys so it can share the read lock.
+func (k *KeyRing) pruneRetired(now time.Time) {
+	retired := k.retired[:0]
+	for _, key := range k.retired {
+		if now.After(key.expiresAt) {
+			continue
+		}
+		retired = append(retired, key)
+	}
+	k.retired = retired
+}
+
+func (k *KeyRing) KeyID() string {
+	return k.Active().KeyID()
+}
+
+func (k *KeyRing) Method() jwt.SigningMethod {
+	return k.Active().Method()
+}
+
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	return k.Active().Sign(claims)
+}
+
+func (k *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+	for _, keySigner := range k.verificationSigners() {
+		if keySigner.KeyID() == keyID {
+			return keySigner.Keyfunc(token)
+		}
+	}
+
+	return nil, entity.ErrUnknownKeyID
+}
+
+func (k *KeyRing) PublicJWK() (*entity.JWK, bool) {
+	return k.Active().PublicJWK()
+}
+
+func (k *KeyRing) JWKSet() *entity.JWKSet {
+	return signer.JWKSet(k.verificationSigners()...)
+}
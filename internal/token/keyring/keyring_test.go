@@ -0,0 +1,208 @@
+package keyring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/token/domain/entity"
+	"github.com/lightlink/auth-service/internal/token/signer"
+)
+
+func newECSigner(t *testing.T) signer.Signer {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	keySigner, err := signer.NewPrivateKeySigner("", privateKey)
+	if err != nil {
+		t.Fatalf("NewPrivateKeySigner: %v", err)
+	}
+
+	return keySigner
+}
+
+func sign(t *testing.T, keySigner signer.Signer) string {
+	t.Helper()
+
+	tokenString, err := keySigner.Sign(jwt.MapClaims{"sub": "1"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	return tokenString
+}
+
+func verify(keyRing *KeyRing, tokenString string) error {
+	_, err := jwt.Parse(tokenString, keyRing.Keyfunc)
+
+	return err
+}
+
+func jwkKeyIDs(keyRing *KeyRing) []string {
+	keyIDs := []string{}
+	for _, jwk := range keyRing.JWKSet().Keys {
+		keyIDs = append(keyIDs, jwk.Kid)
+	}
+
+	return keyIDs
+}
+
+func TestRotate(t *testing.T) {
+	first := newECSigner(t)
+	second := newECSigner(t)
+
+	keyRing, err := NewKeyRing(func() (signer.Signer, error) { return first, nil }, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+
+	oldToken := sign(t, keyRing)
+
+	if keyRing.Rotate(first) {
+		t.Fatalf("Rotate to the active key reported a rotation")
+	}
+
+	if !keyRing.Rotate(second) {
+		t.Fatalf("Rotate to a new key reported no rotation")
+	}
+
+	newToken := sign(t, keyRing)
+	if keyRing.KeyID() != second.KeyID() {
+		t.Errorf("active kid = %q, want %q", keyRing.KeyID(), second.KeyID())
+	}
+
+	for name, tokenString := range map[string]string{"old": oldToken, "new": newToken} {
+		err = verify(keyRing, tokenString)
+		if err != nil {
+			t.Errorf("%s token: %v", name, err)
+		}
+	}
+
+	keyIDs := jwkKeyIDs(keyRing)
+	if len(keyIDs) != 2 || keyIDs[0] != second.KeyID() || keyIDs[1] != first.KeyID() {
+		t.Errorf("JWKSet kids = %v, want [%s %s]", keyIDs, second.KeyID(), first.KeyID())
+	}
+}
+
+func TestRotateRetention(t *testing.T) {
+	first := newECSigner(t)
+
+	keyRing, err := NewKeyRing(func() (signer.Signer, error) { return first, nil }, -time.Second)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+
+	oldToken := sign(t, keyRing)
+	keyRing.Rotate(newECSigner(t))
+
+	err = verify(keyRing, oldToken)
+	if err == nil {
+		t.Fatalf("token of a key past its retention still verifies")
+	}
+
+	if keyIDs := jwkKeyIDs(keyRing); len(keyIDs) != 1 {
+		t.Errorf("JWKSet kids = %v, want only the active key", keyIDs)
+	}
+}
+
+func TestReload(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "token.key")
+	writeKey := func(secret string) {
+		err := os.WriteFile(keyFile, []byte(secret+"\n"), 0o600)
+		if err != nil {
+			t.Fatalf("write key: %v", err)
+		}
+	}
+
+	t.Setenv("TOKEN_SIGNING_KEY_FILE", "")
+	t.Setenv("TOKEN_KEY_FILE", keyFile)
+	writeKey("first secret")
+
+	keyRing, err := NewKeyRing(signer.LoadSignerFromEnv, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+
+	oldToken := sign(t, keyRing)
+	oldKeyID := keyRing.KeyID()
+
+	err = keyRing.Reload()
+	if !errors.Is(err, entity.ErrKeyUnchanged) {
+		t.Fatalf("Reload of the same key err = %v, want %v", err, entity.ErrKeyUnchanged)
+	}
+
+	writeKey("second secret")
+	err = keyRing.Reload()
+	if err != nil {
+		t.Fatalf("Reload of a new key: %v", err)
+	}
+
+	if keyRing.KeyID() == oldKeyID {
+		t.Fatalf("kid stayed %q after the key changed", oldKeyID)
+	}
+
+	err = verify(keyRing, oldToken)
+	if err != nil {
+		t.Errorf("token of the retired key: %v", err)
+	}
+
+	activeKeyID := keyRing.KeyID()
+	os.Remove(keyFile)
+	err = keyRing.Reload()
+	if err == nil {
+		t.Fatalf("Reload of a missing key file succeeded")
+	}
+
+	if keyRing.KeyID() != activeKeyID {
+		t.Errorf("failed reload changed the active kid to %q", keyRing.KeyID())
+	}
+}
+
+func TestPreviousKeys(t *testing.T) {
+	t.Setenv("TOKEN_SIGNING_KEY_FILE", "")
+	t.Setenv("TOKEN_KEY_FILE", "")
+	t.Setenv("TOKEN_KEY", "old secret")
+
+	before, err := NewKeyRing(signer.LoadSignerFromEnv, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	oldToken := sign(t, before)
+
+	t.Setenv("TOKEN_KEY", "new secret")
+	t.Setenv("TOKEN_PREVIOUS_KEYS", "other secret, old secret")
+	t.Setenv("TOKEN_PREVIOUS_KEY_FILES", "")
+
+	keyRing, err := NewKeyRing(signer.LoadSignerFromEnv, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+
+	err = verify(keyRing, oldToken)
+	if err == nil {
+		t.Fatalf("token of the previous secret verifies before it was added")
+	}
+
+	previousSigners, err := signer.LoadPreviousSignersFromEnv()
+	if err != nil {
+		t.Fatalf("LoadPreviousSignersFromEnv: %v", err)
+	}
+	for _, previousSigner := range previousSigners {
+		keyRing.AddRetired(previousSigner)
+	}
+
+	err = verify(keyRing, oldToken)
+	if err != nil {
+		t.Errorf("token of the previous secret: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"strings"
+)
+
+// LoadSignerFromEnv builds the token signer. The kid is always derived from
+// the key material, so putting a new key in place gives it a new kid and a
+// reload can tell a rotated key from an unchanged one.
+func LoadSignerFromEnv() (Signer, error) {
+	keyFile := os.Getenv("TOKEN_SIGNING_KEY_FILE")
+	if keyFile != "" {
+		return LoadPEMSigner("", keyFile)
+	}
+
+	secret, err := loadHMACSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHMACSigner(HMACKeyID(secret), secret), nil
+}
+
+// loadHMACSecret prefers TOKEN_KEY_FILE, which is re-read on every reload.
+// TOKEN_KEY is fixed for the life of the process and can't be rotated.
+func loadHMACSecret() ([]byte, error) {
+	secretFile := os.Getenv("TOKEN_KEY_FILE")
+	if secretFile == "" {
+		return []byte(os.Getenv("TOKEN_KEY")), nil
+	}
+
+	secret, err := os.ReadFile(secretFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(strings.TrimRight(string(secret), "\r\n")), nil
+}
+
+// HMACKeyID names a shared secret without revealing it.
+func HMACKeyID(secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("kid"))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// LoadPreviousSignersFromEnv loads the keys that signed tokens before the
+// last restart: PEM files from TOKEN_PREVIOUS_KEY_FILES and shared secrets
+// from TOKEN_PREVIOUS_KEYS, both comma separated.
+func LoadPreviousSignersFromEnv() ([]Signer, error) {
+	signers := []Signer{}
+	for _, keyFile := range strings.Split(os.Getenv("TOKEN_PREVIOUS_KEY_FILES"), ",") {
+		keyFile = strings.TrimSpace(keyFile)
+		if keyFile == "" {
+			continue
+		}
+
+		keySigner, err := LoadPEMSigner("", keyFile)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, keySigner)
+	}
+
+	for _, secret := range strings.Split(os.Getenv("TOKEN_PREVIOUS_KEYS"), ",") {
+		secret = strings.TrimSpace(secret)
+		if secret == "" {
+			continue
+		}
+
+		signers = append(signers, NewHMACSigner(HMACKeyID([]byte(secret)), []byte(secret)))
+	}
+
+	return signers, nil
+}
@@ -0,0 +1,162 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/lightlink/auth-service/internal/token/domain/entity"
+)
+
+func publicKeyToJWK(publicKey interface{}) (*entity.JWK, bool) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return &entity.JWK{
+			Kty: "RSA",
+			N:   encodeBigInt(key.N, 0),
+			E:   encodeBigInt(big.NewInt(int64(key.E)), 0),
+		}, true
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return &entity.JWK{
+			Kty: "EC",
+			Crv: key.Curve.Params().Name,
+			X:   encodeBigInt(key.X, size),
+			Y:   encodeBigInt(key.Y, size),
+		}, true
+	case ed25519.PublicKey:
+		return &entity.JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, true
+	}
+
+	return nil, false
+}
+
+// ParsePublicJWK is the inverse of publicKeyToJWK, used to verify tokens
+// signed by other issuers.
+func ParsePublicJWK(jwk *entity.JWK) (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, entity.ErrUnsupportedKey
+		}
+
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, entity.ErrUnsupportedKey
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, entity.ErrUnsupportedKey
+		}
+
+		key, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(key) != ed25519.PublicKeySize {
+			return nil, entity.ErrUnsupportedKey
+		}
+
+		return ed25519.PublicKey(key), nil
+	}
+
+	return nil, entity.ErrUnsupportedKey
+}
+
+func decodeBigInt(encoded string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+func encodeBigInt(value *big.Int, size int) string {
+	bytes := value.Bytes()
+	if len(bytes) < size {
+		padded := make([]byte, size)
+		copy(padded[size-len(bytes):], bytes)
+		bytes = padded
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// Thumbprint computes the RFC 7638 JWK thumbprint used as the default key id.
+func Thumbprint(jwk *entity.JWK) (string, error) {
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	default:
+		return "", entity.ErrUnsupportedKey
+	}
+
+	serialized, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(serialized)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
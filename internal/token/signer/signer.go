@@ -0,0 +1,171 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/token/domain/entity"
+)
+
+type Signer interface {
+	KeyID() string
+	Method() jwt.SigningMethod
+	Sign(claims jwt.Claims) (string, error)
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	PublicJWK() (*entity.JWK, bool)
+}
+
+type KeySigner struct {
+	keyID           string
+	method          jwt.SigningMethod
+	signingKey      interface{}
+	verificationKey interface{}
+}
+
+func NewHMACSigner(keyID string, secret []byte) *KeySigner {
+	return &KeySigner{
+		keyID:           keyID,
+		method:          jwt.SigningMethodHS256,
+		signingKey:      secret,
+		verificationKey: secret,
+	}
+}
+
+func NewPrivateKeySigner(keyID string, privateKey crypto.PrivateKey) (*KeySigner, error) {
+	keySigner := &KeySigner{
+		keyID:      keyID,
+		signingKey: privateKey,
+	}
+
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		keySigner.method = jwt.SigningMethodRS256
+		keySigner.verificationKey = &key.PublicKey
+	case *ecdsa.PrivateKey:
+		switch key.Curve {
+		case elliptic.P256():
+			keySigner.method = jwt.SigningMethodES256
+		case elliptic.P384():
+			keySigner.method = jwt.SigningMethodES384
+		case elliptic.P521():
+			keySigner.method = jwt.SigningMethodES512
+		default:
+			return nil, entity.ErrUnsupportedKey
+		}
+		keySigner.verificationKey = &key.PublicKey
+	case ed25519.PrivateKey:
+		keySigner.method = SigningMethodEdDSA
+		keySigner.verificationKey = key.Public()
+	default:
+		return nil, entity.ErrUnsupportedKey
+	}
+
+	if keySigner.keyID == "" {
+		jwk, _ := keySigner.PublicJWK()
+		thumbprint, err := Thumbprint(jwk)
+		if err != nil {
+			return nil, err
+		}
+		keySigner.keyID = thumbprint
+	}
+
+	return keySigner, nil
+}
+
+func LoadPEMSigner(keyID string, path string) (*KeySigner, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := ParsePrivateKeyPEM(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrivateKeySigner(keyID, privateKey)
+}
+
+func ParsePrivateKeyPEM(pemBytes []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, entity.ErrNoPEMBlock
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+
+	return nil, entity.ErrUnsupportedKey
+}
+
+func (s *KeySigner) KeyID() string {
+	return s.keyID
+}
+
+func (s *KeySigner) Method() jwt.SigningMethod {
+	return s.method
+}
+
+func (s *KeySigner) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.method, claims)
+	if s.keyID != "" {
+		token.Header["kid"] = s.keyID
+	}
+
+	return token.SignedString(s.signingKey)
+}
+
+func (s *KeySigner) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != s.method.Alg() {
+		return nil, entity.ErrUnexpectedSigningMethod
+	}
+
+	keyID, _ := token.Header["kid"].(string)
+	if keyID != s.keyID {
+		return nil, entity.ErrUnknownKeyID
+	}
+
+	return s.verificationKey, nil
+}
+
+func (s *KeySigner) PublicJWK() (*entity.JWK, bool) {
+	jwk, ok := publicKeyToJWK(s.verificationKey)
+	if !ok {
+		return nil, false
+	}
+
+	jwk.Kid = s.keyID
+	jwk.Alg = s.method.Alg()
+	jwk.Use = "sig"
+
+	return jwk, true
+}
+
+func JWKSet(signers ...Signer) *entity.JWKSet {
+	jwkSet := &entity.JWKSet{
+		Keys: []entity.JWK{},
+	}
+
+	for _, signer := range signers {
+		jwk, ok := signer.PublicJWK()
+		if !ok {
+			continue
+		}
+		jwkSet.Keys = append(jwkSet.Keys, *jwk)
+	}
+
+	return jwkSet
+}
@@ -0,0 +1,237 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lightlink/auth-service/internal/token/domain/entity"
+)
+
+type testKey struct {
+	name    string
+	alg     string
+	kty     string
+	crv     string
+	keySize int
+	key     func(t *testing.T) crypto.PrivateKey
+}
+
+var testKeys = []testKey{
+	{"RS256", "RS256", "RSA", "", 0, func(t *testing.T) crypto.PrivateKey {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		return key
+	}},
+	{"ES256", "ES256", "EC", "P-256", 32, func(t *testing.T) crypto.PrivateKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		return key
+	}},
+	{"ES512", "ES512", "EC", "P-521", 66, func(t *testing.T) crypto.PrivateKey {
+		key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		return key
+	}},
+	{"EdDSA", "EdDSA", "OKP", "Ed25519", ed25519.PublicKeySize, func(t *testing.T) crypto.PrivateKey {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		return key
+	}},
+}
+
+// writePKCS8 stores the key the way openssl genpkey does, so the test goes
+// through the same loader as TOKEN_SIGNING_KEY_FILE.
+func writePKCS8(t *testing.T, privateKey crypto.PrivateKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	return path
+}
+
+func TestSignVerify(t *testing.T) {
+	for _, test := range testKeys {
+		t.Run(test.name, func(t *testing.T) {
+			keySigner, err := LoadPEMSigner("", writePKCS8(t, test.key(t)))
+			if err != nil {
+				t.Fatalf("LoadPEMSigner: %v", err)
+			}
+
+			if keySigner.Method().Alg() != test.alg {
+				t.Fatalf("Method() = %s, want %s", keySigner.Method().Alg(), test.alg)
+			}
+
+			tokenString, err := keySigner.Sign(jwt.MapClaims{"sub": "1"})
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			token, err := jwt.Parse(tokenString, keySigner.Keyfunc)
+			if err != nil || !token.Valid {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			if token.Header["alg"] != test.alg || token.Header["kid"] != keySigner.KeyID() {
+				t.Errorf("header = %v, want alg %s kid %s", token.Header, test.alg, keySigner.KeyID())
+			}
+
+			other, err := NewPrivateKeySigner(keySigner.KeyID(), test.key(t))
+			if err != nil {
+				t.Fatalf("NewPrivateKeySigner: %v", err)
+			}
+
+			_, err = jwt.Parse(tokenString, other.Keyfunc)
+			if err == nil {
+				t.Error("Parse verified a token against another key")
+			}
+		})
+	}
+}
+
+func TestKeyfuncRejectsOtherAlgorithms(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	keySigner, err := NewPrivateKeySigner("", privateKey)
+	if err != nil {
+		t.Fatalf("NewPrivateKeySigner: %v", err)
+	}
+
+	// An HS256 token keyed with the published key must not pass as EdDSA.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "1"})
+	token.Header["kid"] = keySigner.KeyID()
+	tokenString, err := token.SignedString([]byte(privateKey.Public().(ed25519.PublicKey)))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+
+	_, err = jwt.Parse(tokenString, keySigner.Keyfunc)
+	if err == nil {
+		t.Fatal("Parse accepted an HS256 token")
+	}
+}
+
+func TestJWKSet(t *testing.T) {
+	for _, test := range testKeys {
+		t.Run(test.name, func(t *testing.T) {
+			keySigner, err := NewPrivateKeySigner("", test.key(t))
+			if err != nil {
+				t.Fatalf("NewPrivateKeySigner: %v", err)
+			}
+
+			tokenString, err := keySigner.Sign(jwt.MapClaims{"sub": "1"})
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			// Go through JSON the way a relying party reads the JWKS.
+			serialized, err := json.Marshal(JWKSet(keySigner, NewHMACSigner("hmac", []byte("secret"))))
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+
+			jwkSet := &entity.JWKSet{}
+			err = json.Unmarshal(serialized, jwkSet)
+			if err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+
+			if len(jwkSet.Keys) != 1 {
+				t.Fatalf("JWKSet has %d keys, want only the asymmetric one", len(jwkSet.Keys))
+			}
+
+			jwk := jwkSet.Keys[0]
+			if jwk.Kty != test.kty || jwk.Crv != test.crv || jwk.Alg != test.alg || jwk.Use != "sig" {
+				t.Errorf("jwk = %+v, want kty %s crv %s alg %s", jwk, test.kty, test.crv, test.alg)
+			}
+
+			thumbprint, err := Thumbprint(&jwk)
+			if err != nil {
+				t.Fatalf("Thumbprint: %v", err)
+			}
+
+			if jwk.Kid != keySigner.KeyID() || jwk.Kid != thumbprint {
+				t.Errorf("kid = %s, want signer kid %s and thumbprint %s", jwk.Kid, keySigner.KeyID(), thumbprint)
+			}
+
+			for name, coordinate := range map[string]string{"x": jwk.X, "y": jwk.Y} {
+				if test.keySize == 0 || coordinate == "" {
+					continue
+				}
+
+				decoded, err := base64.RawURLEncoding.DecodeString(coordinate)
+				if err != nil {
+					t.Fatalf("decode %s: %v", name, err)
+				}
+
+				if len(decoded) != test.keySize {
+					t.Errorf("%s is %d bytes, want %d", name, len(decoded), test.keySize)
+				}
+			}
+
+			publicKey, err := ParsePublicJWK(&jwk)
+			if err != nil {
+				t.Fatalf("ParsePublicJWK: %v", err)
+			}
+
+			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+				if token.Header["kid"] != jwk.Kid || token.Method.Alg() != jwk.Alg {
+					return nil, entity.ErrUnknownKeyID
+				}
+				return publicKey, nil
+			})
+			if err != nil || !token.Valid {
+				t.Fatalf("published key doesn't verify the token: %v", err)
+			}
+		})
+	}
+}
+
+func TestEncodeBigIntPadsToKeySize(t *testing.T) {
+	encoded := encodeBigInt(big.NewInt(1), 32)
+
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(decoded) != 32 || decoded[31] != 1 {
+		t.Errorf("encodeBigInt(1, 32) = %x, want 31 zero bytes and 0x01", decoded)
+	}
+
+	if encodeBigInt(big.NewInt(65537), 0) != "AQAB" {
+		t.Errorf("encodeBigInt(65537, 0) = %s, want AQAB", encodeBigInt(big.NewInt(65537), 0))
+	}
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/lightlink/auth-service/internal/turn/domain/dto"
+	"github.com/lightlink/auth-service/internal/turn/domain/entity"
+	"github.com/lightlink/auth-service/internal/turn/usecase"
+)
+
+type TURNHandler struct {
+	turnUC usecase.TURNUsecaseI
+}
+
+func NewTURNHandler(turnUsecase usecase.TURNUsecaseI) *TURNHandler {
+	return &TURNHandler{
+		turnUC: turnUsecase,
+	}
+}
+
+func (h *TURNHandler) Credentials(w http.ResponseWriter, r *http.Request) {
+	userIDString := r.Header.Get("X-User-ID")
+	userID64, err := strconv.ParseUint(userIDString, 10, 32)
+	if err != nil || userID64 == 0 {
+		/*Handle*/
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Println("turn credentials missing user", err)
+		return
+	}
+
+	credentials, err := h.turnUC.IssueCredentials(uint(userID64))
+	rateLimited := &entity.RateLimitedError{}
+	if errors.As(err, &rateLimited) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimited.RetryAfter.Seconds()))))
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Println("turn credentials err", err)
+		return
+	}
+
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("turn credentials err", err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, dto.CredentialsEntityToResponse(credentials))
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		/*Handle*/
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Println("marshal err", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
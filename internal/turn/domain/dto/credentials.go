@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/lightlink/auth-service/internal/turn/domain/entity"
+)
+
+type ICEServer struct {
+	URLs       []string `json:"urls"`
+	Username   string   `json:"username,omitempty"`
+	Credential string   `json:"credential,omitempty"`
+}
+
+// CredentialsResponse carries the coturn REST API fields alongside a ready
+// to use RTCConfiguration iceServers list.
+type CredentialsResponse struct {
+	Username   string       `json:"username"`
+	Password   string       `json:"password"`
+	TTL        int64        `json:"ttl"`
+	URIs       []string     `json:"uris"`
+	ICEServers []*ICEServer `json:"ice_servers"`
+}
+
+func CredentialsEntityToResponse(credentials *entity.Credentials) *CredentialsResponse {
+	stunURIs := []string{}
+	turnURIs := []string{}
+	for _, uri := range credentials.URIs {
+		if strings.HasPrefix(uri, "stun:") || strings.HasPrefix(uri, "stuns:") {
+			stunURIs = append(stunURIs, uri)
+		} else {
+			turnURIs = append(turnURIs, uri)
+		}
+	}
+
+	iceServers := []*ICEServer{}
+	if len(stunURIs) > 0 {
+		iceServers = append(iceServers, &ICEServer{
+			URLs: stunURIs,
+		})
+	}
+	if len(turnURIs) > 0 {
+		iceServers = append(iceServers, &ICEServer{
+			URLs:       turnURIs,
+			Username:   credentials.Username,
+			Credential: credentials.Password,
+		})
+	}
+
+	return &CredentialsResponse{
+		Username:   credentials.Username,
+		Password:   credentials.Password,
+		TTL:        int64(credentials.TTL.Seconds()),
+		URIs:       credentials.URIs,
+		ICEServers: iceServers,
+	}
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+type Config struct {
+	SharedSecret  string
+	URIs          []string
+	CredentialTTL time.Duration
+	RateLimit     int
+	RateWindow    time.Duration
+}
@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// Credentials follow the coturn REST API: the username carries the expiry and
+// the password is derived from it, so the TURN server needs no lookup.
+type Credentials struct {
+	Username  string
+	Password  string
+	TTL       time.Duration
+	ExpiresAt time.Time
+	URIs      []string
+}
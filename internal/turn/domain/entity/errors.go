@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNoSharedSecret = errors.New("TURN shared secret is not configured")
+	ErrNoURIs         = errors.New("no ICE server URLs are configured")
+)
+
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return "too many credential requests"
+}
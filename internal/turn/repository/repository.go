@@ -0,0 +1,7 @@
+package repository
+
+import "time"
+
+type RateLimitRepositoryI interface {
+	Hit(userID uint, window time.Duration) (int, time.Duration, error)
+}
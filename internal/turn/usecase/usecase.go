@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lightlink/auth-service/internal/turn/domain/entity"
+	turnRepo "github.com/lightlink/auth-service/internal/turn/repository"
+)
+
+const (
+	defaultCredentialTTL = 12 * time.Hour
+	defaultRateLimit     = 10
+	defaultRateWindow    = time.Minute
+)
+
+type TURNUsecaseI interface {
+	IssueCredentials(userID uint) (*entity.Credentials, error)
+}
+
+type TURNUsecase struct {
+	rateLimitRepo turnRepo.RateLimitRepositoryI
+	config        *entity.Config
+}
+
+func NewTURNUsecase(rateLimitRepository turnRepo.RateLimitRepositoryI, config *entity.Config) *TURNUsecase {
+	return &TURNUsecase{
+		rateLimitRepo: rateLimitRepository,
+		config:        config,
+	}
+}
+
+func LoadConfigFromEnv() (*entity.Config, error) {
+	config := &entity.Config{
+		SharedSecret:  os.Getenv("TURN_SHARED_SECRET"),
+		URIs:          []string{},
+		CredentialTTL: defaultCredentialTTL,
+		RateLimit:     defaultRateLimit,
+		RateWindow:    defaultRateWindow,
+	}
+
+	if config.SharedSecret == "" {
+		return nil, entity.ErrNoSharedSecret
+	}
+
+	for _, uri := range strings.Split(os.Getenv("TURN_URIS"), ",") {
+		uri = strings.TrimSpace(uri)
+		if uri != "" {
+			config.URIs = append(config.URIs, uri)
+		}
+	}
+
+	if len(config.URIs) == 0 {
+		return nil, entity.ErrNoURIs
+	}
+
+	var err error
+	if ttl := os.Getenv("TURN_CREDENTIAL_TTL"); ttl != "" {
+		config.CredentialTTL, err = time.ParseDuration(ttl)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if limit := os.Getenv("TURN_RATE_LIMIT"); limit != "" {
+		config.RateLimit, err = strconv.Atoi(limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if window := os.Getenv("TURN_RATE_WINDOW"); window != "" {
+		config.RateWindow, err = time.ParseDuration(window)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+func (uc *TURNUsecase) IssueCredentials(userID uint) (*entity.Credentials, error) {
+	if uc.config.RateLimit > 0 {
+		count, retryAfter, err := uc.rateLimitRepo.Hit(userID, uc.config.RateWindow)
+		if err != nil {
+			return nil, err
+		}
+
+		if count > uc.config.RateLimit {
+			return nil, &entity.RateLimitedError{RetryAfter: retryAfter}
+		}
+	}
+
+	expiresAt := time.Now().Add(uc.config.CredentialTTL)
+	username := strconv.FormatInt(expiresAt.Unix(), 10) + ":" + strconv.Itoa(int(userID))
+
+	mac := hmac.New(sha1.New, []byte(uc.config.SharedSecret))
+	mac.Write([]byte(username))
+
+	return &entity.Credentials{
+		Username:  username,
+		Password:  base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+		TTL:       uc.config.CredentialTTL,
+		ExpiresAt: expiresAt,
+		URIs:      uc.config.URIs,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lightlink/auth-service/internal/testutil"
+	"github.com/lightlink/auth-service/internal/turn/domain/entity"
+	turnRepo "github.com/lightlink/auth-service/internal/turn/repository/redis"
+)
+
+const testSharedSecret = "turn secret"
+
+func newTestUsecase(t *testing.T, rateLimit int) (*TURNUsecase, *testutil.Env) {
+	t.Helper()
+
+	base := testutil.NewEnv(t)
+
+	return NewTURNUsecase(turnRepo.NewRateLimitRedisRepository(base.Dial()), &entity.Config{
+		SharedSecret:  testSharedSecret,
+		URIs:          []string{"turn:turn.example.com:3478"},
+		CredentialTTL: time.Hour,
+		RateLimit:     rateLimit,
+		RateWindow:    time.Minute,
+	}), base
+}
+
+func TestIssueCredentials(t *testing.T) {
+	uc, _ := newTestUsecase(t, 0)
+
+	before := time.Now()
+	credentials, err := uc.IssueCredentials(42)
+	if err != nil {
+		t.Fatalf("IssueCredentials: %v", err)
+	}
+
+	expiry, userID, ok := strings.Cut(credentials.Username, ":")
+	if !ok || userID != "42" {
+		t.Fatalf("username %q, want <expiry>:42", credentials.Username)
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || expiresAt != credentials.ExpiresAt.Unix() {
+		t.Errorf("username expiry %q, want %d", expiry, credentials.ExpiresAt.Unix())
+	}
+
+	if ttl := credentials.ExpiresAt.Sub(before); ttl < time.Hour-time.Second || ttl > time.Hour+time.Second {
+		t.Errorf("credentials live %v, want %v", ttl, time.Hour)
+	}
+
+	// The TURN server recomputes the password from the username with the
+	// shared secret, as in the TURN REST API.
+	mac := hmac.New(sha1.New, []byte(testSharedSecret))
+	mac.Write([]byte(credentials.Username))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); credentials.Password != want {
+		t.Errorf("password %q, want %q", credentials.Password, want)
+	}
+
+	if len(credentials.URIs) != 1 || credentials.URIs[0] != "turn:turn.example.com:3478" {
+		t.Errorf("uris = %v", credentials.URIs)
+	}
+}
+
+func TestIssueCredentialsRateLimit(t *testing.T) {
+	uc, base := newTestUsecase(t, 2)
+
+	for i := 0; i < 2; i++ {
+		_, err := uc.IssueCredentials(1)
+		if err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+
+	_, err := uc.IssueCredentials(1)
+	rateLimited := &entity.RateLimitedError{}
+	if !errors.As(err, &rateLimited) {
+		t.Fatalf("request over the limit err = %v, want a rate limit", err)
+	}
+
+	if rateLimited.RetryAfter <= 0 || rateLimited.RetryAfter > time.Minute {
+		t.Errorf("retry after %v, want within the window", rateLimited.RetryAfter)
+	}
+
+	_, err = uc.IssueCredentials(2)
+	if err != nil {
+		t.Errorf("another user is limited too: %v", err)
+	}
+
+	base.Redis.FastForward(time.Minute)
+	_, err = uc.IssueCredentials(1)
+	if err != nil {
+		t.Errorf("request after the window: %v", err)
+	}
+}
@@ -8,14 +8,36 @@ import (
 )
 
 type UserTransfer struct {
-	Id       uint
-	Username string
+	Id            uint
+	Username      string
+	Email         string
+	EmailVerified bool
+}
+
+type UserCredentialsTransfer struct {
+	Id            uint
+	Username      string
+	PasswordHash  string
+	Email         string
+	EmailVerified bool
 }
 
 func GetUserResponseToTransfer(getResponse *proto.GetUserResponse) *UserTransfer {
 	return &UserTransfer{
-		Id:       uint(getResponse.Id),
-		Username: getResponse.Username,
+		Id:            uint(getResponse.Id),
+		Username:      getResponse.Username,
+		Email:         getResponse.Email,
+		EmailVerified: getResponse.EmailVerified,
+	}
+}
+
+func GetUserCredentialsResponseToTransfer(getResponse *proto.GetUserCredentialsResponse) *UserCredentialsTransfer {
+	return &UserCredentialsTransfer{
+		Id:            uint(getResponse.Id),
+		Username:      getResponse.Username,
+		PasswordHash:  getResponse.PasswordHash,
+		Email:         getResponse.Email,
+		EmailVerified: getResponse.EmailVerified,
 	}
 }
 
@@ -23,6 +45,7 @@ func UserEntityToCreateRequest(userEntity *entity.User) *proto.CreateUserRequest
 	return &proto.CreateUserRequest{
 		Username:     userEntity.Username,
 		PasswordHash: userEntity.PasswordHash,
+		Email:        userEntity.Email,
 	}
 }
 
@@ -35,5 +58,6 @@ func SignupRequestToEntity(signupRequest *dto.SignupRequest) (*entity.User, erro
 	return &entity.User{
 		Username:     signupRequest.Username,
 		PasswordHash: string(hashedPassword),
+		Email:        signupRequest.Email,
 	}, nil
 }
@@ -3,4 +3,5 @@ package entity
 type User struct {
 	Username     string
 	PasswordHash string
+	Email        string
 }
@@ -59,3 +59,73 @@ func (repo *UserGrpcRepository) GetByUsername(username string) (*dto.UserTransfe
 
 	return userModel, nil
 }
+
+func (repo *UserGrpcRepository) GetCredentials(username string) (*dto.UserCredentialsTransfer, error) {
+	getUserByUsernameRequest := &proto.GetUserByUsernameRequest{
+		Username: username,
+	}
+
+	credentialsResponseProto, err := repo.client.GetUserCredentials(context.Background(), getUserByUsernameRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := dto.GetUserCredentialsResponseToTransfer(credentialsResponseProto)
+
+	return credentials, nil
+}
+
+func (repo *UserGrpcRepository) GetByExternalIdentity(provider string, subject string) (*dto.UserTransfer, error) {
+	getUserByExternalIdentityRequest := &proto.GetUserByExternalIdentityRequest{
+		Provider: provider,
+		Subject:  subject,
+	}
+
+	userResponseProto, err := repo.client.GetUserByExternalIdentity(context.Background(), getUserByExternalIdentityRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	userModel := dto.GetUserResponseToTransfer(userResponseProto)
+
+	return userModel, nil
+}
+
+func (repo *UserGrpcRepository) LinkIdentity(userID uint, provider string, subject string) error {
+	linkIdentityRequest := &proto.LinkIdentityRequest{
+		UserId:   uint32(userID),
+		Provider: provider,
+		Subject:  subject,
+	}
+
+	_, err := repo.client.LinkIdentity(context.Background(), linkIdentityRequest)
+
+	return err
+}
+
+func (repo *UserGrpcRepository) MarkEmailVerified(userID uint, email string) (*dto.UserTransfer, error) {
+	markEmailVerifiedRequest := &proto.MarkEmailVerifiedRequest{
+		UserId: uint32(userID),
+		Email:  email,
+	}
+
+	userResponseProto, err := repo.client.MarkEmailVerified(context.Background(), markEmailVerifiedRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	userModel := dto.GetUserResponseToTransfer(userResponseProto)
+
+	return userModel, nil
+}
+
+func (repo *UserGrpcRepository) SetPasswordHash(userID uint, passwordHash string) error {
+	setPasswordHashRequest := &proto.SetPasswordHashRequest{
+		UserId:       uint32(userID),
+		PasswordHash: passwordHash,
+	}
+
+	_, err := repo.client.SetPasswordHash(context.Background(), setPasswordHashRequest)
+
+	return err
+}
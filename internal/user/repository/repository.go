@@ -9,4 +9,9 @@ type UserRepositoryI interface {
 	Create(userEntity *entity.User) (*dto.UserTransfer, error)
 	GetById(id uint) (*dto.UserTransfer, error)
 	GetByUsername(username string) (*dto.UserTransfer, error)
+	GetCredentials(username string) (*dto.UserCredentialsTransfer, error)
+	GetByExternalIdentity(provider string, subject string) (*dto.UserTransfer, error)
+	LinkIdentity(userID uint, provider string, subject string) error
+	MarkEmailVerified(userID uint, email string) (*dto.UserTransfer, error)
+	SetPasswordHash(userID uint, passwordHash string) error
 }
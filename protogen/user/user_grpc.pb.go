@@ -19,9 +19,14 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	UserService_CreateUser_FullMethodName        = "/user.UserService/CreateUser"
-	UserService_GetUserById_FullMethodName       = "/user.UserService/GetUserById"
-	UserService_GetUserByUsername_FullMethodName = "/user.UserService/GetUserByUsername"
+	UserService_CreateUser_FullMethodName                = "/user.UserService/CreateUser"
+	UserService_GetUserById_FullMethodName               = "/user.UserService/GetUserById"
+	UserService_GetUserByUsername_FullMethodName         = "/user.UserService/GetUserByUsername"
+	UserService_GetUserCredentials_FullMethodName        = "/user.UserService/GetUserCredentials"
+	UserService_GetUserByExternalIdentity_FullMethodName = "/user.UserService/GetUserByExternalIdentity"
+	UserService_LinkIdentity_FullMethodName              = "/user.UserService/LinkIdentity"
+	UserService_MarkEmailVerified_FullMethodName         = "/user.UserService/MarkEmailVerified"
+	UserService_SetPasswordHash_FullMethodName           = "/user.UserService/SetPasswordHash"
 )
 
 // UserServiceClient is the client API for UserService service.
@@ -33,6 +38,11 @@ type UserServiceClient interface {
 	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
 	GetUserById(ctx context.Context, in *GetUserByIdRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
 	GetUserByUsername(ctx context.Context, in *GetUserByUsernameRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
+	GetUserCredentials(ctx context.Context, in *GetUserByUsernameRequest, opts ...grpc.CallOption) (*GetUserCredentialsResponse, error)
+	GetUserByExternalIdentity(ctx context.Context, in *GetUserByExternalIdentityRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
+	LinkIdentity(ctx context.Context, in *LinkIdentityRequest, opts ...grpc.CallOption) (*LinkIdentityResponse, error)
+	MarkEmailVerified(ctx context.Context, in *MarkEmailVerifiedRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
+	SetPasswordHash(ctx context.Context, in *SetPasswordHashRequest, opts ...grpc.CallOption) (*SetPasswordHashResponse, error)
 }
 
 type userServiceClient struct {
@@ -73,6 +83,56 @@ func (c *userServiceClient) GetUserByUsername(ctx context.Context, in *GetUserBy
 	return out, nil
 }
 
+func (c *userServiceClient) GetUserCredentials(ctx context.Context, in *GetUserByUsernameRequest, opts ...grpc.CallOption) (*GetUserCredentialsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserCredentialsResponse)
+	err := c.cc.Invoke(ctx, UserService_GetUserCredentials_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) GetUserByExternalIdentity(ctx context.Context, in *GetUserByExternalIdentityRequest, opts ...grpc.CallOption) (*GetUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserResponse)
+	err := c.cc.Invoke(ctx, UserService_GetUserByExternalIdentity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) LinkIdentity(ctx context.Context, in *LinkIdentityRequest, opts ...grpc.CallOption) (*LinkIdentityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LinkIdentityResponse)
+	err := c.cc.Invoke(ctx, UserService_LinkIdentity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) MarkEmailVerified(ctx context.Context, in *MarkEmailVerifiedRequest, opts ...grpc.CallOption) (*GetUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserResponse)
+	err := c.cc.Invoke(ctx, UserService_MarkEmailVerified_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) SetPasswordHash(ctx context.Context, in *SetPasswordHashRequest, opts ...grpc.CallOption) (*SetPasswordHashResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetPasswordHashResponse)
+	err := c.cc.Invoke(ctx, UserService_SetPasswordHash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServiceServer is the server API for UserService service.
 // All implementations must embed UnimplementedUserServiceServer
 // for forward compatibility.
@@ -82,6 +142,11 @@ type UserServiceServer interface {
 	CreateUser(context.Context, *CreateUserRequest) (*GetUserResponse, error)
 	GetUserById(context.Context, *GetUserByIdRequest) (*GetUserResponse, error)
 	GetUserByUsername(context.Context, *GetUserByUsernameRequest) (*GetUserResponse, error)
+	GetUserCredentials(context.Context, *GetUserByUsernameRequest) (*GetUserCredentialsResponse, error)
+	GetUserByExternalIdentity(context.Context, *GetUserByExternalIdentityRequest) (*GetUserResponse, error)
+	LinkIdentity(context.Context, *LinkIdentityRequest) (*LinkIdentityResponse, error)
+	MarkEmailVerified(context.Context, *MarkEmailVerifiedRequest) (*GetUserResponse, error)
+	SetPasswordHash(context.Context, *SetPasswordHashRequest) (*SetPasswordHashResponse, error)
 	mustEmbedUnimplementedUserServiceServer()
 }
 
@@ -101,6 +166,21 @@ func (UnimplementedUserServiceServer) GetUserById(context.Context, *GetUserByIdR
 func (UnimplementedUserServiceServer) GetUserByUsername(context.Context, *GetUserByUsernameRequest) (*GetUserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserByUsername not implemented")
 }
+func (UnimplementedUserServiceServer) GetUserCredentials(context.Context, *GetUserByUsernameRequest) (*GetUserCredentialsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserCredentials not implemented")
+}
+func (UnimplementedUserServiceServer) GetUserByExternalIdentity(context.Context, *GetUserByExternalIdentityRequest) (*GetUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserByExternalIdentity not implemented")
+}
+func (UnimplementedUserServiceServer) LinkIdentity(context.Context, *LinkIdentityRequest) (*LinkIdentityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LinkIdentity not implemented")
+}
+func (UnimplementedUserServiceServer) MarkEmailVerified(context.Context, *MarkEmailVerifiedRequest) (*GetUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkEmailVerified not implemented")
+}
+func (UnimplementedUserServiceServer) SetPasswordHash(context.Context, *SetPasswordHashRequest) (*SetPasswordHashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPasswordHash not implemented")
+}
 func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
 func (UnimplementedUserServiceServer) testEmbeddedByValue()                     {}
 
@@ -176,6 +256,96 @@ func _UserService_GetUserByUsername_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_GetUserCredentials_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserByUsernameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetUserCredentials(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_GetUserCredentials_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetUserCredentials(ctx, req.(*GetUserByUsernameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_GetUserByExternalIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserByExternalIdentityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetUserByExternalIdentity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_GetUserByExternalIdentity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetUserByExternalIdentity(ctx, req.(*GetUserByExternalIdentityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_LinkIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LinkIdentityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).LinkIdentity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_LinkIdentity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).LinkIdentity(ctx, req.(*LinkIdentityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_MarkEmailVerified_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkEmailVerifiedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).MarkEmailVerified(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_MarkEmailVerified_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).MarkEmailVerified(ctx, req.(*MarkEmailVerifiedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_SetPasswordHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPasswordHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).SetPasswordHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_SetPasswordHash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).SetPasswordHash(ctx, req.(*SetPasswordHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -195,6 +365,26 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUserByUsername",
 			Handler:    _UserService_GetUserByUsername_Handler,
 		},
+		{
+			MethodName: "GetUserCredentials",
+			Handler:    _UserService_GetUserCredentials_Handler,
+		},
+		{
+			MethodName: "GetUserByExternalIdentity",
+			Handler:    _UserService_GetUserByExternalIdentity_Handler,
+		},
+		{
+			MethodName: "LinkIdentity",
+			Handler:    _UserService_LinkIdentity_Handler,
+		},
+		{
+			MethodName: "MarkEmailVerified",
+			Handler:    _UserService_MarkEmailVerified_Handler,
+		},
+		{
+			MethodName: "SetPasswordHash",
+			Handler:    _UserService_SetPasswordHash_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "user/user.proto",